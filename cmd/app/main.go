@@ -1,9 +1,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/SanExpett/film-library-backend/internal/server"
 	"github.com/SanExpett/film-library-backend/pkg/config"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
 //	@title      FILM-LIBRARY project API
@@ -12,11 +16,22 @@ import (
 //
 // @Schemes http
 // @BasePath  /api/v1
+//
+//	@securityDefinitions.apikey CSRFToken
+//	@in header
+//	@name X-CSRF-Token
+//	@description csrf token from GET /csrf_token, it has to be equal to csrf_token cookie
 func main() {
 	configServer := config.New()
 
+	// SIGTERM is sent by orchestrator on rolling deployment, SIGINT by ctrl+c
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
 	srv := new(server.Server)
-	if err := srv.Run(configServer); err != nil {
+	if err := srv.Run(ctx, configServer); err != nil {
 		fmt.Printf("Error in server: %s", err.Error())
+		stop()
+		os.Exit(1)
 	}
 }
@@ -0,0 +1,10 @@
+ALTER TABLE public."film_actor"
+    DROP CONSTRAINT film_actor_film_id_fkey,
+    ADD CONSTRAINT film_actor_film_id_fkey FOREIGN KEY (film_id) REFERENCES public."film" (id),
+    DROP CONSTRAINT film_actor_actor_id_fkey,
+    ADD CONSTRAINT film_actor_actor_id_fkey FOREIGN KEY (actor_id) REFERENCES public."actor" (id);
+
+ALTER TABLE public."film_actor"
+    DROP CONSTRAINT unique_film_actor,
+    DROP COLUMN billing_order,
+    DROP COLUMN character_name;
\ No newline at end of file
@@ -0,0 +1,16 @@
+DELETE FROM public."film_actor" a
+    USING public."film_actor" b
+WHERE a.id > b.id AND a.film_id = b.film_id AND a.actor_id = b.actor_id;
+
+ALTER TABLE public."film_actor"
+    ADD COLUMN character_name TEXT DEFAULT NULL
+    CONSTRAINT max_len_character_name CHECK (LENGTH(character_name) <= 256),
+    ADD COLUMN billing_order  BIGINT DEFAULT 0 NOT NULL
+    CONSTRAINT non_negative_billing_order CHECK (billing_order >= 0),
+    ADD CONSTRAINT unique_film_actor UNIQUE (film_id, actor_id);
+
+ALTER TABLE public."film_actor"
+    DROP CONSTRAINT film_actor_film_id_fkey,
+    ADD CONSTRAINT film_actor_film_id_fkey FOREIGN KEY (film_id) REFERENCES public."film" (id) ON DELETE CASCADE,
+    DROP CONSTRAINT film_actor_actor_id_fkey,
+    ADD CONSTRAINT film_actor_actor_id_fkey FOREIGN KEY (actor_id) REFERENCES public."actor" (id) ON DELETE CASCADE;
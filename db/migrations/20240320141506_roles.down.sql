@@ -0,0 +1,17 @@
+ALTER TABLE public."user"
+    ADD COLUMN is_admin BOOL DEFAULT FALSE;
+
+UPDATE public."user" u
+SET is_admin = TRUE
+FROM public."user_role" ur
+         JOIN public."role" r ON r.id = ur.role_id
+WHERE ur.user_id = u.id
+  AND r.name = 'admin';
+
+DROP TABLE IF EXISTS "user_role" CASCADE;
+DROP TABLE IF EXISTS "role_permission" CASCADE;
+DROP TABLE IF EXISTS "permission" CASCADE;
+DROP TABLE IF EXISTS "role" CASCADE;
+
+DROP SEQUENCE IF EXISTS permission_id_seq;
+DROP SEQUENCE IF EXISTS role_id_seq;
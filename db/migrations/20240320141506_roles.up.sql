@@ -0,0 +1,55 @@
+CREATE SEQUENCE IF NOT EXISTS role_id_seq;
+CREATE SEQUENCE IF NOT EXISTS permission_id_seq;
+
+CREATE TABLE IF NOT EXISTS public."role"
+(
+    id   BIGINT DEFAULT NEXTVAL('role_id_seq'::regclass) NOT NULL PRIMARY KEY,
+    name TEXT UNIQUE                                     NOT NULL CHECK (name <> '')
+);
+
+CREATE TABLE IF NOT EXISTS public."permission"
+(
+    id   BIGINT DEFAULT NEXTVAL('permission_id_seq'::regclass) NOT NULL PRIMARY KEY,
+    name TEXT UNIQUE                                           NOT NULL CHECK (name <> '')
+);
+
+CREATE TABLE IF NOT EXISTS public."role_permission"
+(
+    role_id       BIGINT NOT NULL REFERENCES public."role" (id) ON DELETE CASCADE,
+    permission_id BIGINT NOT NULL REFERENCES public."permission" (id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE TABLE IF NOT EXISTS public."user_role"
+(
+    user_id    BIGINT                                 NOT NULL REFERENCES public."user" (id) ON DELETE CASCADE,
+    role_id    BIGINT                                 NOT NULL REFERENCES public."role" (id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    PRIMARY KEY (user_id, role_id)
+);
+
+INSERT INTO public."role" (name)
+VALUES ('viewer'), ('editor'), ('moderator'), ('admin');
+
+INSERT INTO public."permission" (name)
+VALUES ('film:create'), ('film:update'), ('film:delete'),
+       ('actor:create'), ('actor:update'), ('actor:delete'),
+       ('cast:update'), ('user:manage'), ('role:manage');
+
+-- viewer has no permissions: reading and searching are allowed for everyone
+INSERT INTO public."role_permission" (role_id, permission_id)
+SELECT r.id, p.id
+FROM public."role" r
+         JOIN public."permission" p ON
+    (r.name = 'editor' AND p.name IN ('film:create', 'film:update', 'actor:create', 'actor:update', 'cast:update'))
+        OR (r.name = 'moderator' AND p.name IN ('film:create', 'film:update', 'film:delete',
+                                                'actor:create', 'actor:update', 'actor:delete', 'cast:update'))
+        OR r.name = 'admin';
+
+INSERT INTO public."user_role" (user_id, role_id)
+SELECT u.id, r.id
+FROM public."user" u
+         JOIN public."role" r ON r.name = 'viewer' OR (r.name = 'admin' AND u.is_admin);
+
+ALTER TABLE public."user"
+    DROP COLUMN is_admin;
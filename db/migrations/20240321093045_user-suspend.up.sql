@@ -0,0 +1,2 @@
+ALTER TABLE public."user"
+    ADD COLUMN IF NOT EXISTS suspended_at TIMESTAMP WITH TIME ZONE DEFAULT NULL;
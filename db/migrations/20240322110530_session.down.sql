@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS public."session";
+
+DROP SEQUENCE IF EXISTS session_id_seq;
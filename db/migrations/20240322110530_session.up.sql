@@ -0,0 +1,19 @@
+CREATE SEQUENCE IF NOT EXISTS session_id_seq;
+
+CREATE TABLE IF NOT EXISTS public."session"
+(
+    id                          BIGINT                   DEFAULT NEXTVAL('session_id_seq'::regclass) NOT NULL PRIMARY KEY,
+    user_id                     BIGINT                                                               NOT NULL REFERENCES public."user" (id) ON DELETE CASCADE,
+    refresh_token_hash          TEXT UNIQUE                                                          NOT NULL CHECK (refresh_token_hash <> ''),
+    previous_refresh_token_hash TEXT                     DEFAULT NULL,
+    user_agent                  TEXT                     DEFAULT ''                                  NOT NULL
+    CONSTRAINT max_len_user_agent CHECK (LENGTH(user_agent) <= 256),
+    ip                          TEXT                     DEFAULT ''                                  NOT NULL,
+    created_at                  TIMESTAMP WITH TIME ZONE DEFAULT NOW()                               NOT NULL,
+    last_used_at                TIMESTAMP WITH TIME ZONE DEFAULT NOW()                               NOT NULL,
+    expires_at                  TIMESTAMP WITH TIME ZONE                                             NOT NULL,
+    revoked_at                  TIMESTAMP WITH TIME ZONE DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS session_user_id_idx ON public."session" (user_id);
+CREATE INDEX IF NOT EXISTS session_previous_refresh_token_hash_idx ON public."session" (previous_refresh_token_hash);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS public."audit_log";
+
+DROP SEQUENCE IF EXISTS audit_log_id_seq;
+
+DROP TABLE IF EXISTS public."login_attempt";
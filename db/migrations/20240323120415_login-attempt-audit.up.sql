@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS public."login_attempt"
+(
+    key            TEXT                     NOT NULL PRIMARY KEY CHECK (key <> ''),
+    failed_count   BIGINT                   DEFAULT 0     NOT NULL CHECK (failed_count >= 0),
+    last_failed_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    locked_until   TIMESTAMP WITH TIME ZONE DEFAULT NULL
+);
+
+CREATE SEQUENCE IF NOT EXISTS audit_log_id_seq;
+
+CREATE TABLE IF NOT EXISTS public."audit_log"
+(
+    id         BIGINT                   DEFAULT NEXTVAL('audit_log_id_seq'::regclass) NOT NULL PRIMARY KEY,
+    actor_id   BIGINT                   DEFAULT NULL REFERENCES public."user" (id) ON DELETE SET NULL,
+    action     TEXT                                                                   NOT NULL CHECK (action <> ''),
+    target     TEXT                     DEFAULT ''                                    NOT NULL,
+    ip         TEXT                     DEFAULT ''                                    NOT NULL,
+    details    TEXT                     DEFAULT ''                                    NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()                                 NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_created_at_idx ON public."audit_log" (created_at);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS public."user_token";
+
+DROP SEQUENCE IF EXISTS user_token_id_seq;
+
+ALTER TABLE public."user" DROP COLUMN IF EXISTS email_verified_at;
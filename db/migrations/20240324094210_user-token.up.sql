@@ -0,0 +1,16 @@
+ALTER TABLE public."user" ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+
+CREATE SEQUENCE IF NOT EXISTS user_token_id_seq;
+
+CREATE TABLE IF NOT EXISTS public."user_token"
+(
+    id         BIGINT                   DEFAULT NEXTVAL('user_token_id_seq'::regclass) NOT NULL PRIMARY KEY,
+    user_id    BIGINT                                                                   NOT NULL REFERENCES public."user" (id) ON DELETE CASCADE,
+    purpose    TEXT                                                                     NOT NULL CHECK (purpose IN ('password_reset', 'email_verification')),
+    token_hash TEXT UNIQUE                                                              NOT NULL CHECK (token_hash <> ''),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()                                   NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE                                                 NOT NULL,
+    used_at    TIMESTAMP WITH TIME ZONE DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS user_token_user_id_idx ON public."user_token" (user_id);
@@ -0,0 +1,11 @@
+DELETE FROM public."user_token" WHERE purpose = 'email_change';
+
+ALTER TABLE public."user_token" DROP CONSTRAINT IF EXISTS user_token_purpose_check;
+ALTER TABLE public."user_token" ADD CONSTRAINT user_token_purpose_check
+    CHECK (purpose IN ('password_reset', 'email_verification'));
+
+ALTER TABLE public."user"
+    DROP COLUMN IF EXISTS pending_email,
+    DROP COLUMN IF EXISTS locale,
+    DROP COLUMN IF EXISTS avatar_url,
+    DROP COLUMN IF EXISTS display_name;
@@ -0,0 +1,13 @@
+ALTER TABLE public."user"
+    ADD COLUMN IF NOT EXISTS display_name  TEXT DEFAULT '' NOT NULL
+        CONSTRAINT max_len_display_name CHECK (LENGTH(display_name) <= 64),
+    ADD COLUMN IF NOT EXISTS avatar_url    TEXT DEFAULT '' NOT NULL
+        CONSTRAINT max_len_avatar_url CHECK (LENGTH(avatar_url) <= 512),
+    ADD COLUMN IF NOT EXISTS locale        TEXT DEFAULT 'ru' NOT NULL
+        CONSTRAINT max_len_locale CHECK (LENGTH(locale) <= 16),
+    ADD COLUMN IF NOT EXISTS pending_email TEXT DEFAULT NULL
+        CONSTRAINT max_len_pending_email CHECK (LENGTH(pending_email) <= 256);
+
+ALTER TABLE public."user_token" DROP CONSTRAINT IF EXISTS user_token_purpose_check;
+ALTER TABLE public."user_token" ADD CONSTRAINT user_token_purpose_check
+    CHECK (purpose IN ('password_reset', 'email_verification', 'email_change'));
@@ -0,0 +1,18 @@
+CREATE SEQUENCE IF NOT EXISTS api_key_id_seq;
+
+CREATE TABLE IF NOT EXISTS public."api_key"
+(
+    id           BIGINT                   DEFAULT NEXTVAL('api_key_id_seq'::regclass) NOT NULL PRIMARY KEY,
+    user_id      BIGINT                                                                NOT NULL REFERENCES public."user" (id) ON DELETE CASCADE,
+    name         TEXT                                                                  NOT NULL CHECK (name <> '')
+    CONSTRAINT max_len_name CHECK (LENGTH(name) <= 64),
+    prefix       TEXT                                                                  NOT NULL,
+    key_hash     TEXT UNIQUE                                                           NOT NULL CHECK (key_hash <> ''),
+    scopes       TEXT[]                                                                NOT NULL CHECK (CARDINALITY(scopes) > 0),
+    created_at   TIMESTAMP WITH TIME ZONE DEFAULT NOW()                                NOT NULL,
+    expires_at   TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    last_used_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    revoked_at   TIMESTAMP WITH TIME ZONE DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS api_key_user_id_idx ON public."api_key" (user_id);
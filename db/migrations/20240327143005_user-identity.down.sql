@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS public."user_identity";
+
+DROP SEQUENCE IF EXISTS user_identity_id_seq;
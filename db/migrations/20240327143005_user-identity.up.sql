@@ -0,0 +1,15 @@
+CREATE SEQUENCE IF NOT EXISTS user_identity_id_seq;
+
+CREATE TABLE IF NOT EXISTS public."user_identity"
+(
+    id            BIGINT                   DEFAULT NEXTVAL('user_identity_id_seq'::regclass) NOT NULL PRIMARY KEY,
+    user_id       BIGINT                                                                      NOT NULL REFERENCES public."user" (id) ON DELETE CASCADE,
+    issuer        TEXT                                                                        NOT NULL CHECK (issuer <> ''),
+    subject       TEXT                                                                        NOT NULL CHECK (subject <> ''),
+    email         TEXT                     DEFAULT ''                                         NOT NULL,
+    created_at    TIMESTAMP WITH TIME ZONE DEFAULT NOW()                                      NOT NULL,
+    last_login_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()                                      NOT NULL,
+    UNIQUE (issuer, subject)
+);
+
+CREATE INDEX IF NOT EXISTS user_identity_user_id_idx ON public."user_identity" (user_id);
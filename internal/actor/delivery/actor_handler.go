@@ -22,6 +22,9 @@ type IActorService interface {
 	UpdateActor(ctx context.Context, r io.Reader, isPartialUpdate bool, actorID uint64, userID uint64) error
 	GetListOfActorsInFilm(ctx context.Context, filmID uint64) ([]*models.Actor, error)
 	DeleteActor(ctx context.Context, actorID uint64, userID uint64) error
+	AddFilmToActor(ctx context.Context, r io.Reader, actorID uint64, userID uint64) (uint64, error)
+	AddFilmsToActor(ctx context.Context, r io.Reader, actorID uint64, userID uint64) ([]uint64, error)
+	DeleteFilmFromActor(ctx context.Context, actorID uint64, filmID uint64, userID uint64) error
 }
 
 type ActorHandler struct {
@@ -44,10 +47,8 @@ func NewActorHandler(actorService IActorService) (*ActorHandler, error) {
 // AddActorHandler godoc
 //
 //	@Summary    add Actor
-//	@Description  add Actor by data
-//	@Description Error.status can be:
-//	@Description StatusErrBadRequest      = 400
-//	@Description  StatusErrInternalServer  = 500
+//	@Description  add Actor by data. Needs permission actor:create
+//	@Description Errors are sent with http status of error as application/problem+json
 //	@Tags Actor
 //
 //	@Accept      json
@@ -56,7 +57,8 @@ func NewActorHandler(actorService IActorService) (*ActorHandler, error) {
 //	@Success    200  {object} delivery.ResponseID
 //	@Failure    405  {string} string
 //	@Failure    500  {string} string
-//	@Failure    222  {object} delivery.ErrorResponse "Error"
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
 //	@Router      /actor/add [post]
 func (a *ActorHandler) AddActorHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -67,21 +69,21 @@ func (a *ActorHandler) AddActorHandler(w http.ResponseWriter, r *http.Request) {
 
 	ctx := r.Context()
 
-	userID, err := delivery.GetUserIDFromCookie(r)
+	userID, err := delivery.GetUserID(r)
 	if err != nil {
-		delivery.HandleErr(w, a.logger, err)
+		delivery.HandleErr(w, r, a.logger, err)
 		return
 	}
 
 	actorID, err := a.service.AddActor(ctx, r.Body, userID)
 	if err != nil {
-		delivery.HandleErr(w, a.logger, err)
+		delivery.HandleErr(w, r, a.logger, err)
 
 		return
 	}
 
 	delivery.SendOkResponse(w, a.logger, delivery.NewResponseID(actorID))
-	a.logger.Infof("in AddActorHandler: added Actor id= %+v", actorID)
+	my_logger.FromCtx(r.Context()).Infof("in AddActorHandler: added Actor id= %+v", actorID)
 }
 
 // GetActorHandler godoc
@@ -95,7 +97,7 @@ func (a *ActorHandler) AddActorHandler(w http.ResponseWriter, r *http.Request) {
 //	@Success    200  {object} ActorResponse
 //	@Failure    405  {string} string
 //	@Failure    500  {string} string
-//	@Failure    222  {object} delivery.ErrorResponse "Error"
+//	@Failure    default  {object} delivery.Problem "Error"
 //	@Router      /actor/get [get]
 func (a *ActorHandler) GetActorHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -108,26 +110,26 @@ func (a *ActorHandler) GetActorHandler(w http.ResponseWriter, r *http.Request) {
 
 	actorID, err := utils.ParseUint64FromRequest(r, "id")
 	if err != nil {
-		delivery.HandleErr(w, a.logger, err)
+		delivery.HandleErr(w, r, a.logger, err)
 
 		return
 	}
 
 	actor, err := a.service.GetActor(ctx, actorID)
 	if err != nil {
-		delivery.HandleErr(w, a.logger, err)
+		delivery.HandleErr(w, r, a.logger, err)
 
 		return
 	}
 
 	delivery.SendOkResponse(w, a.logger, NewActorResponse(delivery.StatusResponseSuccessful, actor))
-	a.logger.Infof("in GetActorHandler: get Actor: %+v", actor)
+	my_logger.FromCtx(r.Context()).Infof("in GetActorHandler: get Actor: %+v", actor)
 }
 
 // DeleteActorHandler godoc
 //
 //	@Summary     delete Actor
-//	@Description  delete Actor for author using user id from cookies\jwt.
+//	@Description  delete Actor using user id from cookies\jwt. Needs permission actor:delete.
 //	@Description  This totally removed Actor. Recovery will be impossible
 //	@Tags Actor
 //	@Accept      json
@@ -136,7 +138,8 @@ func (a *ActorHandler) GetActorHandler(w http.ResponseWriter, r *http.Request) {
 //	@Success    200  {object} delivery.Response
 //	@Failure    405  {string} string
 //	@Failure    500  {string} string
-//	@Failure    222  {object} delivery.ErrorResponse "Error"
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
 //	@Router      /actor/delete [delete]
 func (a *ActorHandler) DeleteActorHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
@@ -147,30 +150,30 @@ func (a *ActorHandler) DeleteActorHandler(w http.ResponseWriter, r *http.Request
 
 	ctx := r.Context()
 
-	userID, err := delivery.GetUserIDFromCookie(r)
+	userID, err := delivery.GetUserID(r)
 	if err != nil {
-		delivery.HandleErr(w, a.logger, err)
+		delivery.HandleErr(w, r, a.logger, err)
 
 		return
 	}
 
 	actorID, err := utils.ParseUint64FromRequest(r, "id")
 	if err != nil {
-		delivery.HandleErr(w, a.logger, err)
+		delivery.HandleErr(w, r, a.logger, err)
 
 		return
 	}
 
 	err = a.service.DeleteActor(ctx, actorID, userID)
 	if err != nil {
-		delivery.HandleErr(w, a.logger, err)
+		delivery.HandleErr(w, r, a.logger, err)
 
 		return
 	}
 
 	delivery.SendOkResponse(w, a.logger,
 		delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulDeleteActor))
-	a.logger.Infof("in DeleteActorHandler: delete Actor id=%d", actorID)
+	my_logger.FromCtx(r.Context()).Infof("in DeleteActorHandler: delete Actor id=%d", actorID)
 }
 
 // GetActorsListInFilmHandler godoc
@@ -184,7 +187,7 @@ func (a *ActorHandler) DeleteActorHandler(w http.ResponseWriter, r *http.Request
 //		@Success    200  {object} ActorListResponse
 //		@Failure    405  {string} string
 //		@Failure    500  {string} string
-//		@Failure    222  {object} delivery.ErrorResponse "Error"
+//		@Failure    default  {object} delivery.Problem "Error"
 //		@Router      /actor/get_list_of_actors_in_film [get]
 func (a *ActorHandler) GetActorsListInFilmHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -195,28 +198,28 @@ func (a *ActorHandler) GetActorsListInFilmHandler(w http.ResponseWriter, r *http
 
 	ctx := r.Context()
 
-	filmID, err := utils.ParseUint64FromRequest(r, "film_id")
+	filmID, err := utils.ParseIDFromRequest(r, "film_id")
 	if err != nil {
-		delivery.HandleErr(w, a.logger, err)
+		delivery.HandleErr(w, r, a.logger, err)
 
 		return
 	}
 
 	Actors, err := a.service.GetListOfActorsInFilm(ctx, filmID)
 	if err != nil {
-		delivery.HandleErr(w, a.logger, err)
+		delivery.HandleErr(w, r, a.logger, err)
 
 		return
 	}
 
 	delivery.SendOkResponse(w, a.logger, NewActorListResponse(delivery.StatusResponseSuccessful, Actors))
-	a.logger.Infof("in GetActorsListInFilmHandler: get Actor list: %+v", Actors)
+	my_logger.FromCtx(r.Context()).Infof("in GetActorsListInFilmHandler: get Actor list: %+v", Actors)
 }
 
 // UpdateActorHandler godoc
 //
 //	@Summary    update Actor
-//	@Description  update Actor by id
+//	@Description  update Actor by id. Needs permission actor:update
 //	@Tags Actor
 //	@Accept      json
 //	@Produce    json
@@ -225,7 +228,8 @@ func (a *ActorHandler) GetActorsListInFilmHandler(w http.ResponseWriter, r *http
 //	@Success    200  {object} delivery.ResponseID
 //	@Failure    405  {string} string
 //	@Failure    500  {string} string
-//	@Failure    222  {object} delivery.ErrorResponse "Error"
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
 //	@Router      /actor/update [patch]
 //	@Router      /actor/update [put]
 func (a *ActorHandler) UpdateActorHandler(w http.ResponseWriter, r *http.Request) {
@@ -237,16 +241,16 @@ func (a *ActorHandler) UpdateActorHandler(w http.ResponseWriter, r *http.Request
 
 	actorID, err := utils.ParseUint64FromRequest(r, "id")
 	if err != nil {
-		delivery.HandleErr(w, a.logger, err)
+		delivery.HandleErr(w, r, a.logger, err)
 
 		return
 	}
 
 	ctx := r.Context()
 
-	userID, err := delivery.GetUserIDFromCookie(r)
+	userID, err := delivery.GetUserID(r)
 	if err != nil {
-		delivery.HandleErr(w, a.logger, err)
+		delivery.HandleErr(w, r, a.logger, err)
 
 		return
 	}
@@ -258,11 +262,171 @@ func (a *ActorHandler) UpdateActorHandler(w http.ResponseWriter, r *http.Request
 	}
 
 	if err != nil {
-		delivery.HandleErr(w, a.logger, err)
+		delivery.HandleErr(w, r, a.logger, err)
 
 		return
 	}
 
 	delivery.SendOkResponse(w, a.logger, delivery.NewResponseID(actorID))
-	a.logger.Infof("in UpdateActorHandler: updated Actor with id = %+v", actorID)
+	my_logger.FromCtx(r.Context()).Infof("in UpdateActorHandler: updated Actor with id = %+v", actorID)
+}
+
+// AddFilmToActorHandler godoc
+//
+//	@Summary    add film to actor filmography
+//	@Description  add actor to film cast with character name and billing order. Needs permission cast:update
+//	@Description  If billing_order is not set actor is added to the end of the cast
+//	@Tags Actor
+//	@Accept      json
+//	@Produce    json
+//	@Param      id  query uint64 true  "Actor id"
+//	@Param      preFilmActor  body models.FilmActorWithoutID true  "actor_id is taken from query"
+//	@Success    200  {object} delivery.ResponseID
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
+//	@Router      /actor/add_film [post]
+func (a *ActorHandler) AddFilmToActorHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	userID, err := delivery.GetUserID(r)
+	if err != nil {
+		delivery.HandleErr(w, r, a.logger, err)
+
+		return
+	}
+
+	actorID, err := utils.ParseUint64FromRequest(r, "id")
+	if err != nil {
+		delivery.HandleErr(w, r, a.logger, err)
+
+		return
+	}
+
+	filmActorID, err := a.service.AddFilmToActor(ctx, r.Body, actorID, userID)
+	if err != nil {
+		delivery.HandleErr(w, r, a.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, a.logger, delivery.NewResponseID(filmActorID))
+	my_logger.FromCtx(r.Context()).Infof("in AddFilmToActorHandler: added film to actor id=%d film_actor id=%d",
+		actorID, filmActorID)
+}
+
+// AddFilmsToActorHandler godoc
+//
+//	@Summary    add films list to actor filmography
+//	@Description  add actor to several films in one transaction. Needs permission cast:update
+//	@Description  If one of films can't be added, nothing is added
+//	@Tags Actor
+//	@Accept      json
+//	@Produce    json
+//	@Param      id  query uint64 true  "Actor id"
+//	@Param      preFilmActors  body []models.FilmActorWithoutID true  "actor_id is taken from query"
+//	@Success    200  {object} delivery.ResponseIDs
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
+//	@Router      /actor/add_films [post]
+func (a *ActorHandler) AddFilmsToActorHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	userID, err := delivery.GetUserID(r)
+	if err != nil {
+		delivery.HandleErr(w, r, a.logger, err)
+
+		return
+	}
+
+	actorID, err := utils.ParseUint64FromRequest(r, "id")
+	if err != nil {
+		delivery.HandleErr(w, r, a.logger, err)
+
+		return
+	}
+
+	filmActorIDs, err := a.service.AddFilmsToActor(ctx, r.Body, actorID, userID)
+	if err != nil {
+		delivery.HandleErr(w, r, a.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, a.logger, delivery.NewResponseIDs(filmActorIDs))
+	my_logger.FromCtx(r.Context()).Infof("in AddFilmsToActorHandler: added films to actor id=%d film_actor ids=%+v",
+		actorID, filmActorIDs)
+}
+
+// DeleteFilmFromActorHandler godoc
+//
+//	@Summary     delete film from actor filmography
+//	@Description  delete actor from film cast. Needs permission cast:update
+//	@Tags Actor
+//	@Accept      json
+//	@Produce    json
+//	@Param      id  query uint64 true  "Actor id"
+//	@Param      film_id  query uint64 true  "Film id"
+//	@Success    200  {object} delivery.Response
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
+//	@Router      /actor/delete_film [delete]
+func (a *ActorHandler) DeleteFilmFromActorHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	userID, err := delivery.GetUserID(r)
+	if err != nil {
+		delivery.HandleErr(w, r, a.logger, err)
+
+		return
+	}
+
+	actorID, err := utils.ParseUint64FromRequest(r, "id")
+	if err != nil {
+		delivery.HandleErr(w, r, a.logger, err)
+
+		return
+	}
+
+	filmID, err := utils.ParseUint64FromRequest(r, "film_id")
+	if err != nil {
+		delivery.HandleErr(w, r, a.logger, err)
+
+		return
+	}
+
+	err = a.service.DeleteFilmFromActor(ctx, actorID, filmID, userID)
+	if err != nil {
+		delivery.HandleErr(w, r, a.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, a.logger,
+		delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulDeleteFilmActor))
+	my_logger.FromCtx(r.Context()).Infof("in DeleteFilmFromActorHandler: delete film id=%d from actor id=%d",
+		filmID, actorID)
 }
@@ -3,7 +3,8 @@ package delivery
 import "github.com/SanExpett/film-library-backend/pkg/models"
 
 const (
-	ResponseSuccessfulDeleteActor = "Актер успешно удален"
+	ResponseSuccessfulDeleteActor     = "Актер успешно удален"
+	ResponseSuccessfulDeleteFilmActor = "Фильм успешно удален из фильмографии актера"
 )
 
 type ActorResponse struct {
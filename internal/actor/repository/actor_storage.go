@@ -6,22 +6,23 @@ import (
 	"fmt"
 	"github.com/Masterminds/squirrel"
 	"github.com/SanExpett/film-library-backend/internal/server/repository"
+	"github.com/SanExpett/film-library-backend/pkg/metrics"
 	"github.com/SanExpett/film-library-backend/pkg/models"
 	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
 	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"github.com/SanExpett/film-library-backend/pkg/tracing"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
+	"time"
 )
 
 var (
-	ErrActorNotFound       = myerrors.NewError("Этот актер не найден")
-	ErrNotAuthorUpdate     = myerrors.NewError("Только автор может обновлять данные актера")
-	ErrNoUpdateFields      = myerrors.NewError("Вы пытаетесь обновить пустое количество полей актера")
-	ErrNoAdminAddActor     = myerrors.NewError("Только администратор может добавлять информацию об актерах")
-	ErrNoAffectedActorRows = myerrors.NewError("Не получилось обновить данные актера")
-
-	NameSeqActor = pgx.Identifier{"public", "actor_id_seq"} //nolint:gochecknoglobals
+	ErrActorNotFound  = myerrors.New(myerrors.KindNotFound, "actor_not_found", "Этот актер не найден")
+	ErrNoUpdateFields = myerrors.New(myerrors.KindBadRequest, "actor_no_update_fields",
+		"Вы пытаетесь обновить пустое количество полей актера")
+	ErrNoAffectedActorRows = myerrors.New(myerrors.KindNotFound, "actor_not_updated",
+		"Не получилось обновить данные актера")
 )
 
 type ActorStorage struct {
@@ -41,46 +42,15 @@ func NewActorStorage(pool *pgxpool.Pool) (*ActorStorage, error) {
 	}, nil
 }
 
-func (a *ActorStorage) createActor(ctx context.Context, tx pgx.Tx, preActor *models.ActorWithoutID,
-	userID uint64) error {
-	var SQLCreateActor string
-
-	var err error
-
-	SQLCreateActor = `INSERT INTO public."actor" (name, birthday, gender, author_id) VALUES ($1, $2, $3, $4);`
-	_, err = tx.Exec(ctx, SQLCreateActor,
-		preActor.Name, preActor.Birthday, preActor.Gender, userID)
-
-	if err != nil {
-		a.logger.Errorf("in createActor: preAcotor%+v err=%+v", preActor, err)
-
-		return fmt.Errorf(myerrors.ErrTemplate, err)
-	}
-
-	return nil
-}
-
 func (a *ActorStorage) AddActor(ctx context.Context, preActor *models.ActorWithoutID, userID uint64) (uint64, error) {
+	ctx, span := tracing.Start(ctx, "ActorStorage.AddActor")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageActor, "AddActor", time.Now())
+
 	actor := models.Actor{} //nolint:exhaustruct
 
 	err := pgx.BeginFunc(ctx, a.pool, func(tx pgx.Tx) error {
-		isAdmin, err := repository.SelectIsAdminByUserID(ctx, tx, userID)
-		if err != nil {
-			return fmt.Errorf(myerrors.ErrTemplate, err)
-		}
-
-		if !isAdmin {
-			a.logger.Errorln(ErrNoAdminAddActor)
-
-			return fmt.Errorf(myerrors.ErrTemplate, ErrNoAdminAddActor)
-		}
-
-		err = a.createActor(ctx, tx, preActor, userID)
-		if err != nil {
-			return fmt.Errorf(myerrors.ErrTemplate, err)
-		}
-
-		id, err := repository.GetLastValSeq(ctx, tx, NameSeqActor)
+		id, err := repository.InsertActor(ctx, tx, preActor, userID)
 		if err != nil {
 			return fmt.Errorf(myerrors.ErrTemplate, err)
 		}
@@ -118,6 +88,10 @@ func (a *ActorStorage) selectActorByID(ctx context.Context,
 }
 
 func (a *ActorStorage) GetActor(ctx context.Context, actorID uint64) (*models.Actor, error) {
+	ctx, span := tracing.Start(ctx, "ActorStorage.GetActor")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageActor, "GetActor", time.Now())
+
 	var actor *models.Actor
 
 	err := pgx.BeginFunc(ctx, a.pool, func(tx pgx.Tx) error {
@@ -137,10 +111,10 @@ func (a *ActorStorage) GetActor(ctx context.Context, actorID uint64) (*models.Ac
 	return actor, nil
 }
 
-func (a *ActorStorage) deleteActor(ctx context.Context, tx pgx.Tx, actorID uint64, userID uint64) error {
-	SQLDeleteActor := `DELETE FROM public."actor" WHERE id=$1 AND actor.author_id=$2`
+func (a *ActorStorage) deleteActor(ctx context.Context, tx pgx.Tx, actorID uint64) error {
+	SQLDeleteActor := `DELETE FROM public."actor" WHERE id=$1`
 
-	result, err := tx.Exec(ctx, SQLDeleteActor, actorID, userID)
+	result, err := tx.Exec(ctx, SQLDeleteActor, actorID)
 	if err != nil {
 		a.logger.Errorln(err)
 
@@ -155,9 +129,13 @@ func (a *ActorStorage) deleteActor(ctx context.Context, tx pgx.Tx, actorID uint6
 	return nil
 }
 
-func (a *ActorStorage) DeleteActor(ctx context.Context, actorID uint64, userID uint64) error {
+func (a *ActorStorage) DeleteActor(ctx context.Context, actorID uint64) error {
+	ctx, span := tracing.Start(ctx, "ActorStorage.DeleteActor")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageActor, "DeleteActor", time.Now())
+
 	err := pgx.BeginFunc(ctx, a.pool, func(tx pgx.Tx) error {
-		err := a.deleteActor(ctx, tx, actorID, userID)
+		err := a.deleteActor(ctx, tx, actorID)
 		if err != nil {
 			return err
 		}
@@ -178,7 +156,8 @@ func (a *ActorStorage) selectActorsIDsByFilmID(ctx context.Context, tx pgx.Tx,
 	SQLSelectActorsIDsByFilmID :=
 		`SELECT actor_id
 		FROM public."film_actor" 
-		WHERE film_id = $1`
+		WHERE film_id = $1
+		ORDER BY billing_order, id`
 
 	actorsIDsByFilmIDRows, err := tx.Query(ctx, SQLSelectActorsIDsByFilmID, filmID)
 	if err != nil {
@@ -208,6 +187,10 @@ func (a *ActorStorage) selectActorsIDsByFilmID(ctx context.Context, tx pgx.Tx,
 }
 
 func (a *ActorStorage) GetListOfActorsInFilm(ctx context.Context, filmID uint64) ([]*models.Actor, error) {
+	ctx, span := tracing.Start(ctx, "ActorStorage.GetListOfActorsInFilm")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageActor, "GetListOfActorsInFilm", time.Now())
+
 	var slActors []*models.Actor
 
 	err := pgx.BeginFunc(ctx, a.pool, func(tx pgx.Tx) error {
@@ -236,25 +219,6 @@ func (a *ActorStorage) GetListOfActorsInFilm(ctx context.Context, filmID uint64)
 	return slActors, nil
 }
 
-func (a *ActorStorage) selectAuthorIDOfActor(ctx context.Context, tx pgx.Tx, actorID uint64) (uint64, error) {
-	var authorID uint64
-
-	SQLIsAuthorByUserIDAndActorID := `SELECT author_id FROM public."actor" WHERE id=$1`
-
-	authorIDRow := tx.QueryRow(ctx, SQLIsAuthorByUserIDAndActorID, actorID)
-	if err := authorIDRow.Scan(&authorID); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return 0, nil
-		}
-
-		a.logger.Errorln(err)
-
-		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
-	}
-
-	return authorID, nil
-}
-
 func (a *ActorStorage) updateActor(ctx context.Context, tx pgx.Tx,
 	actorID uint64, updateFields map[string]interface{},
 ) error {
@@ -287,16 +251,13 @@ func (a *ActorStorage) updateActor(ctx context.Context, tx pgx.Tx,
 	return nil
 }
 
-func (a *ActorStorage) UpdateActor(ctx context.Context, actorID uint64, userID uint64,
-	updateFields map[string]interface{},
-) error {
-	err := pgx.BeginFunc(ctx, a.pool, func(tx pgx.Tx) error {
-		authorID, err := a.selectAuthorIDOfActor(ctx, tx, actorID)
-		if authorID != userID {
-			return ErrNotAuthorUpdate
-		}
+func (a *ActorStorage) UpdateActor(ctx context.Context, actorID uint64, updateFields map[string]interface{}) error {
+	ctx, span := tracing.Start(ctx, "ActorStorage.UpdateActor")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageActor, "UpdateActor", time.Now())
 
-		err = a.updateActor(ctx, tx, actorID, updateFields)
+	err := pgx.BeginFunc(ctx, a.pool, func(tx pgx.Tx) error {
+		err := a.updateActor(ctx, tx, actorID, updateFields)
 
 		return err
 	})
@@ -308,3 +269,61 @@ func (a *ActorStorage) UpdateActor(ctx context.Context, actorID uint64, userID u
 
 	return nil
 }
+
+func (a *ActorStorage) AddFilmToActor(ctx context.Context, preFilmActor *models.FilmActorWithoutID) (uint64, error) {
+	ctx, span := tracing.Start(ctx, "ActorStorage.AddFilmToActor")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageActor, "AddFilmToActor", time.Now())
+
+	var filmActorID uint64
+
+	err := pgx.BeginFunc(ctx, a.pool, func(tx pgx.Tx) error {
+		var err error
+
+		filmActorID, err = repository.InsertFilmActor(ctx, tx, preFilmActor)
+
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return filmActorID, nil
+}
+
+func (a *ActorStorage) AddFilmsToActor(ctx context.Context, preFilmActors []*models.FilmActorWithoutID,
+) ([]uint64, error) {
+	ctx, span := tracing.Start(ctx, "ActorStorage.AddFilmsToActor")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageActor, "AddFilmsToActor", time.Now())
+
+	var slFilmActorIDs []uint64
+
+	err := pgx.BeginFunc(ctx, a.pool, func(tx pgx.Tx) error {
+		var err error
+
+		slFilmActorIDs, err = repository.InsertFilmActorsList(ctx, tx, preFilmActors)
+
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return slFilmActorIDs, nil
+}
+
+func (a *ActorStorage) DeleteFilmFromActor(ctx context.Context, actorID uint64, filmID uint64) error {
+	ctx, span := tracing.Start(ctx, "ActorStorage.DeleteFilmFromActor")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageActor, "DeleteFilmFromActor", time.Now())
+
+	err := pgx.BeginFunc(ctx, a.pool, func(tx pgx.Tx) error {
+		return repository.DeleteFilmActor(ctx, tx, filmID, actorID)
+	})
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
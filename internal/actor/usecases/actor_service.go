@@ -4,39 +4,60 @@ import (
 	"context"
 	"fmt"
 	actorrepo "github.com/SanExpett/film-library-backend/internal/actor/repository"
+	serverusecases "github.com/SanExpett/film-library-backend/internal/server/usecases"
 	"github.com/SanExpett/film-library-backend/pkg/models"
 	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
 	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"github.com/SanExpett/film-library-backend/pkg/tracing"
 	"github.com/SanExpett/film-library-backend/pkg/utils"
 	"go.uber.org/zap"
 	"io"
 )
 
-var _ IActorStorage = (*actorrepo.ActorStorage)(nil)
+var (
+	_ IActorStorage = (*actorrepo.ActorStorage)(nil)
+	_ IPolicy       = (*serverusecases.Policy)(nil)
+)
 
 type IActorStorage interface {
 	AddActor(ctx context.Context, preActor *models.ActorWithoutID, userID uint64) (uint64, error)
 	GetActor(ctx context.Context, ActorID uint64) (*models.Actor, error)
-	UpdateActor(ctx context.Context, actorID uint64, userID uint64, updateFields map[string]interface{}) error
-	DeleteActor(ctx context.Context, actorID uint64, userID uint64) error
+	UpdateActor(ctx context.Context, actorID uint64, updateFields map[string]interface{}) error
+	DeleteActor(ctx context.Context, actorID uint64) error
 	GetListOfActorsInFilm(ctx context.Context, filmID uint64) ([]*models.Actor, error)
+	AddFilmToActor(ctx context.Context, preFilmActor *models.FilmActorWithoutID) (uint64, error)
+	AddFilmsToActor(ctx context.Context, preFilmActors []*models.FilmActorWithoutID) ([]uint64, error)
+	DeleteFilmFromActor(ctx context.Context, actorID uint64, filmID uint64) error
+}
+
+type IPolicy interface {
+	Check(ctx context.Context, userID uint64, permission models.Permission) error
 }
 
 type ActorService struct {
 	storage IActorStorage
+	policy  IPolicy
 	logger  *zap.SugaredLogger
 }
 
-func NewActorService(actorStorage IActorStorage) (*ActorService, error) {
+func NewActorService(actorStorage IActorStorage, policy IPolicy) (*ActorService, error) {
 	logger, err := my_logger.Get()
 	if err != nil {
 		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
 	}
 
-	return &ActorService{storage: actorStorage, logger: logger}, nil
+	return &ActorService{storage: actorStorage, policy: policy, logger: logger}, nil
 }
 
 func (a *ActorService) AddActor(ctx context.Context, r io.Reader, userID uint64) (uint64, error) {
+	ctx, span := tracing.Start(ctx, "ActorService.AddActor")
+	defer span.End()
+
+	err := a.policy.Check(ctx, userID, models.PermissionActorCreate)
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
 	preActor, err := ValidatePreActor(r)
 	if err != nil {
 		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
@@ -51,6 +72,9 @@ func (a *ActorService) AddActor(ctx context.Context, r io.Reader, userID uint64)
 }
 
 func (a *ActorService) GetActor(ctx context.Context, actorID uint64) (*models.Actor, error) {
+	ctx, span := tracing.Start(ctx, "ActorService.GetActor")
+	defer span.End()
+
 	actor, err := a.storage.GetActor(ctx, actorID)
 	if err != nil {
 		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
@@ -62,7 +86,15 @@ func (a *ActorService) GetActor(ctx context.Context, actorID uint64) (*models.Ac
 }
 
 func (p *ActorService) DeleteActor(ctx context.Context, actorID uint64, userID uint64) error {
-	err := p.storage.DeleteActor(ctx, actorID, userID)
+	ctx, span := tracing.Start(ctx, "ActorService.DeleteActor")
+	defer span.End()
+
+	err := p.policy.Check(ctx, userID, models.PermissionActorDelete)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	err = p.storage.DeleteActor(ctx, actorID)
 	if err != nil {
 		return fmt.Errorf(myerrors.ErrTemplate, err)
 	}
@@ -71,6 +103,9 @@ func (p *ActorService) DeleteActor(ctx context.Context, actorID uint64, userID u
 }
 
 func (a *ActorService) GetListOfActorsInFilm(ctx context.Context, filmID uint64) ([]*models.Actor, error) {
+	ctx, span := tracing.Start(ctx, "ActorService.GetListOfActorsInFilm")
+	defer span.End()
+
 	actors, err := a.storage.GetListOfActorsInFilm(ctx, filmID)
 	if err != nil {
 		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
@@ -86,9 +121,15 @@ func (a *ActorService) GetListOfActorsInFilm(ctx context.Context, filmID uint64)
 func (a *ActorService) UpdateActor(ctx context.Context,
 	r io.Reader, isPartialUpdate bool, actorID uint64, userID uint64,
 ) error {
-	var preActor *models.ActorWithoutID
+	ctx, span := tracing.Start(ctx, "ActorService.UpdateActor")
+	defer span.End()
 
-	var err error
+	err := a.policy.Check(ctx, userID, models.PermissionActorUpdate)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	var preActor *models.ActorWithoutID
 
 	if isPartialUpdate {
 		preActor, err = ValidatePartOfPreActor(r)
@@ -104,7 +145,70 @@ func (a *ActorService) UpdateActor(ctx context.Context,
 
 	updateFieldsMap := utils.StructToMap(preActor)
 
-	err = a.storage.UpdateActor(ctx, actorID, userID, updateFieldsMap)
+	err = a.storage.UpdateActor(ctx, actorID, updateFieldsMap)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+func (a *ActorService) AddFilmToActor(ctx context.Context, r io.Reader, actorID uint64, userID uint64,
+) (uint64, error) {
+	ctx, span := tracing.Start(ctx, "ActorService.AddFilmToActor")
+	defer span.End()
+
+	err := a.policy.Check(ctx, userID, models.PermissionCastUpdate)
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	preFilmActor, err := ValidatePreFilmActor(r, actorID)
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	filmActorID, err := a.storage.AddFilmToActor(ctx, preFilmActor)
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return filmActorID, nil
+}
+
+func (a *ActorService) AddFilmsToActor(ctx context.Context, r io.Reader, actorID uint64, userID uint64,
+) ([]uint64, error) {
+	ctx, span := tracing.Start(ctx, "ActorService.AddFilmsToActor")
+	defer span.End()
+
+	err := a.policy.Check(ctx, userID, models.PermissionCastUpdate)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	preFilmActors, err := ValidatePreFilmActorsList(r, actorID)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	filmActorIDs, err := a.storage.AddFilmsToActor(ctx, preFilmActors)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return filmActorIDs, nil
+}
+
+func (a *ActorService) DeleteFilmFromActor(ctx context.Context, actorID uint64, filmID uint64, userID uint64) error {
+	ctx, span := tracing.Start(ctx, "ActorService.DeleteFilmFromActor")
+	defer span.End()
+
+	err := a.policy.Check(ctx, userID, models.PermissionCastUpdate)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	err = a.storage.DeleteFilmFromActor(ctx, actorID, filmID)
 	if err != nil {
 		return fmt.Errorf(myerrors.ErrTemplate, err)
 	}
@@ -11,7 +11,11 @@ import (
 )
 
 var (
-	ErrDecodePreActor = myerrors.NewError("Некорректный json актер")
+	ErrDecodePreActor     = myerrors.New(myerrors.KindBadRequest, "actor_json_invalid", "Некорректный json актер")
+	ErrDecodePreFilmActor = myerrors.New(myerrors.KindBadRequest, "actor_film_json_invalid",
+		"Некорректный json фильма в фильмографии актера")
+	ErrDuplicateFilmInActor = myerrors.New(myerrors.KindValidation, "duplicate_film_in_actor",
+		"Фильм указан в фильмографии актера несколько раз")
 )
 
 func validateActorWithoutID(r io.Reader) (*models.ActorWithoutID, error) {
@@ -34,7 +38,8 @@ func validateActorWithoutID(r io.Reader) (*models.ActorWithoutID, error) {
 	if err != nil {
 		logger.Errorln(err)
 
-		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+		// decoded value is returned with error of validation, so partial update can skip empty fields
+		return preActor, fmt.Errorf(myerrors.ErrTemplate, err)
 	}
 
 	return preActor, nil
@@ -42,8 +47,12 @@ func validateActorWithoutID(r io.Reader) (*models.ActorWithoutID, error) {
 
 func ValidatePreActor(r io.Reader) (*models.ActorWithoutID, error) {
 	preActor, err := validateActorWithoutID(r)
+	if preActor == nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
 	if err != nil {
-		return nil, myerrors.NewError(err.Error())
+		return nil, myerrors.NewValidationErrorFrom(err)
 	}
 
 	return preActor, nil
@@ -61,16 +70,90 @@ func ValidatePartOfPreActor(r io.Reader) (*models.ActorWithoutID, error) {
 	}
 
 	if err != nil {
-		validationErrors := govalidator.ErrorsByField(err)
-
-		for field, err := range validationErrors {
-			if err != "non zero value required" {
-				logger.Errorln(err)
+		fields := myerrors.ValidationFields(err, myerrors.ValidatorRequired)
+		if len(fields) != 0 {
+			logger.Errorln(err)
 
-				return nil, myerrors.NewError("%s error: %s", field, err)
-			}
+			return nil, myerrors.NewValidationError(fields...)
 		}
 	}
 
 	return preActor, nil
 }
+
+func validateFilmActorWithoutID(preFilmActor *models.FilmActorWithoutID, actorID uint64) error {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return err
+	}
+
+	preFilmActor.ActorID = actorID
+	preFilmActor.Trim()
+
+	_, err = govalidator.ValidateStruct(preFilmActor)
+	if err != nil {
+		logger.Errorln(err)
+
+		return myerrors.NewValidationErrorFrom(err)
+	}
+
+	return nil
+}
+
+func ValidatePreFilmActor(r io.Reader, actorID uint64) (*models.FilmActorWithoutID, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(r)
+	preFilmActor := &models.FilmActorWithoutID{}
+	if err := decoder.Decode(preFilmActor); err != nil {
+		logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrDecodePreFilmActor)
+	}
+
+	err = validateFilmActorWithoutID(preFilmActor, actorID)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return preFilmActor, nil
+}
+
+func ValidatePreFilmActorsList(r io.Reader, actorID uint64) ([]*models.FilmActorWithoutID, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(r)
+	var preFilmActors []*models.FilmActorWithoutID
+	if err := decoder.Decode(&preFilmActors); err != nil {
+		logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrDecodePreFilmActor)
+	}
+
+	filmsIDs := make(map[uint64]struct{}, len(preFilmActors))
+
+	for _, preFilmActor := range preFilmActors {
+		if preFilmActor == nil {
+			return nil, fmt.Errorf(myerrors.ErrTemplate, ErrDecodePreFilmActor)
+		}
+
+		err = validateFilmActorWithoutID(preFilmActor, actorID)
+		if err != nil {
+			return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		if _, ok := filmsIDs[preFilmActor.FilmID]; ok {
+			return nil, fmt.Errorf(myerrors.ErrTemplate, ErrDuplicateFilmInActor)
+		}
+
+		filmsIDs[preFilmActor.FilmID] = struct{}{}
+	}
+
+	return preFilmActors, nil
+}
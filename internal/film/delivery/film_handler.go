@@ -17,7 +17,7 @@ import (
 var _ IFilmService = (*usecases.FilmService)(nil)
 
 type IFilmService interface {
-	AddFilm(ctx context.Context, r io.Reader, userID uint64) (uint64, error)
+	AddFilm(ctx context.Context, r io.Reader, userID uint64) (*models.AddedFilm, error)
 	GetFilm(ctx context.Context, filmID uint64) (*models.Film, error)
 	UpdateFilm(ctx context.Context, r io.Reader, isPartialUpdate bool, filmID uint64, userID uint64) error
 	GetFilmsListWithActorHandler(ctx context.Context, actorID uint64) ([]*models.Film, error)
@@ -25,6 +25,11 @@ type IFilmService interface {
 	GetFilmsList(ctx context.Context, limit uint64, offset uint64, sortType uint64) ([]*models.Film, error)
 	SearchFilmByTitle(ctx context.Context, searchedTitle string) ([]*models.Film, error)
 	SearchFilmByActorsName(ctx context.Context, searchedTitle string) ([]*models.Film, error)
+	AddActorToFilm(ctx context.Context, r io.Reader, filmID uint64, userID uint64) (uint64, error)
+	AddActorsToFilm(ctx context.Context, r io.Reader, filmID uint64, userID uint64) ([]uint64, error)
+	DeleteActorFromFilm(ctx context.Context, filmID uint64, actorID uint64, userID uint64) error
+	ReplaceFilmCast(ctx context.Context, r io.Reader, filmID uint64, userID uint64) ([]uint64, error)
+	GetFilmCast(ctx context.Context, filmID uint64) ([]*models.FilmActor, error)
 }
 
 type FilmHandler struct {
@@ -47,19 +52,19 @@ func NewFilmHandler(filmService IFilmService) (*FilmHandler, error) {
 // AddFilmHandler godoc
 //
 //	@Summary    add Film
-//	@Description  add Film by data
-//	@Description Error.status can be:
-//	@Description StatusErrBadRequest      = 400
-//	@Description  StatusErrInternalServer  = 500
+//	@Description  add Film by data. Cast can be set at once by ids of existing actors
+//	@Description  and by data of new actors, everything is created in one transaction. Needs permission film:create
+//	@Description Errors are sent with http status of error as application/problem+json
 //	@Tags Film
 //
 //	@Accept      json
 //	@Produce    json
-//	@Param      Film  body models.FilmWithoutID true  "Film data for adding"
-//	@Success    200  {object} delivery.ResponseID
+//	@Param      Film  body models.FilmWithCast true  "Film data for adding"
+//	@Success    200  {object} AddedFilmResponse
 //	@Failure    405  {string} string
 //	@Failure    500  {string} string
-//	@Failure    222  {object} delivery.ErrorResponse "Error"
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
 //	@Router      /film/add [post]
 func (f *FilmHandler) AddFilmHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -70,22 +75,22 @@ func (f *FilmHandler) AddFilmHandler(w http.ResponseWriter, r *http.Request) {
 
 	ctx := r.Context()
 
-	userID, err := delivery.GetUserIDFromCookie(r)
+	userID, err := delivery.GetUserID(r)
 	if err != nil {
-		delivery.HandleErr(w, f.logger, err)
+		delivery.HandleErr(w, r, f.logger, err)
 
 		return
 	}
 
-	filmID, err := f.service.AddFilm(ctx, r.Body, userID)
+	addedFilm, err := f.service.AddFilm(ctx, r.Body, userID)
 	if err != nil {
-		delivery.HandleErr(w, f.logger, err)
+		delivery.HandleErr(w, r, f.logger, err)
 
 		return
 	}
 
-	delivery.SendOkResponse(w, f.logger, delivery.NewResponseID(filmID))
-	f.logger.Infof("in AddFilmHandler: added Film id= %+v", filmID)
+	delivery.SendOkResponse(w, f.logger, NewAddedFilmResponse(delivery.StatusRedirectAfterSuccessful, addedFilm))
+	my_logger.FromCtx(r.Context()).Infof("in AddFilmHandler: added Film %+v", addedFilm)
 }
 
 // GetFilmHandler godoc
@@ -99,7 +104,7 @@ func (f *FilmHandler) AddFilmHandler(w http.ResponseWriter, r *http.Request) {
 //	@Success    200  {object} FilmResponse
 //	@Failure    405  {string} string
 //	@Failure    500  {string} string
-//	@Failure    222  {object} delivery.ErrorResponse "Error"
+//	@Failure    default  {object} delivery.Problem "Error"
 //	@Router      /film/get [get]
 func (f *FilmHandler) GetFilmHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -112,26 +117,26 @@ func (f *FilmHandler) GetFilmHandler(w http.ResponseWriter, r *http.Request) {
 
 	filmID, err := utils.ParseUint64FromRequest(r, "id")
 	if err != nil {
-		delivery.HandleErr(w, f.logger, err)
+		delivery.HandleErr(w, r, f.logger, err)
 
 		return
 	}
 
 	film, err := f.service.GetFilm(ctx, filmID)
 	if err != nil {
-		delivery.HandleErr(w, f.logger, err)
+		delivery.HandleErr(w, r, f.logger, err)
 
 		return
 	}
 
 	delivery.SendOkResponse(w, f.logger, NewFilmResponse(delivery.StatusResponseSuccessful, film))
-	f.logger.Infof("in GetFilmHandler: get Film: %+v", film)
+	my_logger.FromCtx(r.Context()).Infof("in GetFilmHandler: get Film: %+v", film)
 }
 
 // DeleteFilmHandler godoc
 //
 //	@Summary     delete Film
-//	@Description  delete Film for author using user id from cookies\jwt.
+//	@Description  delete Film using user id from cookies\jwt. Needs permission film:delete.
 //	@Description  This totally removed Film. Recovery will be impossible
 //	@Tags Film
 //	@Accept      json
@@ -140,7 +145,8 @@ func (f *FilmHandler) GetFilmHandler(w http.ResponseWriter, r *http.Request) {
 //	@Success    200  {object} delivery.Response
 //	@Failure    405  {string} string
 //	@Failure    500  {string} string
-//	@Failure    222  {object} delivery.ErrorResponse "Error"
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
 //	@Router      /film/delete [delete]
 func (f *FilmHandler) DeleteFilmHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
@@ -151,36 +157,36 @@ func (f *FilmHandler) DeleteFilmHandler(w http.ResponseWriter, r *http.Request)
 
 	ctx := r.Context()
 
-	userID, err := delivery.GetUserIDFromCookie(r)
+	userID, err := delivery.GetUserID(r)
 	if err != nil {
-		delivery.HandleErr(w, f.logger, err)
+		delivery.HandleErr(w, r, f.logger, err)
 
 		return
 	}
 
 	filmID, err := utils.ParseUint64FromRequest(r, "id")
 	if err != nil {
-		delivery.HandleErr(w, f.logger, err)
+		delivery.HandleErr(w, r, f.logger, err)
 
 		return
 	}
 
 	err = f.service.DeleteFilm(ctx, filmID, userID)
 	if err != nil {
-		delivery.HandleErr(w, f.logger, err)
+		delivery.HandleErr(w, r, f.logger, err)
 
 		return
 	}
 
 	delivery.SendOkResponse(w, f.logger,
 		delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulDeleteFilm))
-	f.logger.Infof("in DeleteFilmHandler: delete Film id=%d", filmID)
+	my_logger.FromCtx(r.Context()).Infof("in DeleteFilmHandler: delete Film id=%d", filmID)
 }
 
 // UpdateFilmHandler godoc
 //
 //	@Summary    update Film
-//	@Description  update Film by id
+//	@Description  update Film by id. Needs permission film:update
 //	@Tags Film
 //	@Accept      json
 //	@Produce    json
@@ -189,7 +195,8 @@ func (f *FilmHandler) DeleteFilmHandler(w http.ResponseWriter, r *http.Request)
 //	@Success    200  {object} delivery.ResponseID
 //	@Failure    405  {string} string
 //	@Failure    500  {string} string
-//	@Failure    222  {object} delivery.ErrorResponse "Error"
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
 //	@Router      /film/update [patch]
 //	@Router      /film/update [put]
 func (f *FilmHandler) UpdateFilmHandler(w http.ResponseWriter, r *http.Request) {
@@ -201,16 +208,16 @@ func (f *FilmHandler) UpdateFilmHandler(w http.ResponseWriter, r *http.Request)
 
 	filmID, err := utils.ParseUint64FromRequest(r, "id")
 	if err != nil {
-		delivery.HandleErr(w, f.logger, err)
+		delivery.HandleErr(w, r, f.logger, err)
 
 		return
 	}
 
 	ctx := r.Context()
 
-	userID, err := delivery.GetUserIDFromCookie(r)
+	userID, err := delivery.GetUserID(r)
 	if err != nil {
-		delivery.HandleErr(w, f.logger, err)
+		delivery.HandleErr(w, r, f.logger, err)
 
 		return
 	}
@@ -222,13 +229,13 @@ func (f *FilmHandler) UpdateFilmHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	if err != nil {
-		delivery.HandleErr(w, f.logger, err)
+		delivery.HandleErr(w, r, f.logger, err)
 
 		return
 	}
 
 	delivery.SendOkResponse(w, f.logger, delivery.NewResponseID(filmID))
-	f.logger.Infof("in UpdateFilmHandler: updated Film with id = %+v", filmID)
+	my_logger.FromCtx(r.Context()).Infof("in UpdateFilmHandler: updated Film with id = %+v", filmID)
 }
 
 // GetFilmsListWithActorHandler godoc
@@ -242,7 +249,7 @@ func (f *FilmHandler) UpdateFilmHandler(w http.ResponseWriter, r *http.Request)
 //		@Success    200  {object} FilmListResponse
 //		@Failure    405  {string} string
 //		@Failure    500  {string} string
-//		@Failure    222  {object} delivery.ErrorResponse "Error"
+//		@Failure    default  {object} delivery.Problem "Error"
 //		@Router      /film/get_list_of_films_with_actor [get]
 func (p *FilmHandler) GetFilmsListWithActorHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -253,22 +260,22 @@ func (p *FilmHandler) GetFilmsListWithActorHandler(w http.ResponseWriter, r *htt
 
 	ctx := r.Context()
 
-	actorID, err := utils.ParseUint64FromRequest(r, "actor_id")
+	actorID, err := utils.ParseIDFromRequest(r, "actor_id")
 	if err != nil {
-		delivery.HandleErr(w, p.logger, err)
+		delivery.HandleErr(w, r, p.logger, err)
 
 		return
 	}
 
 	films, err := p.service.GetFilmsListWithActorHandler(ctx, actorID)
 	if err != nil {
-		delivery.HandleErr(w, p.logger, err)
+		delivery.HandleErr(w, r, p.logger, err)
 
 		return
 	}
 
 	delivery.SendOkResponse(w, p.logger, NewFilmListResponse(delivery.StatusResponseSuccessful, films))
-	p.logger.Infof("in GetFilmsListInFilmHandler: get Film list: %+v", films)
+	my_logger.FromCtx(r.Context()).Infof("in GetFilmsListInFilmHandler: get Film list: %+v", films)
 }
 
 // GetFilmsListHandler godoc
@@ -284,7 +291,7 @@ func (p *FilmHandler) GetFilmsListWithActorHandler(w http.ResponseWriter, r *htt
 //	@Success    200  {object} FilmListResponse
 //	@Failure    405  {string} string
 //	@Failure    500  {string} string
-//	@Failure    222  {object} delivery.ErrorResponse "Error"
+//	@Failure    default  {object} delivery.Problem "Error"
 //	@Router      /film/get_list_of_films [get]
 func (f *FilmHandler) GetFilmsListHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -312,13 +319,13 @@ func (f *FilmHandler) GetFilmsListHandler(w http.ResponseWriter, r *http.Request
 
 	films, err := f.service.GetFilmsList(ctx, limit, offset, sortType)
 	if err != nil {
-		delivery.HandleErr(w, f.logger, err)
+		delivery.HandleErr(w, r, f.logger, err)
 
 		return
 	}
 
 	delivery.SendOkResponse(w, f.logger, NewFilmListResponse(delivery.StatusResponseSuccessful, films))
-	f.logger.Infof("in GetFilmListHandler: get film list: %+v", films)
+	my_logger.FromCtx(r.Context()).Infof("in GetFilmListHandler: get film list: %+v", films)
 }
 
 // SearchFilmByTitleHandler godoc
@@ -331,7 +338,7 @@ func (f *FilmHandler) GetFilmsListHandler(w http.ResponseWriter, r *http.Request
 //	@Success    200  {object} FilmListResponse
 //	@Failure    405  {string} string
 //	@Failure    500  {string} string
-//	@Failure    222  {object} delivery.ErrorResponse "Error"
+//	@Failure    default  {object} delivery.Problem "Error"
 //	@Router      /film/search_by_title [get]
 func (f *FilmHandler) SearchFilmByTitleHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -346,13 +353,13 @@ func (f *FilmHandler) SearchFilmByTitleHandler(w http.ResponseWriter, r *http.Re
 
 	films, err := f.service.SearchFilmByTitle(ctx, searchInput)
 	if err != nil {
-		delivery.HandleErr(w, f.logger, err)
+		delivery.HandleErr(w, r, f.logger, err)
 
 		return
 	}
 
 	delivery.SendOkResponse(w, f.logger, NewFilmListResponse(delivery.StatusResponseSuccessful, films))
-	f.logger.Infof("in SearchFilmByTitleHandler: get film list: %+v", films)
+	my_logger.FromCtx(r.Context()).Infof("in SearchFilmByTitleHandler: get film list: %+v", films)
 }
 
 // SearchFilmByActorsNameHandler godoc
@@ -365,7 +372,7 @@ func (f *FilmHandler) SearchFilmByTitleHandler(w http.ResponseWriter, r *http.Re
 //	@Success    200  {object} FilmListResponse
 //	@Failure    405  {string} string
 //	@Failure    500  {string} string
-//	@Failure    222  {object} delivery.ErrorResponse "Error"
+//	@Failure    default  {object} delivery.Problem "Error"
 //	@Router      /film/search_by_actors_name [get]
 func (f *FilmHandler) SearchFilmByActorsNameHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -380,11 +387,262 @@ func (f *FilmHandler) SearchFilmByActorsNameHandler(w http.ResponseWriter, r *ht
 
 	films, err := f.service.SearchFilmByActorsName(ctx, searchInput)
 	if err != nil {
-		delivery.HandleErr(w, f.logger, err)
+		delivery.HandleErr(w, r, f.logger, err)
 
 		return
 	}
 
 	delivery.SendOkResponse(w, f.logger, NewFilmListResponse(delivery.StatusResponseSuccessful, films))
-	f.logger.Infof("in SearchFilmByActorsNameHandler: get film list: %+v", films)
+	my_logger.FromCtx(r.Context()).Infof("in SearchFilmByActorsNameHandler: get film list: %+v", films)
+}
+
+// AddActorToFilmHandler godoc
+//
+//	@Summary    add actor to film cast
+//	@Description  add actor to film cast with character name and billing order. Needs permission cast:update
+//	@Description  If billing_order is not set actor is added to the end of the cast
+//	@Tags Film
+//	@Accept      json
+//	@Produce    json
+//	@Param      id  query uint64 true  "Film id"
+//	@Param      preFilmActor  body models.FilmActorWithoutID true  "film_id is taken from query"
+//	@Success    200  {object} delivery.ResponseID
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
+//	@Router      /film/add_actor [post]
+func (f *FilmHandler) AddActorToFilmHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	userID, err := delivery.GetUserID(r)
+	if err != nil {
+		delivery.HandleErr(w, r, f.logger, err)
+
+		return
+	}
+
+	filmID, err := utils.ParseUint64FromRequest(r, "id")
+	if err != nil {
+		delivery.HandleErr(w, r, f.logger, err)
+
+		return
+	}
+
+	filmActorID, err := f.service.AddActorToFilm(ctx, r.Body, filmID, userID)
+	if err != nil {
+		delivery.HandleErr(w, r, f.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, f.logger, delivery.NewResponseID(filmActorID))
+	my_logger.FromCtx(r.Context()).Infof("in AddActorToFilmHandler: added actor to film id=%d film_actor id=%d",
+		filmID, filmActorID)
+}
+
+// AddActorsToFilmHandler godoc
+//
+//	@Summary    add actors list to film cast
+//	@Description  add several actors to film cast in one transaction. Needs permission cast:update
+//	@Description  If one of actors can't be added, nothing is added
+//	@Tags Film
+//	@Accept      json
+//	@Produce    json
+//	@Param      id  query uint64 true  "Film id"
+//	@Param      preFilmActors  body []models.FilmActorWithoutID true  "film_id is taken from query"
+//	@Success    200  {object} delivery.ResponseIDs
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
+//	@Router      /film/add_actors [post]
+func (f *FilmHandler) AddActorsToFilmHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	userID, err := delivery.GetUserID(r)
+	if err != nil {
+		delivery.HandleErr(w, r, f.logger, err)
+
+		return
+	}
+
+	filmID, err := utils.ParseUint64FromRequest(r, "id")
+	if err != nil {
+		delivery.HandleErr(w, r, f.logger, err)
+
+		return
+	}
+
+	filmActorIDs, err := f.service.AddActorsToFilm(ctx, r.Body, filmID, userID)
+	if err != nil {
+		delivery.HandleErr(w, r, f.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, f.logger, delivery.NewResponseIDs(filmActorIDs))
+	my_logger.FromCtx(r.Context()).Infof("in AddActorsToFilmHandler: added actors to film id=%d film_actor ids=%+v",
+		filmID, filmActorIDs)
+}
+
+// DeleteActorFromFilmHandler godoc
+//
+//	@Summary     delete actor from film cast
+//	@Description  delete actor from film cast. Needs permission cast:update
+//	@Tags Film
+//	@Accept      json
+//	@Produce    json
+//	@Param      id  query uint64 true  "Film id"
+//	@Param      actor_id  query uint64 true  "Actor id"
+//	@Success    200  {object} delivery.Response
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
+//	@Router      /film/delete_actor [delete]
+func (f *FilmHandler) DeleteActorFromFilmHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	userID, err := delivery.GetUserID(r)
+	if err != nil {
+		delivery.HandleErr(w, r, f.logger, err)
+
+		return
+	}
+
+	filmID, err := utils.ParseUint64FromRequest(r, "id")
+	if err != nil {
+		delivery.HandleErr(w, r, f.logger, err)
+
+		return
+	}
+
+	actorID, err := utils.ParseUint64FromRequest(r, "actor_id")
+	if err != nil {
+		delivery.HandleErr(w, r, f.logger, err)
+
+		return
+	}
+
+	err = f.service.DeleteActorFromFilm(ctx, filmID, actorID, userID)
+	if err != nil {
+		delivery.HandleErr(w, r, f.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, f.logger,
+		delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulDeleteFilmActor))
+	my_logger.FromCtx(r.Context()).Infof("in DeleteActorFromFilmHandler: delete actor id=%d from film id=%d",
+		actorID, filmID)
+}
+
+// ReplaceFilmCastHandler godoc
+//
+//	@Summary    replace film cast
+//	@Description  replace whole film cast with new actors list. Needs permission cast:update
+//	@Description  Empty list removes all actors from film
+//	@Tags Film
+//	@Accept      json
+//	@Produce    json
+//	@Param      id  query uint64 true  "Film id"
+//	@Param      preFilmActors  body []models.FilmActorWithoutID true  "film_id is taken from query"
+//	@Success    200  {object} delivery.ResponseIDs
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
+//	@Router      /film/replace_cast [put]
+func (f *FilmHandler) ReplaceFilmCastHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	userID, err := delivery.GetUserID(r)
+	if err != nil {
+		delivery.HandleErr(w, r, f.logger, err)
+
+		return
+	}
+
+	filmID, err := utils.ParseUint64FromRequest(r, "id")
+	if err != nil {
+		delivery.HandleErr(w, r, f.logger, err)
+
+		return
+	}
+
+	filmActorIDs, err := f.service.ReplaceFilmCast(ctx, r.Body, filmID, userID)
+	if err != nil {
+		delivery.HandleErr(w, r, f.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, f.logger, delivery.NewResponseIDs(filmActorIDs))
+	my_logger.FromCtx(r.Context()).Infof("in ReplaceFilmCastHandler: replaced cast of film id=%d film_actor ids=%+v",
+		filmID, filmActorIDs)
+}
+
+// GetFilmCastHandler godoc
+//
+//	@Summary    get film cast
+//	@Description  get film cast with character names sorted by billing order
+//	@Tags Film
+//	@Accept      json
+//	@Produce    json
+//	@Param      id  query uint64 true  "Film id"
+//	@Success    200  {object} FilmActorListResponse
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Router      /film/get_cast [get]
+func (f *FilmHandler) GetFilmCastHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	filmID, err := utils.ParseUint64FromRequest(r, "id")
+	if err != nil {
+		delivery.HandleErr(w, r, f.logger, err)
+
+		return
+	}
+
+	filmActors, err := f.service.GetFilmCast(ctx, filmID)
+	if err != nil {
+		delivery.HandleErr(w, r, f.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, f.logger, NewFilmActorListResponse(delivery.StatusResponseSuccessful, filmActors))
+	my_logger.FromCtx(r.Context()).Infof("in GetFilmCastHandler: get cast of film id=%d: %+v", filmID, filmActors)
 }
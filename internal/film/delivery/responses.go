@@ -3,7 +3,8 @@ package delivery
 import "github.com/SanExpett/film-library-backend/pkg/models"
 
 const (
-	ResponseSuccessfulDeleteFilm = "Фильм успешно удален"
+	ResponseSuccessfulDeleteFilm      = "Фильм успешно удален"
+	ResponseSuccessfulDeleteFilmActor = "Актер успешно удален из состава фильма"
 )
 
 type FilmResponse struct {
@@ -29,3 +30,27 @@ func NewFilmListResponse(status int, body []*models.Film) *FilmListResponse {
 		Body:   body,
 	}
 }
+
+type FilmActorListResponse struct {
+	Status int                 `json:"status"`
+	Body   []*models.FilmActor `json:"body"`
+}
+
+func NewFilmActorListResponse(status int, body []*models.FilmActor) *FilmActorListResponse {
+	return &FilmActorListResponse{
+		Status: status,
+		Body:   body,
+	}
+}
+
+type AddedFilmResponse struct {
+	Status int               `json:"status"`
+	Body   *models.AddedFilm `json:"body"`
+}
+
+func NewAddedFilmResponse(status int, body *models.AddedFilm) *AddedFilmResponse {
+	return &AddedFilmResponse{
+		Status: status,
+		Body:   body,
+	}
+}
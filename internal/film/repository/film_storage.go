@@ -6,21 +6,25 @@ import (
 	"fmt"
 	"github.com/Masterminds/squirrel"
 	"github.com/SanExpett/film-library-backend/internal/server/repository"
+	"github.com/SanExpett/film-library-backend/pkg/metrics"
 	"github.com/SanExpett/film-library-backend/pkg/models"
 	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
 	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"github.com/SanExpett/film-library-backend/pkg/tracing"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
+	"slices"
 	"strings"
+	"time"
 )
 
 var (
-	ErrFilmNotFound       = myerrors.NewError("Этот актер не найден")
-	ErrNotAuthorUpdate    = myerrors.NewError("Только автор может обновлять данные фильма")
-	ErrNoUpdateFields     = myerrors.NewError("Вы пытаетесь обновить пустое количество полей фильма")
-	ErrNoAdminAddFilm     = myerrors.NewError("Только администратор может добавлять информацию о фильмах")
-	ErrNoAffectedFilmRows = myerrors.NewError("Не получилось обновить данные фильма")
+	ErrFilmNotFound   = myerrors.New(myerrors.KindNotFound, "film_not_found", "Этот фильм не найден")
+	ErrNoUpdateFields = myerrors.New(myerrors.KindBadRequest, "film_no_update_fields",
+		"Вы пытаетесь обновить пустое количество полей фильма")
+	ErrNoAffectedFilmRows = myerrors.New(myerrors.KindNotFound, "film_not_updated",
+		"Не получилось обновить данные фильма")
 
 	NameSeqFilm = pgx.Identifier{"public", "film_id_seq"} //nolint:gochecknoglobals
 )
@@ -66,40 +70,71 @@ func (f *FilmStorage) createFilm(ctx context.Context, tx pgx.Tx, preFilm *models
 	return nil
 }
 
-func (f *FilmStorage) AddFilm(ctx context.Context, preFilm *models.FilmWithoutID, userID uint64) (uint64, error) {
-	Film := models.Film{} //nolint:exhaustruct
+// AddFilm creates film, its new actors and links them with film in one transaction,
+// so film is never left half-created.
+func (f *FilmStorage) AddFilm(ctx context.Context, preFilm *models.FilmWithCast, userID uint64,
+) (*models.AddedFilm, error) {
+	ctx, span := tracing.Start(ctx, "FilmStorage.AddFilm")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageFilm, "AddFilm", time.Now())
+
+	addedFilm := &models.AddedFilm{} //nolint:exhaustruct
 
 	err := pgx.BeginFunc(ctx, f.pool, func(tx pgx.Tx) error {
-		isAdmin, err := repository.SelectIsAdminByUserID(ctx, tx, userID)
+		err := f.createFilm(ctx, tx, &preFilm.FilmWithoutID, userID)
 		if err != nil {
 			return fmt.Errorf(myerrors.ErrTemplate, err)
 		}
 
-		if !isAdmin {
-			f.logger.Errorln(ErrNoAdminAddFilm)
-
-			return fmt.Errorf(myerrors.ErrTemplate, ErrNoAdminAddFilm)
+		id, err := repository.GetLastValSeq(ctx, tx, NameSeqFilm)
+		if err != nil {
+			return fmt.Errorf(myerrors.ErrTemplate, err)
 		}
 
-		err = f.createFilm(ctx, tx, preFilm, userID)
+		addedFilm.ID = id
+
+		addedFilm.ActorsIDs, err = f.createNewActors(ctx, tx, preFilm.NewActors, userID)
 		if err != nil {
-			return fmt.Errorf(myerrors.ErrTemplate, err)
+			return err
 		}
 
-		id, err := repository.GetLastValSeq(ctx, tx, NameSeqFilm)
+		preFilmActors := make([]*models.FilmActorWithoutID, 0, len(preFilm.ActorsIDs)+len(addedFilm.ActorsIDs))
+		for _, actorID := range slices.Concat(preFilm.ActorsIDs, addedFilm.ActorsIDs) {
+			preFilmActors = append(preFilmActors, &models.FilmActorWithoutID{ //nolint:exhaustruct
+				FilmID:  addedFilm.ID,
+				ActorID: actorID,
+			})
+		}
+
+		addedFilm.FilmActorsIDs, err = repository.InsertFilmActorsList(ctx, tx, preFilmActors)
 		if err != nil {
 			return fmt.Errorf(myerrors.ErrTemplate, err)
 		}
 
-		Film.ID = id
-
 		return nil
 	})
 	if err != nil {
-		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return addedFilm, nil
+}
+
+func (f *FilmStorage) createNewActors(ctx context.Context, tx pgx.Tx,
+	preActors []*models.ActorWithoutID, userID uint64,
+) ([]uint64, error) {
+	slActorsIDs := make([]uint64, 0, len(preActors))
+
+	for _, preActor := range preActors {
+		actorID, err := repository.InsertActor(ctx, tx, preActor, userID)
+		if err != nil {
+			return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		slActorsIDs = append(slActorsIDs, actorID)
 	}
 
-	return Film.ID, nil
+	return slActorsIDs, nil
 }
 
 func (f *FilmStorage) selectFilmByID(ctx context.Context, tx pgx.Tx, filmID uint64) (*models.Film, error) {
@@ -122,6 +157,10 @@ func (f *FilmStorage) selectFilmByID(ctx context.Context, tx pgx.Tx, filmID uint
 }
 
 func (f *FilmStorage) GetFilm(ctx context.Context, filmID uint64) (*models.Film, error) {
+	ctx, span := tracing.Start(ctx, "FilmStorage.GetFilm")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageFilm, "GetFilm", time.Now())
+
 	var film *models.Film
 
 	err := pgx.BeginFunc(ctx, f.pool, func(tx pgx.Tx) error {
@@ -141,10 +180,10 @@ func (f *FilmStorage) GetFilm(ctx context.Context, filmID uint64) (*models.Film,
 	return film, nil
 }
 
-func (f *FilmStorage) deleteFilm(ctx context.Context, tx pgx.Tx, filmID uint64, userID uint64) error {
-	SQLDeleteFilm := `DELETE FROM public."film" WHERE id=$1 AND author_id=$2`
+func (f *FilmStorage) deleteFilm(ctx context.Context, tx pgx.Tx, filmID uint64) error {
+	SQLDeleteFilm := `DELETE FROM public."film" WHERE id=$1`
 
-	result, err := tx.Exec(ctx, SQLDeleteFilm, filmID, userID)
+	result, err := tx.Exec(ctx, SQLDeleteFilm, filmID)
 	if err != nil {
 		f.logger.Errorln(err)
 
@@ -159,9 +198,13 @@ func (f *FilmStorage) deleteFilm(ctx context.Context, tx pgx.Tx, filmID uint64,
 	return nil
 }
 
-func (f *FilmStorage) DeleteFilm(ctx context.Context, filmID uint64, userID uint64) error {
+func (f *FilmStorage) DeleteFilm(ctx context.Context, filmID uint64) error {
+	ctx, span := tracing.Start(ctx, "FilmStorage.DeleteFilm")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageFilm, "DeleteFilm", time.Now())
+
 	err := pgx.BeginFunc(ctx, f.pool, func(tx pgx.Tx) error {
-		err := f.deleteFilm(ctx, tx, filmID, userID)
+		err := f.deleteFilm(ctx, tx, filmID)
 		if err != nil {
 			return err
 		}
@@ -177,25 +220,6 @@ func (f *FilmStorage) DeleteFilm(ctx context.Context, filmID uint64, userID uint
 	return nil
 }
 
-func (f *FilmStorage) selectAuthorIDOfFilm(ctx context.Context, tx pgx.Tx, filmID uint64) (uint64, error) {
-	var authorID uint64
-
-	SQLIsAuthorByUserIDAndFilmID := `SELECT author_id FROM public."film" WHERE id=$1`
-
-	authorIDRow := tx.QueryRow(ctx, SQLIsAuthorByUserIDAndFilmID, filmID)
-	if err := authorIDRow.Scan(&authorID); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return 0, nil
-		}
-
-		f.logger.Errorln(err)
-
-		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
-	}
-
-	return authorID, nil
-}
-
 func (f *FilmStorage) updateFilm(ctx context.Context, tx pgx.Tx,
 	filmID uint64, updateFields map[string]interface{},
 ) error {
@@ -203,7 +227,7 @@ func (f *FilmStorage) updateFilm(ctx context.Context, tx pgx.Tx,
 		return ErrNoUpdateFields
 	}
 
-	query := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).Update(`public."Film"`).
+	query := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).Update(`public."film"`).
 		Where(squirrel.Eq{"id": filmID}).SetMap(updateFields)
 
 	queryString, args, err := query.ToSql()
@@ -228,16 +252,13 @@ func (f *FilmStorage) updateFilm(ctx context.Context, tx pgx.Tx,
 	return nil
 }
 
-func (f *FilmStorage) UpdateFilm(ctx context.Context, filmID uint64, userID uint64,
-	updateFields map[string]interface{},
-) error {
-	err := pgx.BeginFunc(ctx, f.pool, func(tx pgx.Tx) error {
-		authorID, err := f.selectAuthorIDOfFilm(ctx, tx, filmID)
-		if authorID != userID {
-			return ErrNotAuthorUpdate
-		}
+func (f *FilmStorage) UpdateFilm(ctx context.Context, filmID uint64, updateFields map[string]interface{}) error {
+	ctx, span := tracing.Start(ctx, "FilmStorage.UpdateFilm")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageFilm, "UpdateFilm", time.Now())
 
-		err = f.updateFilm(ctx, tx, filmID, updateFields)
+	err := pgx.BeginFunc(ctx, f.pool, func(tx pgx.Tx) error {
+		err := f.updateFilm(ctx, tx, filmID, updateFields)
 
 		return err
 	})
@@ -284,6 +305,10 @@ func (f *FilmStorage) selectFilmsIDsByActorID(ctx context.Context, tx pgx.Tx,
 }
 
 func (f *FilmStorage) GetFilmsListWithActorHandler(ctx context.Context, actorID uint64) ([]*models.Film, error) {
+	ctx, span := tracing.Start(ctx, "FilmStorage.GetFilmsListWithActorHandler")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageFilm, "GetFilmsListWithActorHandler", time.Now())
+
 	var slFilms []*models.Film
 
 	err := pgx.BeginFunc(ctx, f.pool, func(tx pgx.Tx) error {
@@ -365,6 +390,10 @@ func (f *FilmStorage) selectFilmsInFeedWithOrderLimitOffset(ctx context.Context,
 
 func (f *FilmStorage) GetFilmsList(ctx context.Context, limit uint64, offset uint64, sortType uint64,
 ) ([]*models.Film, error) {
+	ctx, span := tracing.Start(ctx, "FilmStorage.GetFilmsList")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageFilm, "GetFilmsList", time.Now())
+
 	var slFilms []*models.Film
 
 	var orderByClause []string
@@ -442,6 +471,10 @@ WHERE to_tsvector(title) @@ to_tsquery(replace($1 || ':*', ' ', ' | '))
 }
 
 func (f *FilmStorage) SearchFilmByTitle(ctx context.Context, searchInput string) ([]*models.Film, error) {
+	ctx, span := tracing.Start(ctx, "FilmStorage.SearchFilmByTitle")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageFilm, "SearchFilmByTitle", time.Now())
+
 	var films []*models.Film
 
 	err := pgx.BeginFunc(ctx, f.pool, func(tx pgx.Tx) error {
@@ -510,6 +543,10 @@ ORDER BY ts_rank(to_tsvector(a.name), to_tsquery(replace($1 || ':*', ' ', ' | ')
 }
 
 func (f *FilmStorage) SearchFilmByActorsName(ctx context.Context, searchInput string) ([]*models.Film, error) {
+	ctx, span := tracing.Start(ctx, "FilmStorage.SearchFilmByActorsName")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageFilm, "SearchFilmByActorsName", time.Now())
+
 	var films []*models.Film
 
 	err := pgx.BeginFunc(ctx, f.pool, func(tx pgx.Tx) error {
@@ -530,3 +567,177 @@ func (f *FilmStorage) SearchFilmByActorsName(ctx context.Context, searchInput st
 
 	return films, nil
 }
+
+func (f *FilmStorage) AddActorToFilm(ctx context.Context, preFilmActor *models.FilmActorWithoutID) (uint64, error) {
+	ctx, span := tracing.Start(ctx, "FilmStorage.AddActorToFilm")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageFilm, "AddActorToFilm", time.Now())
+
+	var filmActorID uint64
+
+	err := pgx.BeginFunc(ctx, f.pool, func(tx pgx.Tx) error {
+		var err error
+
+		filmActorID, err = repository.InsertFilmActor(ctx, tx, preFilmActor)
+
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return filmActorID, nil
+}
+
+func (f *FilmStorage) AddActorsToFilm(ctx context.Context, preFilmActors []*models.FilmActorWithoutID,
+) ([]uint64, error) {
+	ctx, span := tracing.Start(ctx, "FilmStorage.AddActorsToFilm")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageFilm, "AddActorsToFilm", time.Now())
+
+	var slFilmActorIDs []uint64
+
+	err := pgx.BeginFunc(ctx, f.pool, func(tx pgx.Tx) error {
+		var err error
+
+		slFilmActorIDs, err = repository.InsertFilmActorsList(ctx, tx, preFilmActors)
+
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return slFilmActorIDs, nil
+}
+
+func (f *FilmStorage) DeleteActorFromFilm(ctx context.Context, filmID uint64, actorID uint64) error {
+	ctx, span := tracing.Start(ctx, "FilmStorage.DeleteActorFromFilm")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageFilm, "DeleteActorFromFilm", time.Now())
+
+	err := pgx.BeginFunc(ctx, f.pool, func(tx pgx.Tx) error {
+		return repository.DeleteFilmActor(ctx, tx, filmID, actorID)
+	})
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+func (f *FilmStorage) deleteFilmCast(ctx context.Context, tx pgx.Tx, filmID uint64) error {
+	SQLDeleteFilmCast := `DELETE FROM public."film_actor" WHERE film_id=$1`
+
+	_, err := tx.Exec(ctx, SQLDeleteFilmCast, filmID)
+	if err != nil {
+		f.logger.Errorln(err)
+
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+// ReplaceFilmCast removes the whole cast of the film and sets the new one in the same transaction.
+func (f *FilmStorage) ReplaceFilmCast(ctx context.Context, filmID uint64, preFilmActors []*models.FilmActorWithoutID,
+) ([]uint64, error) {
+	ctx, span := tracing.Start(ctx, "FilmStorage.ReplaceFilmCast")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageFilm, "ReplaceFilmCast", time.Now())
+
+	var slFilmActorIDs []uint64
+
+	err := pgx.BeginFunc(ctx, f.pool, func(tx pgx.Tx) error {
+		isFilmExists, err := repository.SelectIsFilmExists(ctx, tx, filmID)
+		if err != nil {
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		if !isFilmExists {
+			return fmt.Errorf(myerrors.ErrTemplate, ErrFilmNotFound)
+		}
+
+		err = f.deleteFilmCast(ctx, tx, filmID)
+		if err != nil {
+			return err
+		}
+
+		slFilmActorIDs, err = repository.InsertFilmActorsList(ctx, tx, preFilmActors)
+
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return slFilmActorIDs, nil
+}
+
+func (f *FilmStorage) selectFilmCast(ctx context.Context, tx pgx.Tx, filmID uint64) ([]*models.FilmActor, error) {
+	SQLSelectFilmCast := `SELECT id, film_id, actor_id, COALESCE(character_name, ''), billing_order
+		FROM public."film_actor"
+		WHERE film_id = $1
+		ORDER BY billing_order, id`
+
+	filmCastRows, err := tx.Query(ctx, SQLSelectFilmCast, filmID)
+	if err != nil {
+		f.logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	curFilmActor := new(models.FilmActor)
+
+	var slFilmActors []*models.FilmActor
+
+	_, err = pgx.ForEachRow(filmCastRows, []any{
+		&curFilmActor.ID, &curFilmActor.FilmID, &curFilmActor.ActorID,
+		&curFilmActor.CharacterName, &curFilmActor.BillingOrder,
+	}, func() error {
+		slFilmActors = append(slFilmActors, &models.FilmActor{
+			ID:            curFilmActor.ID,
+			FilmID:        curFilmActor.FilmID,
+			ActorID:       curFilmActor.ActorID,
+			CharacterName: curFilmActor.CharacterName,
+			BillingOrder:  curFilmActor.BillingOrder,
+		})
+
+		return nil
+	})
+	if err != nil {
+		f.logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return slFilmActors, nil
+}
+
+func (f *FilmStorage) GetFilmCast(ctx context.Context, filmID uint64) ([]*models.FilmActor, error) {
+	ctx, span := tracing.Start(ctx, "FilmStorage.GetFilmCast")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageFilm, "GetFilmCast", time.Now())
+
+	var slFilmActors []*models.FilmActor
+
+	err := pgx.BeginFunc(ctx, f.pool, func(tx pgx.Tx) error {
+		isFilmExists, err := repository.SelectIsFilmExists(ctx, tx, filmID)
+		if err != nil {
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		if !isFilmExists {
+			return fmt.Errorf(myerrors.ErrTemplate, ErrFilmNotFound)
+		}
+
+		slFilmActors, err = f.selectFilmCast(ctx, tx, filmID)
+
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return slFilmActors, nil
+}
@@ -4,56 +4,85 @@ import (
 	"context"
 	"fmt"
 	filmrepo "github.com/SanExpett/film-library-backend/internal/film/repository"
+	serverusecases "github.com/SanExpett/film-library-backend/internal/server/usecases"
+	"github.com/SanExpett/film-library-backend/pkg/metrics"
 	"github.com/SanExpett/film-library-backend/pkg/models"
 	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
 	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"github.com/SanExpett/film-library-backend/pkg/tracing"
 	"github.com/SanExpett/film-library-backend/pkg/utils"
 	"go.uber.org/zap"
 	"io"
 )
 
-var _ IFilmStorage = (*filmrepo.FilmStorage)(nil)
+var (
+	_ IFilmStorage = (*filmrepo.FilmStorage)(nil)
+	_ IPolicy      = (*serverusecases.Policy)(nil)
+)
 
 type IFilmStorage interface {
-	AddFilm(ctx context.Context, preFilm *models.FilmWithoutID, userID uint64) (uint64, error)
+	AddFilm(ctx context.Context, preFilm *models.FilmWithCast, userID uint64) (*models.AddedFilm, error)
 	GetFilm(ctx context.Context, filmID uint64) (*models.Film, error)
-	UpdateFilm(ctx context.Context, filmID uint64, userID uint64, updateFields map[string]interface{}) error
-	DeleteFilm(ctx context.Context, filmID uint64, userID uint64) error
+	UpdateFilm(ctx context.Context, filmID uint64, updateFields map[string]interface{}) error
+	DeleteFilm(ctx context.Context, filmID uint64) error
 	GetFilmsListWithActorHandler(ctx context.Context, actorID uint64) ([]*models.Film, error)
 	GetFilmsList(ctx context.Context, limit uint64, offset uint64, sortType uint64) ([]*models.Film, error)
 	SearchFilmByTitle(ctx context.Context, searchedTitle string) ([]*models.Film, error)
 	SearchFilmByActorsName(ctx context.Context, searchedTitle string) ([]*models.Film, error)
+	AddActorToFilm(ctx context.Context, preFilmActor *models.FilmActorWithoutID) (uint64, error)
+	AddActorsToFilm(ctx context.Context, preFilmActors []*models.FilmActorWithoutID) ([]uint64, error)
+	DeleteActorFromFilm(ctx context.Context, filmID uint64, actorID uint64) error
+	ReplaceFilmCast(ctx context.Context, filmID uint64, preFilmActors []*models.FilmActorWithoutID) ([]uint64, error)
+	GetFilmCast(ctx context.Context, filmID uint64) ([]*models.FilmActor, error)
+}
+
+type IPolicy interface {
+	Check(ctx context.Context, userID uint64, permission models.Permission) error
 }
 
 type FilmService struct {
 	storage IFilmStorage
+	policy  IPolicy
 	logger  *zap.SugaredLogger
 }
 
-func NewFilmService(FilmStorage IFilmStorage) (*FilmService, error) {
+func NewFilmService(FilmStorage IFilmStorage, policy IPolicy) (*FilmService, error) {
 	logger, err := my_logger.Get()
 	if err != nil {
 		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
 	}
 
-	return &FilmService{storage: FilmStorage, logger: logger}, nil
+	return &FilmService{storage: FilmStorage, policy: policy, logger: logger}, nil
 }
 
-func (a *FilmService) AddFilm(ctx context.Context, r io.Reader, userID uint64) (uint64, error) {
-	preFilm, err := ValidatePreFilm(r)
+func (a *FilmService) AddFilm(ctx context.Context, r io.Reader, userID uint64) (*models.AddedFilm, error) {
+	ctx, span := tracing.Start(ctx, "FilmService.AddFilm")
+	defer span.End()
+
+	err := a.policy.Check(ctx, userID, models.PermissionFilmCreate)
 	if err != nil {
-		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
 	}
 
-	filmID, err := a.storage.AddFilm(ctx, preFilm, userID)
+	preFilm, err := ValidatePreFilmWithCast(r)
 	if err != nil {
-		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	addedFilm, err := a.storage.AddFilm(ctx, preFilm, userID)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
 	}
 
-	return filmID, nil
+	metrics.FilmsCreated.Inc()
+
+	return addedFilm, nil
 }
 
 func (a *FilmService) GetFilm(ctx context.Context, filmID uint64) (*models.Film, error) {
+	ctx, span := tracing.Start(ctx, "FilmService.GetFilm")
+	defer span.End()
+
 	film, err := a.storage.GetFilm(ctx, filmID)
 	if err != nil {
 		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
@@ -65,7 +94,15 @@ func (a *FilmService) GetFilm(ctx context.Context, filmID uint64) (*models.Film,
 }
 
 func (f *FilmService) DeleteFilm(ctx context.Context, filmID uint64, userID uint64) error {
-	err := f.storage.DeleteFilm(ctx, filmID, userID)
+	ctx, span := tracing.Start(ctx, "FilmService.DeleteFilm")
+	defer span.End()
+
+	err := f.policy.Check(ctx, userID, models.PermissionFilmDelete)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	err = f.storage.DeleteFilm(ctx, filmID)
 	if err != nil {
 		return fmt.Errorf(myerrors.ErrTemplate, err)
 	}
@@ -76,9 +113,15 @@ func (f *FilmService) DeleteFilm(ctx context.Context, filmID uint64, userID uint
 func (a *FilmService) UpdateFilm(ctx context.Context,
 	r io.Reader, isPartialUpdate bool, filmID uint64, userID uint64,
 ) error {
-	var preFilm *models.FilmWithoutID
+	ctx, span := tracing.Start(ctx, "FilmService.UpdateFilm")
+	defer span.End()
+
+	err := a.policy.Check(ctx, userID, models.PermissionFilmUpdate)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
 
-	var err error
+	var preFilm *models.FilmWithoutID
 
 	if isPartialUpdate {
 		preFilm, err = ValidatePartOfPreFilm(r)
@@ -94,7 +137,7 @@ func (a *FilmService) UpdateFilm(ctx context.Context,
 
 	updateFieldsMap := utils.StructToMap(preFilm)
 
-	err = a.storage.UpdateFilm(ctx, filmID, userID, updateFieldsMap)
+	err = a.storage.UpdateFilm(ctx, filmID, updateFieldsMap)
 	if err != nil {
 		return fmt.Errorf(myerrors.ErrTemplate, err)
 	}
@@ -103,6 +146,9 @@ func (a *FilmService) UpdateFilm(ctx context.Context,
 }
 
 func (f *FilmService) GetFilmsListWithActorHandler(ctx context.Context, filmID uint64) ([]*models.Film, error) {
+	ctx, span := tracing.Start(ctx, "FilmService.GetFilmsListWithActorHandler")
+	defer span.End()
+
 	films, err := f.storage.GetFilmsListWithActorHandler(ctx, filmID)
 	if err != nil {
 		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
@@ -117,6 +163,9 @@ func (f *FilmService) GetFilmsListWithActorHandler(ctx context.Context, filmID u
 
 func (f *FilmService) GetFilmsList(ctx context.Context, limit uint64, offset uint64, sortType uint64,
 ) ([]*models.Film, error) {
+	ctx, span := tracing.Start(ctx, "FilmService.GetFilmsList")
+	defer span.End()
+
 	films, err := f.storage.GetFilmsList(ctx, limit, offset, sortType)
 	if err != nil {
 		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
@@ -130,6 +179,9 @@ func (f *FilmService) GetFilmsList(ctx context.Context, limit uint64, offset uin
 }
 
 func (f *FilmService) SearchFilmByTitle(ctx context.Context, searchedInput string) ([]*models.Film, error) {
+	ctx, span := tracing.Start(ctx, "FilmService.SearchFilmByTitle")
+	defer span.End()
+
 	films, err := f.storage.SearchFilmByTitle(ctx, searchedInput)
 	if err != nil {
 		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
@@ -143,6 +195,9 @@ func (f *FilmService) SearchFilmByTitle(ctx context.Context, searchedInput strin
 }
 
 func (f *FilmService) SearchFilmByActorsName(ctx context.Context, searchedInput string) ([]*models.Film, error) {
+	ctx, span := tracing.Start(ctx, "FilmService.SearchFilmByActorsName")
+	defer span.End()
+
 	films, err := f.storage.SearchFilmByActorsName(ctx, searchedInput)
 	if err != nil {
 		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
@@ -154,3 +209,104 @@ func (f *FilmService) SearchFilmByActorsName(ctx context.Context, searchedInput
 
 	return films, nil
 }
+
+func (f *FilmService) AddActorToFilm(ctx context.Context, r io.Reader, filmID uint64, userID uint64) (uint64, error) {
+	ctx, span := tracing.Start(ctx, "FilmService.AddActorToFilm")
+	defer span.End()
+
+	err := f.policy.Check(ctx, userID, models.PermissionCastUpdate)
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	preFilmActor, err := ValidatePreFilmActor(r, filmID)
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	filmActorID, err := f.storage.AddActorToFilm(ctx, preFilmActor)
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return filmActorID, nil
+}
+
+func (f *FilmService) AddActorsToFilm(ctx context.Context, r io.Reader, filmID uint64, userID uint64,
+) ([]uint64, error) {
+	ctx, span := tracing.Start(ctx, "FilmService.AddActorsToFilm")
+	defer span.End()
+
+	err := f.policy.Check(ctx, userID, models.PermissionCastUpdate)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	preFilmActors, err := ValidatePreFilmActorsList(r, filmID)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	filmActorIDs, err := f.storage.AddActorsToFilm(ctx, preFilmActors)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return filmActorIDs, nil
+}
+
+func (f *FilmService) DeleteActorFromFilm(ctx context.Context, filmID uint64, actorID uint64, userID uint64) error {
+	ctx, span := tracing.Start(ctx, "FilmService.DeleteActorFromFilm")
+	defer span.End()
+
+	err := f.policy.Check(ctx, userID, models.PermissionCastUpdate)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	err = f.storage.DeleteActorFromFilm(ctx, filmID, actorID)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+func (f *FilmService) ReplaceFilmCast(ctx context.Context, r io.Reader, filmID uint64, userID uint64,
+) ([]uint64, error) {
+	ctx, span := tracing.Start(ctx, "FilmService.ReplaceFilmCast")
+	defer span.End()
+
+	err := f.policy.Check(ctx, userID, models.PermissionCastUpdate)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	preFilmActors, err := ValidatePreFilmActorsList(r, filmID)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	filmActorIDs, err := f.storage.ReplaceFilmCast(ctx, filmID, preFilmActors)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return filmActorIDs, nil
+}
+
+func (f *FilmService) GetFilmCast(ctx context.Context, filmID uint64) ([]*models.FilmActor, error) {
+	ctx, span := tracing.Start(ctx, "FilmService.GetFilmCast")
+	defer span.End()
+
+	filmActors, err := f.storage.GetFilmCast(ctx, filmID)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	for _, filmActor := range filmActors {
+		filmActor.Sanitize()
+	}
+
+	return filmActors, nil
+}
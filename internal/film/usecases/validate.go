@@ -11,7 +11,13 @@ import (
 )
 
 var (
-	ErrDecodePreFilm = myerrors.NewError("Некорректный json фильма")
+	ErrDecodePreFilm      = myerrors.New(myerrors.KindBadRequest, "film_json_invalid", "Некорректный json фильма")
+	ErrDecodePreFilmActor = myerrors.New(myerrors.KindBadRequest, "film_actor_json_invalid",
+		"Некорректный json актера в составе фильма")
+	ErrDuplicateActorInFilm = myerrors.New(myerrors.KindValidation, "duplicate_actor_in_film",
+		"Актер указан в составе фильма несколько раз")
+	ErrDecodeNewActor = myerrors.New(myerrors.KindBadRequest, "new_actor_json_invalid",
+		"Некорректный json нового актера")
 )
 
 func validateFilmWithoutID(r io.Reader) (*models.FilmWithoutID, error) {
@@ -34,7 +40,8 @@ func validateFilmWithoutID(r io.Reader) (*models.FilmWithoutID, error) {
 	if err != nil {
 		logger.Errorln(err)
 
-		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+		// decoded value is returned with error of validation, so partial update can skip empty fields
+		return preFilm, fmt.Errorf(myerrors.ErrTemplate, err)
 	}
 
 	return preFilm, nil
@@ -42,8 +49,61 @@ func validateFilmWithoutID(r io.Reader) (*models.FilmWithoutID, error) {
 
 func ValidatePreFilm(r io.Reader) (*models.FilmWithoutID, error) {
 	preFilm, err := validateFilmWithoutID(r)
+	if preFilm == nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	if err != nil {
+		return nil, myerrors.NewValidationErrorFrom(err)
+	}
+
+	return preFilm, nil
+}
+
+func ValidatePreFilmWithCast(r io.Reader) (*models.FilmWithCast, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(r)
+	preFilm := &models.FilmWithCast{}
+	if err := decoder.Decode(preFilm); err != nil {
+		logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrDecodePreFilm)
+	}
+
+	preFilm.Trim()
+
+	_, err = govalidator.ValidateStruct(preFilm.FilmWithoutID)
 	if err != nil {
-		return nil, myerrors.NewError(err.Error())
+		logger.Errorln(err)
+
+		return nil, myerrors.NewValidationErrorFrom(err)
+	}
+
+	for _, preActor := range preFilm.NewActors {
+		if preActor == nil {
+			return nil, fmt.Errorf(myerrors.ErrTemplate, ErrDecodeNewActor)
+		}
+
+		_, err = govalidator.ValidateStruct(preActor)
+		if err != nil {
+			logger.Errorln(err)
+
+			return nil, myerrors.NewValidationErrorFrom(err)
+		}
+	}
+
+	actorsIDs := make(map[uint64]struct{}, len(preFilm.ActorsIDs))
+
+	for _, actorID := range preFilm.ActorsIDs {
+		if _, ok := actorsIDs[actorID]; ok {
+			return nil, fmt.Errorf(myerrors.ErrTemplate, ErrDuplicateActorInFilm)
+		}
+
+		actorsIDs[actorID] = struct{}{}
 	}
 
 	return preFilm, nil
@@ -61,16 +121,90 @@ func ValidatePartOfPreFilm(r io.Reader) (*models.FilmWithoutID, error) {
 	}
 
 	if err != nil {
-		validationErrors := govalidator.ErrorsByField(err)
-
-		for field, err := range validationErrors {
-			if err != "non zero value required" {
-				logger.Errorln(err)
+		fields := myerrors.ValidationFields(err, myerrors.ValidatorRequired)
+		if len(fields) != 0 {
+			logger.Errorln(err)
 
-				return nil, myerrors.NewError("%s error: %s", field, err)
-			}
+			return nil, myerrors.NewValidationError(fields...)
 		}
 	}
 
 	return preFilm, nil
 }
+
+func validateFilmActorWithoutID(preFilmActor *models.FilmActorWithoutID, filmID uint64) error {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return err
+	}
+
+	preFilmActor.FilmID = filmID
+	preFilmActor.Trim()
+
+	_, err = govalidator.ValidateStruct(preFilmActor)
+	if err != nil {
+		logger.Errorln(err)
+
+		return myerrors.NewValidationErrorFrom(err)
+	}
+
+	return nil
+}
+
+func ValidatePreFilmActor(r io.Reader, filmID uint64) (*models.FilmActorWithoutID, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(r)
+	preFilmActor := &models.FilmActorWithoutID{}
+	if err := decoder.Decode(preFilmActor); err != nil {
+		logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrDecodePreFilmActor)
+	}
+
+	err = validateFilmActorWithoutID(preFilmActor, filmID)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return preFilmActor, nil
+}
+
+func ValidatePreFilmActorsList(r io.Reader, filmID uint64) ([]*models.FilmActorWithoutID, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(r)
+	var preFilmActors []*models.FilmActorWithoutID
+	if err := decoder.Decode(&preFilmActors); err != nil {
+		logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrDecodePreFilmActor)
+	}
+
+	actorsIDs := make(map[uint64]struct{}, len(preFilmActors))
+
+	for _, preFilmActor := range preFilmActors {
+		if preFilmActor == nil {
+			return nil, fmt.Errorf(myerrors.ErrTemplate, ErrDecodePreFilmActor)
+		}
+
+		err = validateFilmActorWithoutID(preFilmActor, filmID)
+		if err != nil {
+			return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		if _, ok := actorsIDs[preFilmActor.ActorID]; ok {
+			return nil, fmt.Errorf(myerrors.ErrTemplate, ErrDuplicateActorInFilm)
+		}
+
+		actorsIDs[preFilmActor.ActorID] = struct{}{}
+	}
+
+	return preFilmActors, nil
+}
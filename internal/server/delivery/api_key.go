@@ -0,0 +1,51 @@
+package delivery
+
+import (
+	"context"
+	"fmt"
+	"github.com/SanExpett/film-library-backend/pkg/models"
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+	"github.com/SanExpett/film-library-backend/pkg/utils"
+	"net/http"
+	"strings"
+)
+
+const (
+	HeaderAuthorization = "Authorization"
+	AuthSchemeAPIKey    = "ApiKey"
+)
+
+var ErrAPIKeyMalformed = myerrors.New(myerrors.KindUnauthorized, "api_key_malformed",
+	"Заголовок Authorization должен иметь вид: ApiKey <ключ>")
+
+type IAPIKeyResolver interface {
+	UseAPIKey(ctx context.Context, keyHash string) (*models.APIKey, error)
+}
+
+// GetAPIKeyFromHeader resolves api key from `Authorization: ApiKey <key>` header.
+// isPresented is false if request has no Authorization header, then it is authenticated by cookie.
+func (a *Authenticator) GetAPIKeyFromHeader(r *http.Request) (apiKey *models.APIKey, isPresented bool, err error) {
+	header := r.Header.Get(HeaderAuthorization)
+	if header == "" {
+		return nil, false, nil
+	}
+
+	scheme, rawKey, found := strings.Cut(header, " ")
+	rawKey = strings.TrimSpace(rawKey)
+
+	if !found || !strings.EqualFold(scheme, AuthSchemeAPIKey) || rawKey == "" {
+		return nil, true, fmt.Errorf(myerrors.ErrTemplate, ErrAPIKeyMalformed)
+	}
+
+	keyHash, err := utils.Hash256([]byte(rawKey))
+	if err != nil {
+		return nil, true, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	apiKey, err = a.apiKeyResolver.UseAPIKey(r.Context(), keyHash)
+	if err != nil {
+		return nil, true, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return apiKey, true, nil
+}
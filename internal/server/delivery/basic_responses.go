@@ -8,13 +8,10 @@ import (
 )
 
 const (
-	HTTPStatusOk    = 200
-	HTTPStatusError = 222
+	HTTPStatusOk = 200
 
 	StatusResponseSuccessful      = 200
 	StatusRedirectAfterSuccessful = 303
-	StatusErrBadRequest           = 400
-	StatusErrInternalServer       = 500
 )
 
 const (
@@ -22,10 +19,12 @@ const (
 	ErrBadRequest     = "Некорректный запрос"
 )
 
-var ErrCookieNotPresented = myerrors.NewError("Должна быть выставлена cookie, а её нет")
+var ErrCookieNotPresented = myerrors.New(myerrors.KindUnauthorized, "cookie_not_presented",
+	"Должна быть выставлена cookie, а её нет")
 
 const (
-	CookieAuthName = "access_token"
+	CookieAuthName    = "access_token"
+	CookieRefreshName = "refresh_token"
 )
 
 type ResponseBody struct {
@@ -57,20 +56,17 @@ func NewResponseID(ID uint64) *ResponseID {
 	return &ResponseID{Status: StatusRedirectAfterSuccessful, Body: ResponseBodyID{ID: ID}}
 }
 
-type ResponseBodyError struct {
-	Error string `json:"error"`
+type ResponseBodyIDs struct {
+	IDs []uint64 `json:"ids"`
 }
 
-type ErrorResponse struct {
-	Status int               `json:"status"`
-	Body   ResponseBodyError `json:"body"`
+type ResponseIDs struct {
+	Status int             `json:"status"`
+	Body   ResponseBodyIDs `json:"body"`
 }
 
-func NewErrResponse(status int, err string) *ErrorResponse {
-	return &ErrorResponse{
-		Status: status,
-		Body:   ResponseBodyError{Error: err},
-	}
+func NewResponseIDs(IDs []uint64) *ResponseIDs {
+	return &ResponseIDs{Status: StatusRedirectAfterSuccessful, Body: ResponseBodyIDs{IDs: IDs}}
 }
 
 func sendResponse(w http.ResponseWriter, logger *zap.SugaredLogger, response any) {
@@ -89,12 +85,6 @@ func sendResponse(w http.ResponseWriter, logger *zap.SugaredLogger, response any
 	}
 }
 
-func SendErrResponse(w http.ResponseWriter, logger *zap.SugaredLogger, response any) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(HTTPStatusError)
-	sendResponse(w, logger, response)
-}
-
 func SendOkResponse(w http.ResponseWriter, logger *zap.SugaredLogger, response any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(HTTPStatusOk)
@@ -0,0 +1,71 @@
+package delivery
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+)
+
+const (
+	SameSiteLax    = "lax"
+	SameSiteStrict = "strict"
+	SameSiteNone   = "none"
+)
+
+var (
+	ErrUnknownSameSite      = myerrors.NewError("Неизвестное значение SameSite для cookie, ожидается lax, strict или none")
+	ErrSameSiteNoneInsecure = myerrors.NewError("SameSite=none для cookie требует Secure")
+)
+
+// CookieConfig holds attributes which are set for every cookie of server.
+type CookieConfig struct {
+	Domain   string
+	Secure   bool
+	SameSite http.SameSite
+}
+
+func NewCookieConfig(domain string, secure bool, sameSite string) (*CookieConfig, error) {
+	cookieConfig := &CookieConfig{Domain: domain, Secure: secure, SameSite: http.SameSiteLaxMode}
+
+	switch strings.ToLower(sameSite) {
+	case SameSiteLax:
+		cookieConfig.SameSite = http.SameSiteLaxMode
+	case SameSiteStrict:
+		cookieConfig.SameSite = http.SameSiteStrictMode
+	case SameSiteNone:
+		if !secure {
+			return nil, fmt.Errorf(myerrors.ErrTemplate, ErrSameSiteNoneInsecure)
+		}
+
+		cookieConfig.SameSite = http.SameSiteNoneMode
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSameSite, sameSite)
+	}
+
+	return cookieConfig, nil
+}
+
+func (c *CookieConfig) NewCookie(name string, value string, path string, expires time.Time, httpOnly bool,
+) *http.Cookie {
+	return &http.Cookie{ //nolint:exhaustruct
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   c.Domain,
+		Expires:  expires,
+		Secure:   c.Secure,
+		HttpOnly: httpOnly,
+		SameSite: c.SameSite,
+	}
+}
+
+// NewExpiredCookie makes cookie which deletes cookie with the same name and path in browser.
+func (c *CookieConfig) NewExpiredCookie(name string, path string, httpOnly bool) *http.Cookie {
+	cookie := c.NewCookie(name, "", path, time.Unix(0, 0), httpOnly)
+	cookie.MaxAge = -1
+
+	return cookie
+}
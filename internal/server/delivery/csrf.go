@@ -0,0 +1,56 @@
+package delivery
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+)
+
+const (
+	CookieCSRFName = "csrf_token"
+	HeaderCSRFName = "X-CSRF-Token"
+
+	lenCSRFToken  = 32
+	csrfTokenLife = 30 * 24 * time.Hour
+)
+
+type ResponseBodyCSRFToken struct {
+	CSRFToken string `json:"csrf_token"`
+}
+
+type CSRFTokenResponse struct {
+	Status int                   `json:"status"`
+	Body   ResponseBodyCSRFToken `json:"body"`
+}
+
+func NewCSRFTokenResponse(csrfToken string) *CSRFTokenResponse {
+	return &CSRFTokenResponse{Status: StatusResponseSuccessful, Body: ResponseBodyCSRFToken{CSRFToken: csrfToken}}
+}
+
+func newCSRFToken() (string, error) {
+	rawToken := make([]byte, lenCSRFToken)
+
+	_, err := rand.Read(rawToken)
+	if err != nil {
+		return "", fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return hex.EncodeToString(rawToken), nil
+}
+
+// SetNewCSRFToken sets new csrf token in cookie and returns it. The cookie is readable by js,
+// because client has to copy it to X-CSRF-Token header of every state-changing request.
+func SetNewCSRFToken(w http.ResponseWriter, cookieConfig *CookieConfig) (string, error) {
+	csrfToken, err := newCSRFToken()
+	if err != nil {
+		return "", err
+	}
+
+	http.SetCookie(w, cookieConfig.NewCookie(CookieCSRFName, csrfToken, "/", time.Now().Add(csrfTokenLife), false))
+
+	return csrfToken, nil
+}
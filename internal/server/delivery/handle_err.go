@@ -1,19 +1,140 @@
 package delivery
 
 import (
+	"context"
 	"errors"
+	"github.com/SanExpett/film-library-backend/pkg/i18n"
 	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
 	"go.uber.org/zap"
 	"net/http"
+	"strings"
 )
 
-func HandleErr(w http.ResponseWriter, logger *zap.SugaredLogger, err error) {
+const (
+	ContentTypeProblem    = "application/problem+json"
+	HeaderContentLanguage = "Content-Language"
+
+	// ProblemTypeBlank is type of problem without own documentation page, title of it is text of http status
+	ProblemTypeBlank = "about:blank"
+
+	CodeInternalServer = "internal_error"
+)
+
+var ErrRequestTimeout = myerrors.New(myerrors.KindTimeout, "request_timeout",
+	"Запрос выполнялся слишком долго, попробуйте позже")
+
+// Problem is body of error response in format of RFC 7807. Code is stable and doesn't change with
+// message, so client decides what to do by Code and shows Detail to user. Detail and details of Errors
+// are translated to language of request. Errors are set for validation error.
+type Problem struct {
+	Type   string                `json:"type"`
+	Title  string                `json:"title"`
+	Status int                   `json:"status"`
+	Detail string                `json:"detail"`
+	Code   string                `json:"code"`
+	Errors []myerrors.FieldError `json:"errors,omitempty"`
+}
+
+func NewProblem(status int, code string, detail string) *Problem {
+	return &Problem{ //nolint:exhaustruct
+		Type:   ProblemTypeBlank,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+func NewInternalServerProblem(lang i18n.Lang) *Problem {
+	detail, ok := i18n.Message(lang, CodeInternalServer)
+	if !ok {
+		detail = ErrInternalServer
+	}
+
+	return NewProblem(http.StatusInternalServerError, CodeInternalServer, detail)
+}
+
+var statusByKind = map[myerrors.Kind]int{ //nolint:gochecknoglobals
+	myerrors.KindBadRequest:      http.StatusBadRequest,
+	myerrors.KindValidation:      http.StatusUnprocessableEntity,
+	myerrors.KindUnauthorized:    http.StatusUnauthorized,
+	myerrors.KindForbidden:       http.StatusForbidden,
+	myerrors.KindNotFound:        http.StatusNotFound,
+	myerrors.KindConflict:        http.StatusConflict,
+	myerrors.KindTooManyRequests: http.StatusTooManyRequests,
+	myerrors.KindTimeout:         http.StatusGatewayTimeout,
+}
+
+// StatusOfKind returns http status for kind of error, unknown kind is bad request.
+func StatusOfKind(kind myerrors.Kind) int {
+	status, ok := statusByKind[kind]
+	if !ok {
+		return http.StatusBadRequest
+	}
+
+	return status
+}
+
+// NewProblemFromError translates message of error by its code, error without translation keeps its own message.
+func NewProblemFromError(err *myerrors.Error, lang i18n.Lang) *Problem {
+	detail, ok := i18n.Message(lang, err.Code(), err.Args()...)
+	if !ok {
+		detail = err.Error()
+	}
+
+	fields := make([]myerrors.FieldError, 0, len(err.Fields()))
+	details := make([]string, 0, len(err.Fields()))
+
+	for _, field := range err.Fields() {
+		if message, ok := i18n.FieldMessage(lang, field.Field, field.Code); ok {
+			field.Detail = message
+		}
+
+		fields = append(fields, field)
+		details = append(details, field.Field+": "+field.Detail)
+	}
+
+	if len(details) != 0 {
+		detail += ": " + strings.Join(details, "; ")
+	}
+
+	problem := NewProblem(StatusOfKind(err.Kind()), err.Code(), detail)
+	problem.Errors = fields
+
+	return problem
+}
+
+// HandleErr sends expected error with http status of its kind, unexpected errors are hidden
+// behind internal server error. Nothing is sent if client has gone, expired deadline of request is reported as timeout.
+func HandleErr(w http.ResponseWriter, r *http.Request, logger *zap.SugaredLogger, err error) {
+	if errors.Is(err, context.Canceled) {
+		logger.Infof("request canceled by client: %+v", err)
+
+		return
+	}
+
+	lang := RequestLang(r)
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		SendErrResponse(w, logger, lang, NewProblemFromError(ErrRequestTimeout, lang))
+
+		return
+	}
+
 	myErr := &myerrors.Error{}
 	if errors.As(err, &myErr) {
-		SendErrResponse(w, logger, NewErrResponse(StatusErrBadRequest, err.Error()))
+		SendErrResponse(w, logger, lang, NewProblemFromError(myErr, lang))
 
 		return
 	}
 
-	SendErrResponse(w, logger, NewErrResponse(StatusErrInternalServer, ErrInternalServer))
+	SendErrResponse(w, logger, lang, NewInternalServerProblem(lang))
+}
+
+func SendErrResponse(w http.ResponseWriter, logger *zap.SugaredLogger, lang i18n.Lang, problem *Problem) {
+	w.Header().Set("Content-Type", ContentTypeProblem)
+	w.Header().Set(HeaderContentLanguage, string(lang))
+	w.Header().Add("Vary", i18n.HeaderAcceptLanguage)
+	w.WriteHeader(problem.Status)
+	sendResponse(w, logger, problem)
 }
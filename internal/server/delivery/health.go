@@ -0,0 +1,156 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/SanExpett/film-library-backend/internal/server/repository"
+	"github.com/SanExpett/film-library-backend/pkg/buildinfo"
+	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"net/http"
+	"sync/atomic"
+)
+
+const (
+	StatusAlive    = "alive"
+	StatusReady    = "ready"
+	StatusNotReady = "not ready"
+
+	ReasonDraining         = "server is shutting down"
+	ReasonPostgres         = "postgres is unreachable"
+	ReasonMigrationsDirty  = "last migration failed"
+	ReasonMigrationsUnread = "migrations version is unavailable"
+	ReasonMigrationVersion = "migrations version %d, expected %d"
+)
+
+var _ IHealthStorage = (*repository.HealthStorage)(nil)
+
+type IHealthStorage interface {
+	Ping(ctx context.Context) error
+	GetMigrationVersion(ctx context.Context) (version uint64, dirty bool, err error)
+}
+
+// Readiness tells orchestrator whether server accepts new requests. Server is not ready
+// before it starts listening and while it drains in-flight requests on shutdown.
+type Readiness struct {
+	ready atomic.Bool
+}
+
+func NewReadiness() *Readiness {
+	return &Readiness{} //nolint:exhaustruct
+}
+
+func (r *Readiness) SetReady(ready bool) {
+	r.ready.Store(ready)
+}
+
+func (r *Readiness) IsReady() bool {
+	return r.ready.Load()
+}
+
+type HealthResponse struct {
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// HealthHandler serves probes of load balancer and orchestrator. Its routes are outside of api base path,
+// without cors and auth, so they have no swagger annotations.
+type HealthHandler struct {
+	readiness        *Readiness
+	storage          IHealthStorage
+	migrationVersion uint64
+}
+
+// NewHealthHandler returns handler, server is ready only when migrations of db have migrationVersion.
+func NewHealthHandler(readiness *Readiness, storage IHealthStorage, migrationVersion uint64) *HealthHandler {
+	return &HealthHandler{
+		readiness:        readiness,
+		storage:          storage,
+		migrationVersion: migrationVersion,
+	}
+}
+
+// sendJSON writes real http status: probes of orchestrator don't read body.
+func sendJSON(w http.ResponseWriter, r *http.Request, httpStatus int, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(httpStatus)
+
+	err := json.NewEncoder(w).Encode(response)
+	if err != nil {
+		my_logger.FromCtx(r.Context()).Errorln(err)
+	}
+}
+
+// HealthzHandler answers 200 while process is alive, it doesn't check dependencies.
+func (h *HealthHandler) HealthzHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	sendJSON(w, r, http.StatusOK, HealthResponse{Status: StatusAlive}) //nolint:exhaustruct
+}
+
+// notReadyReason returns why server can't serve requests now, it is empty if server is ready.
+// Reason is public, so errors of postgres are only logged.
+func (h *HealthHandler) notReadyReason(ctx context.Context) string {
+	if !h.readiness.IsReady() {
+		return ReasonDraining
+	}
+
+	if err := h.storage.Ping(ctx); err != nil {
+		my_logger.FromCtx(ctx).Errorf("in notReadyReason: ping postgres: %+v", err)
+
+		return ReasonPostgres
+	}
+
+	version, dirty, err := h.storage.GetMigrationVersion(ctx)
+	if err != nil {
+		my_logger.FromCtx(ctx).Errorf("in notReadyReason: get migration version: %+v", err)
+
+		return ReasonMigrationsUnread
+	}
+
+	if dirty {
+		return ReasonMigrationsDirty
+	}
+
+	if version != h.migrationVersion {
+		return fmt.Sprintf(ReasonMigrationVersion, version, h.migrationVersion)
+	}
+
+	return ""
+}
+
+// ReadyzHandler answers 503 while server is draining, postgres is unreachable or migrations
+// are not at expected version, so orchestrator doesn't send requests to it.
+func (h *HealthHandler) ReadyzHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	reason := h.notReadyReason(r.Context())
+	if reason != "" {
+		my_logger.FromCtx(r.Context()).Warnf("in ReadyzHandler: not ready: %s", reason)
+		sendJSON(w, r, http.StatusServiceUnavailable, HealthResponse{Status: StatusNotReady, Reason: reason})
+
+		return
+	}
+
+	sendJSON(w, r, http.StatusOK, HealthResponse{Status: StatusReady}) //nolint:exhaustruct
+}
+
+// VersionHandler returns commit, build time and go version of running binary.
+func (h *HealthHandler) VersionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	sendJSON(w, r, http.StatusOK, buildinfo.Get())
+}
@@ -0,0 +1,16 @@
+package delivery
+
+import (
+	"github.com/SanExpett/film-library-backend/pkg/i18n"
+	"net/http"
+)
+
+// RequestLang returns language of messages for client: locale from profile of signed in user,
+// which is put into context by middleware.Auth, otherwise the best supported language from Accept-Language.
+func RequestLang(r *http.Request) i18n.Lang {
+	if lang, ok := i18n.FromContext(r.Context()); ok {
+		return lang
+	}
+
+	return i18n.FromAcceptLanguage(r.Header.Get(i18n.HeaderAcceptLanguage))
+}
@@ -1,9 +1,12 @@
 package mux
 
 import (
-	"context"
+	"github.com/SanExpett/film-library-backend/internal/server/delivery"
+	"github.com/SanExpett/film-library-backend/pkg/metrics"
 	"github.com/SanExpett/film-library-backend/pkg/middleware"
 	"net/http"
+	"strings"
+	"time"
 
 	actordelivery "github.com/SanExpett/film-library-backend/internal/actor/delivery"
 	filmdelivery "github.com/SanExpett/film-library-backend/internal/film/delivery"
@@ -13,25 +16,65 @@ import (
 )
 
 type ConfigMux struct {
-	addrOrigin string
-	schema     string
-	portServer string
+	addrOrigin        string
+	schema            string
+	portServer        string
+	allowLegacySignIn bool
+	cookieConfig      *delivery.CookieConfig
+	appURL            string
+	requestTimeout    time.Duration
+	routeTimeouts     map[string]time.Duration
+	migrationVersion  uint64
 }
 
-func NewConfigMux(addrOrigin string, schema string, portServer string) *ConfigMux {
+func NewConfigMux(addrOrigin string, schema string, portServer string, allowLegacySignIn bool,
+	cookieConfig *delivery.CookieConfig, appURL string, requestTimeout time.Duration,
+	routeTimeouts map[string]time.Duration, migrationVersion uint64,
+) *ConfigMux {
 	return &ConfigMux{
-		addrOrigin: addrOrigin,
-		schema:     schema,
-		portServer: portServer,
+		addrOrigin:        addrOrigin,
+		schema:            schema,
+		portServer:        portServer,
+		allowLegacySignIn: allowLegacySignIn,
+		cookieConfig:      cookieConfig,
+		appURL:            appURL,
+		requestTimeout:    requestTimeout,
+		routeTimeouts:     routeTimeouts,
+		migrationVersion:  migrationVersion,
 	}
 }
 
-func NewMux(ctx context.Context, configMux *ConfigMux, userService userdelivery.IUserService,
-	actorService actordelivery.IActorService, filmService filmdelivery.IFilmService, logger *zap.SugaredLogger,
+// timeout returns deadline of requests to route, it is set in routeTimeouts or requestTimeout by default.
+// Pattern of v2 route starts with method, timeout of its path is applied to all methods.
+func (c *ConfigMux) timeout(pattern string) time.Duration {
+	if timeout, ok := c.routeTimeouts[pattern]; ok {
+		return timeout
+	}
+
+	if _, path, hasMethod := strings.Cut(pattern, " "); hasMethod {
+		if timeout, ok := c.routeTimeouts[path]; ok {
+			return timeout
+		}
+	}
+
+	return c.requestTimeout
+}
+
+// methodRoute is handler of one method of v2 resource.
+type methodRoute struct {
+	method  string
+	access  middleware.Access
+	handler http.HandlerFunc
+}
+
+func NewMux(configMux *ConfigMux, readiness *delivery.Readiness, healthStorage delivery.IHealthStorage,
+	authenticator *delivery.Authenticator, userService userdelivery.IUserService, actorService actordelivery.IActorService,
+	filmService filmdelivery.IFilmService, logger *zap.SugaredLogger,
 ) (http.Handler, error) {
 	router := http.NewServeMux()
 
-	userHandler, err := userdelivery.NewUserHandler(userService)
+	userHandler, err := userdelivery.NewUserHandler(userService, authenticator, configMux.allowLegacySignIn,
+		configMux.cookieConfig, configMux.appURL)
 	if err != nil {
 		return nil, err
 	}
@@ -46,42 +89,127 @@ func NewMux(ctx context.Context, configMux *ConfigMux, userService userdelivery.
 		return nil, err
 	}
 
-	router.Handle("/api/v1/signup", middleware.Context(ctx,
-		middleware.SetupCORS(userHandler.SignUpHandler, configMux.addrOrigin, configMux.schema)))
-	router.Handle("/api/v1/signin", middleware.Context(ctx,
-		middleware.SetupCORS(userHandler.SignInHandler, configMux.addrOrigin, configMux.schema)))
-	router.Handle("/api/v1/logout", middleware.Context(ctx, http.HandlerFunc(userHandler.LogOutHandler)))
-
-	router.Handle("/api/v1/actor/add", middleware.Context(ctx,
-		middleware.SetupCORS(actorHandler.AddActorHandler, configMux.addrOrigin, configMux.schema)))
-	router.Handle("/api/v1/actor/get", middleware.Context(ctx,
-		middleware.SetupCORS(actorHandler.GetActorHandler, configMux.addrOrigin, configMux.schema)))
-	router.Handle("/api/v1/actor/update", middleware.Context(ctx,
-		middleware.SetupCORS(actorHandler.UpdateActorHandler, configMux.addrOrigin, configMux.schema)))
-	router.Handle("/api/v1/actor/delete", middleware.Context(ctx,
-		middleware.SetupCORS(actorHandler.DeleteActorHandler, configMux.addrOrigin, configMux.schema)))
-	router.Handle("/api/v1/actor/get_list_of_actors_in_film", middleware.Context(ctx,
-		middleware.SetupCORS(actorHandler.GetActorsListInFilmHandler, configMux.addrOrigin, configMux.schema)))
-
-	router.Handle("/api/v1/film/add", middleware.Context(ctx,
-		middleware.SetupCORS(filmHandler.AddFilmHandler, configMux.addrOrigin, configMux.schema)))
-	router.Handle("/api/v1/film/get", middleware.Context(ctx,
-		middleware.SetupCORS(filmHandler.GetFilmHandler, configMux.addrOrigin, configMux.schema)))
-	router.Handle("/api/v1/film/update", middleware.Context(ctx,
-		middleware.SetupCORS(filmHandler.UpdateFilmHandler, configMux.addrOrigin, configMux.schema)))
-	router.Handle("/api/v1/film/delete", middleware.Context(ctx,
-		middleware.SetupCORS(filmHandler.DeleteFilmHandler, configMux.addrOrigin, configMux.schema)))
-	router.Handle("/api/v1/film/get_list_of_films_with_actor", middleware.Context(ctx,
-		middleware.SetupCORS(filmHandler.GetFilmsListWithActorHandler, configMux.addrOrigin, configMux.schema)))
-	router.Handle("/api/v1/film/get_list_of_films", middleware.Context(ctx,
-		middleware.SetupCORS(filmHandler.GetFilmsListHandler, configMux.addrOrigin, configMux.schema)))
-	router.Handle("/api/v1/film/search_by_title", middleware.Context(ctx,
-		middleware.SetupCORS(filmHandler.SearchFilmByTitleHandler, configMux.addrOrigin, configMux.schema)))
-	router.Handle("/api/v1/film/search_by_actors_name", middleware.Context(ctx,
-		middleware.SetupCORS(filmHandler.SearchFilmByActorsNameHandler, configMux.addrOrigin, configMux.schema)))
+	healthHandler := delivery.NewHealthHandler(readiness, healthStorage, configMux.migrationVersion)
+
+	// route registers handler with deadline of route, context of request is canceled also
+	// when client disconnects. Requests to route are counted in metrics and traced.
+	route := func(pattern string, handler http.Handler) {
+		router.Handle(pattern, middleware.Route(pattern, middleware.Metrics(pattern, middleware.Tracing(pattern,
+			middleware.Timeout(configMux.timeout(pattern), handler)))))
+	}
+
+	// handle declares access to route. Principal is resolved by middleware.Auth inside of CORS,
+	// so preflight requests are not authenticated.
+	handle := func(pattern string, access middleware.Access, handler http.HandlerFunc) {
+		route(pattern, middleware.SetupCORS(middleware.Auth(authenticator, access, handler, logger).ServeHTTP,
+			configMux.addrOrigin, configMux.schema))
+	}
+
+	// resource declares routes of v2 api: handler and access for every method of path. Router answers 405
+	// to other methods, preflight request to path is answered by CORS.
+	resource := func(path string, methodRoutes ...methodRoute) {
+		for _, methodRoute := range methodRoutes {
+			handle(methodRoute.method+" "+path, methodRoute.access, methodRoute.handler)
+		}
+
+		handle(http.MethodOptions+" "+path, middleware.AccessPublic, func(http.ResponseWriter, *http.Request) {})
+	}
+
+	handle("/api/v1/signup", middleware.AccessPublic, userHandler.SignUpHandler)
+	handle("/api/v1/signin", middleware.AccessPublic, userHandler.SignInHandler)
+	route("/api/v1/oidc/login", http.HandlerFunc(userHandler.OIDCLoginHandler))
+	route("/api/v1/oidc/callback", http.HandlerFunc(userHandler.OIDCCallbackHandler))
+	route("/api/v1/logout", http.HandlerFunc(userHandler.LogOutHandler))
+	handle("/api/v1/csrf_token", middleware.AccessPublic, userHandler.CSRFTokenHandler)
+	handle("/api/v1/refresh", middleware.AccessPublic, userHandler.RefreshHandler)
+	handle("/api/v1/logout_all", middleware.AccessSession, userHandler.LogOutAllHandler)
+	handle("/api/v1/sessions", middleware.AccessAuthenticated, userHandler.GetSessionsHandler)
+	handle("/api/v1/password_reset/request", middleware.AccessPublic, userHandler.RequestPasswordResetHandler)
+	handle("/api/v1/password_reset/confirm", middleware.AccessPublic, userHandler.ConfirmPasswordResetHandler)
+	handle("/api/v1/email_verification/request", middleware.AccessAuthenticated,
+		userHandler.RequestEmailVerificationHandler)
+	handle("/api/v1/email_verification/confirm", middleware.AccessPublic, userHandler.ConfirmEmailVerificationHandler)
+	handle("/api/v1/me", middleware.AccessAuthenticated, userHandler.MeHandler)
+	handle("/api/v1/me/profile", middleware.AccessAuthenticated, userHandler.UpdateProfileHandler)
+	handle("/api/v1/me/password", middleware.AccessAuthenticated, userHandler.ChangePasswordHandler)
+	handle("/api/v1/me/email", middleware.AccessSession, userHandler.RequestEmailChangeHandler)
+	handle("/api/v1/me/email/confirm", middleware.AccessPublic, userHandler.ConfirmEmailChangeHandler)
+	handle("/api/v1/api_keys", middleware.AccessSession, userHandler.APIKeysHandler)
+	handle("/api/v1/api_keys/revoke", middleware.AccessSession, userHandler.RevokeAPIKeyHandler)
+	handle("/api/v1/user/grant_role", middleware.AccessAdmin, userHandler.GrantRoleHandler)
+	handle("/api/v1/user/revoke_role", middleware.AccessAdmin, userHandler.RevokeRoleHandler)
+	handle("/api/v1/user/get_roles", middleware.AccessAuthenticated, userHandler.GetUserRolesHandler)
+	handle("/api/v1/user/get_roles_list", middleware.AccessPublic, userHandler.GetRolesListHandler)
+	handle("/api/v1/user/get_list", middleware.AccessAdmin, userHandler.GetUsersListHandler)
+	handle("/api/v1/user/search_by_email", middleware.AccessAdmin, userHandler.SearchUsersByEmailHandler)
+	handle("/api/v1/user/suspend", middleware.AccessAdmin, userHandler.SuspendUserHandler)
+	handle("/api/v1/user/unsuspend", middleware.AccessAdmin, userHandler.UnsuspendUserHandler)
+	handle("/api/v1/user/delete", middleware.AccessAdmin, userHandler.DeleteUserHandler)
+	handle("/api/v1/user/unlock", middleware.AccessAdmin, userHandler.UnlockUserHandler)
+
+	handle("/api/v1/actor/add", middleware.AccessAuthenticated, actorHandler.AddActorHandler)
+	handle("/api/v1/actor/get", middleware.AccessPublic, actorHandler.GetActorHandler)
+	handle("/api/v1/actor/update", middleware.AccessAuthenticated, actorHandler.UpdateActorHandler)
+	handle("/api/v1/actor/delete", middleware.AccessAuthenticated, actorHandler.DeleteActorHandler)
+	handle("/api/v1/actor/get_list_of_actors_in_film", middleware.AccessPublic, actorHandler.GetActorsListInFilmHandler)
+	handle("/api/v1/actor/add_film", middleware.AccessAuthenticated, actorHandler.AddFilmToActorHandler)
+	handle("/api/v1/actor/add_films", middleware.AccessAuthenticated, actorHandler.AddFilmsToActorHandler)
+	handle("/api/v1/actor/delete_film", middleware.AccessAuthenticated, actorHandler.DeleteFilmFromActorHandler)
+
+	handle("/api/v1/film/add", middleware.AccessAuthenticated, filmHandler.AddFilmHandler)
+	handle("/api/v1/film/get", middleware.AccessPublic, filmHandler.GetFilmHandler)
+	handle("/api/v1/film/update", middleware.AccessAuthenticated, filmHandler.UpdateFilmHandler)
+	handle("/api/v1/film/delete", middleware.AccessAuthenticated, filmHandler.DeleteFilmHandler)
+	handle("/api/v1/film/get_list_of_films_with_actor", middleware.AccessPublic,
+		filmHandler.GetFilmsListWithActorHandler)
+	handle("/api/v1/film/get_list_of_films", middleware.AccessPublic, filmHandler.GetFilmsListHandler)
+	handle("/api/v1/film/search_by_title", middleware.AccessPublic, filmHandler.SearchFilmByTitleHandler)
+	handle("/api/v1/film/search_by_actors_name", middleware.AccessPublic, filmHandler.SearchFilmByActorsNameHandler)
+	handle("/api/v1/film/add_actor", middleware.AccessAuthenticated, filmHandler.AddActorToFilmHandler)
+	handle("/api/v1/film/add_actors", middleware.AccessAuthenticated, filmHandler.AddActorsToFilmHandler)
+	handle("/api/v1/film/delete_actor", middleware.AccessAuthenticated, filmHandler.DeleteActorFromFilmHandler)
+	handle("/api/v1/film/replace_cast", middleware.AccessAuthenticated, filmHandler.ReplaceFilmCastHandler)
+	handle("/api/v1/film/get_cast", middleware.AccessPublic, filmHandler.GetFilmCastHandler)
+
+	// v2 api has the same handlers as v1, id of resource is taken from path instead of query
+	resource("/api/v2/films",
+		methodRoute{http.MethodGet, middleware.AccessPublic, filmHandler.GetFilmsListHandler},
+		methodRoute{http.MethodPost, middleware.AccessAuthenticated, filmHandler.AddFilmHandler})
+	resource("/api/v2/films/{id}",
+		methodRoute{http.MethodGet, middleware.AccessPublic, filmHandler.GetFilmHandler},
+		methodRoute{http.MethodPut, middleware.AccessAuthenticated, filmHandler.UpdateFilmHandler},
+		methodRoute{http.MethodPatch, middleware.AccessAuthenticated, filmHandler.UpdateFilmHandler},
+		methodRoute{http.MethodDelete, middleware.AccessAuthenticated, filmHandler.DeleteFilmHandler})
+	resource("/api/v2/films/{id}/actors",
+		methodRoute{http.MethodGet, middleware.AccessPublic, actorHandler.GetActorsListInFilmHandler},
+		methodRoute{http.MethodPost, middleware.AccessAuthenticated, filmHandler.AddActorToFilmHandler},
+		methodRoute{http.MethodPut, middleware.AccessAuthenticated, filmHandler.ReplaceFilmCastHandler})
+	resource("/api/v2/films/{id}/actors/{actor_id}",
+		methodRoute{http.MethodDelete, middleware.AccessAuthenticated, filmHandler.DeleteActorFromFilmHandler})
+	resource("/api/v2/films/{id}/cast",
+		methodRoute{http.MethodGet, middleware.AccessPublic, filmHandler.GetFilmCastHandler})
+
+	resource("/api/v2/actors",
+		methodRoute{http.MethodPost, middleware.AccessAuthenticated, actorHandler.AddActorHandler})
+	resource("/api/v2/actors/{id}",
+		methodRoute{http.MethodGet, middleware.AccessPublic, actorHandler.GetActorHandler},
+		methodRoute{http.MethodPut, middleware.AccessAuthenticated, actorHandler.UpdateActorHandler},
+		methodRoute{http.MethodPatch, middleware.AccessAuthenticated, actorHandler.UpdateActorHandler},
+		methodRoute{http.MethodDelete, middleware.AccessAuthenticated, actorHandler.DeleteActorHandler})
+	resource("/api/v2/actors/{id}/films",
+		methodRoute{http.MethodGet, middleware.AccessPublic, filmHandler.GetFilmsListWithActorHandler},
+		methodRoute{http.MethodPost, middleware.AccessAuthenticated, actorHandler.AddFilmToActorHandler})
+	resource("/api/v2/actors/{id}/films/{film_id}",
+		methodRoute{http.MethodDelete, middleware.AccessAuthenticated, actorHandler.DeleteFilmFromActorHandler})
+
+	route("/.well-known/jwks.json", http.HandlerFunc(userHandler.JWKSHandler))
+	route("/healthz", http.HandlerFunc(healthHandler.HealthzHandler))
+	route("/readyz", http.HandlerFunc(healthHandler.ReadyzHandler))
+	route("/version", http.HandlerFunc(healthHandler.VersionHandler))
+	route("/metrics", metrics.Handler())
 
 	mux := http.NewServeMux()
-	mux.Handle("/", middleware.Panic(router, logger))
+	mux.Handle("/", middleware.AccessLog(middleware.Panic(middleware.CSRF(router, logger), logger), logger))
 
 	return mux, nil
 }
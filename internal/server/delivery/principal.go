@@ -0,0 +1,113 @@
+package delivery
+
+import (
+	"context"
+	"fmt"
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+	"github.com/SanExpett/film-library-backend/pkg/principal"
+	"net/http"
+)
+
+var ErrNoPrincipal = myerrors.New(myerrors.KindUnauthorized, "unauthorized",
+	"Вы не авторизованы")
+
+type IPrincipalStorage interface {
+	GetPrincipal(ctx context.Context, userID uint64) (*principal.Principal, error)
+}
+
+var ErrNoAuthStorage = myerrors.NewError(
+	"Не задано хранилище пользователей, сессий или api ключей для авторизации запросов")
+
+// Authenticator resolves principal of request, it is created once at start of server and passed
+// to middleware.Auth and handlers which read access token themselves.
+type Authenticator struct {
+	principalStorage IPrincipalStorage
+	sessionChecker   ISessionChecker
+	apiKeyResolver   IAPIKeyResolver
+}
+
+// NewAuthenticator fails without storages, so server can't start with authorization, revocation
+// of sessions or api keys turned off. Roles of principal and state of session are taken from storages on every request,
+// so revoked role, suspension or logout take effect at once.
+func NewAuthenticator(principalStorage IPrincipalStorage, sessionChecker ISessionChecker,
+	apiKeyResolver IAPIKeyResolver,
+) (*Authenticator, error) {
+	if principalStorage == nil || sessionChecker == nil || apiKeyResolver == nil {
+		return nil, ErrNoAuthStorage
+	}
+
+	return &Authenticator{
+		principalStorage: principalStorage,
+		sessionChecker:   sessionChecker,
+		apiKeyResolver:   apiKeyResolver,
+	}, nil
+}
+
+// ResolvePrincipal authenticates request by api key from Authorization header or, if there is no header,
+// by access token cookie. Request with Authorization header is never authenticated by cookie.
+func (a *Authenticator) ResolvePrincipal(r *http.Request) (*principal.Principal, error) {
+	apiKey, isAPIKey, err := a.GetAPIKeyFromHeader(r)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	var userID, sessionID uint64
+
+	if isAPIKey {
+		userID = apiKey.UserID
+	} else {
+		userPayload, err := a.GetJwtPayloadFromCookie(r)
+		if err != nil {
+			return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		userID, sessionID = userPayload.UserID, userPayload.SessionID
+	}
+
+	requestPrincipal, err := a.principalStorage.GetPrincipal(r.Context(), userID)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	requestPrincipal.SessionID = sessionID
+	requestPrincipal.APIKey = apiKey
+
+	return requestPrincipal, nil
+}
+
+// GetPrincipal returns principal put into request context by middleware.Auth.
+func GetPrincipal(r *http.Request) (*principal.Principal, error) {
+	requestPrincipal, ok := principal.FromContext(r.Context())
+	if !ok {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrNoPrincipal)
+	}
+
+	return requestPrincipal, nil
+}
+
+func GetUserID(r *http.Request) (uint64, error) {
+	requestPrincipal, err := GetPrincipal(r)
+	if err != nil {
+		return 0, err
+	}
+
+	return requestPrincipal.UserID, nil
+}
+
+var ErrSessionOnly = myerrors.New(myerrors.KindForbidden, "session_only",
+	"Действие недоступно по api ключу, войдите в аккаунт")
+
+// GetSessionPrincipal returns principal of request signed in by access token cookie.
+// Request authenticated by api key is rejected, so leaked key can't be used to manage account.
+func GetSessionPrincipal(r *http.Request) (*principal.Principal, error) {
+	requestPrincipal, err := GetPrincipal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if requestPrincipal.IsAPIKey() {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrSessionOnly)
+	}
+
+	return requestPrincipal, nil
+}
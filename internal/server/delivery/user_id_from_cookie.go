@@ -1,6 +1,7 @@
 package delivery
 
 import (
+	"context"
 	"fmt"
 	"github.com/SanExpett/film-library-backend/pkg/jwt"
 	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
@@ -8,25 +9,47 @@ import (
 	"net/http"
 )
 
-func GetUserIDFromCookie(r *http.Request) (uint64, error) {
+var ErrSessionRevoked = myerrors.New(myerrors.KindUnauthorized, "session_revoked", "Сессия завершена, войдите заново")
+
+type ISessionChecker interface {
+	IsSessionActive(ctx context.Context, sessionID uint64, userID uint64) (bool, error)
+}
+
+// GetJwtPayloadFromCookie returns payload of access token from cookie. Token of revoked session is rejected
+// even if it is not expired yet.
+func (a *Authenticator) GetJwtPayloadFromCookie(r *http.Request) (*jwt.UserJwtPayload, error) {
 	logger, err := my_logger.Get()
 	if err != nil {
-		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
 	}
 
 	cookie, err := r.Cookie(CookieAuthName)
 	if err != nil {
 		logger.Errorln(err)
 
-		return 0, fmt.Errorf(myerrors.ErrTemplate, ErrCookieNotPresented)
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrCookieNotPresented)
 	}
 
 	rawJwt := cookie.Value
 
-	userPayload, err := jwt.NewUserJwtPayload(rawJwt, jwt.Secret)
+	jwtKeys, err := jwt.Get()
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	userPayload, err := jwt.NewUserJwtPayload(rawJwt, jwtKeys)
 	if err != nil {
-		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	isActive, err := a.sessionChecker.IsSessionActive(r.Context(), userPayload.SessionID, userPayload.UserID)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	if !isActive {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrSessionRevoked)
 	}
 
-	return userPayload.UserID, nil
+	return userPayload, nil
 }
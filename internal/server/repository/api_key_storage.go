@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/SanExpett/film-library-backend/pkg/models"
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+var ErrAPIKeyInvalid = myerrors.New(myerrors.KindUnauthorized, "api_key_invalid", "Api ключ недействителен")
+
+func ScopesToPermissions(scopes []string) []models.Permission {
+	permissions := make([]models.Permission, 0, len(scopes))
+
+	for _, scope := range scopes {
+		permissions = append(permissions, models.Permission(scope))
+	}
+
+	return permissions
+}
+
+type APIKeyStorage struct {
+	pool   *pgxpool.Pool
+	logger *zap.SugaredLogger
+}
+
+func NewAPIKeyStorage(pool *pgxpool.Pool) (*APIKeyStorage, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return &APIKeyStorage{
+		pool:   pool,
+		logger: logger,
+	}, nil
+}
+
+// UseAPIKey returns active key by its hash and marks it as used. Key of suspended user is not active.
+func (a *APIKeyStorage) UseAPIKey(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	SQLUseAPIKey := `UPDATE public."api_key" k SET last_used_at=NOW()
+		FROM public."user" u
+		WHERE k.key_hash=$1 AND k.revoked_at IS NULL AND (k.expires_at IS NULL OR k.expires_at > NOW())
+			AND u.id = k.user_id AND u.suspended_at IS NULL
+		RETURNING k.id, k.user_id, k.name, k.prefix, k.scopes, k.created_at, k.expires_at, k.last_used_at`
+
+	apiKey := new(models.APIKey)
+
+	err := pgx.BeginFunc(ctx, a.pool, func(tx pgx.Tx) error {
+		var scopes []string
+
+		err := tx.QueryRow(ctx, SQLUseAPIKey, keyHash).Scan(&apiKey.ID, &apiKey.UserID, &apiKey.Name,
+			&apiKey.Prefix, &scopes, &apiKey.CreatedAt, &apiKey.ExpiresAt, &apiKey.LastUsedAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrAPIKeyInvalid
+		}
+
+		if err != nil {
+			a.logger.Errorln(err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		apiKey.Scopes = ScopesToPermissions(scopes)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return apiKey, nil
+}
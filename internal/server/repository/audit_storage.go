@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/SanExpett/film-library-backend/pkg/models"
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// InsertAuditEntry writes entry in transaction of caller, so entry is saved only together with audited change.
+func InsertAuditEntry(ctx context.Context, tx pgx.Tx, entry *models.AuditEntry) error {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	SQLInsertAuditEntry := `INSERT INTO public."audit_log" (actor_id, action, target, ip, details)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	_, err = tx.Exec(ctx, SQLInsertAuditEntry, entry.ActorID, entry.Action, entry.Target, entry.IP, entry.Details)
+	if err != nil {
+		logger.Errorf("in InsertAuditEntry: entry=%+v err=%+v", entry, err)
+
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+type AuditStorage struct {
+	pool   *pgxpool.Pool
+	logger *zap.SugaredLogger
+}
+
+func NewAuditStorage(pool *pgxpool.Pool) (*AuditStorage, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return &AuditStorage{
+		pool:   pool,
+		logger: logger,
+	}, nil
+}
+
+func (a *AuditStorage) AddAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	err := pgx.BeginFunc(ctx, a.pool, func(tx pgx.Tx) error {
+		return InsertAuditEntry(ctx, tx, entry)
+	})
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
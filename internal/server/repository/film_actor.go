@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/SanExpett/film-library-backend/pkg/models"
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// CodeUniqueViolation is code of postgres error when unique constraint is violated
+const CodeUniqueViolation = "23505"
+
+var (
+	ErrFilmNotExist       = myerrors.New(myerrors.KindNotFound, "film_not_exist", "Такого фильма не существует")
+	ErrActorNotExist      = myerrors.New(myerrors.KindNotFound, "actor_not_exist", "Такого актера не существует")
+	ErrActorAlreadyInFilm = myerrors.New(myerrors.KindConflict, "actor_already_in_film",
+		"Этот актер уже добавлен в состав фильма")
+	ErrActorNotInFilm = myerrors.New(myerrors.KindNotFound, "actor_not_in_film",
+		"Этого актера нет в составе фильма")
+)
+
+// IsUniqueViolation reports whether err is violation of unique constraint, for example when the same row
+// was inserted by concurrent request between check and insert.
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+
+	return errors.As(err, &pgErr) && pgErr.Code == CodeUniqueViolation
+}
+
+func SelectIsRowExists(ctx context.Context, tx pgx.Tx, SQLIsRowExists string, args ...any) (bool, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return false, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	var isExists bool
+
+	isExistsRow := tx.QueryRow(ctx, SQLIsRowExists, args...)
+	if err := isExistsRow.Scan(&isExists); err != nil {
+		logger.Errorln(err)
+
+		return false, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return isExists, nil
+}
+
+func SelectIsFilmExists(ctx context.Context, tx pgx.Tx, filmID uint64) (bool, error) {
+	SQLIsFilmExists := `SELECT EXISTS(SELECT 1 FROM public."film" WHERE id=$1)`
+
+	return SelectIsRowExists(ctx, tx, SQLIsFilmExists, filmID)
+}
+
+func SelectIsActorExists(ctx context.Context, tx pgx.Tx, actorID uint64) (bool, error) {
+	SQLIsActorExists := `SELECT EXISTS(SELECT 1 FROM public."actor" WHERE id=$1)`
+
+	return SelectIsRowExists(ctx, tx, SQLIsActorExists, actorID)
+}
+
+func SelectIsActorInFilm(ctx context.Context, tx pgx.Tx, filmID uint64, actorID uint64) (bool, error) {
+	SQLIsActorInFilm := `SELECT EXISTS(SELECT 1 FROM public."film_actor" WHERE film_id=$1 AND actor_id=$2)`
+
+	return SelectIsRowExists(ctx, tx, SQLIsActorInFilm, filmID, actorID)
+}
+
+// InsertFilmActor checks that film and actor exist and are not linked yet, then links them.
+// If billing order is not set, actor is placed at the end of the cast.
+func InsertFilmActor(ctx context.Context, tx pgx.Tx, preFilmActor *models.FilmActorWithoutID) (uint64, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	isFilmExists, err := SelectIsFilmExists(ctx, tx, preFilmActor.FilmID)
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	if !isFilmExists {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, ErrFilmNotExist)
+	}
+
+	isActorExists, err := SelectIsActorExists(ctx, tx, preFilmActor.ActorID)
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	if !isActorExists {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, ErrActorNotExist)
+	}
+
+	isActorInFilm, err := SelectIsActorInFilm(ctx, tx, preFilmActor.FilmID, preFilmActor.ActorID)
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	if isActorInFilm {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, ErrActorAlreadyInFilm)
+	}
+
+	SQLInsertFilmActor := `INSERT INTO public."film_actor" (film_id, actor_id, character_name, billing_order)
+		VALUES ($1, $2, NULLIF($3, ''), COALESCE($4,
+			(SELECT COALESCE(MAX(billing_order), 0) + 1 FROM public."film_actor" WHERE film_id=$1)))
+		RETURNING id;`
+
+	var id uint64
+
+	err = tx.QueryRow(ctx, SQLInsertFilmActor, preFilmActor.FilmID, preFilmActor.ActorID,
+		preFilmActor.CharacterName, preFilmActor.BillingOrder).Scan(&id)
+	if IsUniqueViolation(err) {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, ErrActorAlreadyInFilm)
+	}
+
+	if err != nil {
+		logger.Errorf("in InsertFilmActor: preFilmActor=%+v err=%+v", preFilmActor, err)
+
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return id, nil
+}
+
+func InsertFilmActorsList(ctx context.Context, tx pgx.Tx, preFilmActors []*models.FilmActorWithoutID,
+) ([]uint64, error) {
+	slFilmActorIDs := make([]uint64, 0, len(preFilmActors))
+
+	for _, preFilmActor := range preFilmActors {
+		id, err := InsertFilmActor(ctx, tx, preFilmActor)
+		if err != nil {
+			return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		slFilmActorIDs = append(slFilmActorIDs, id)
+	}
+
+	return slFilmActorIDs, nil
+}
+
+func DeleteFilmActor(ctx context.Context, tx pgx.Tx, filmID uint64, actorID uint64) error {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	SQLDeleteFilmActor := `DELETE FROM public."film_actor" WHERE film_id=$1 AND actor_id=$2`
+
+	result, err := tx.Exec(ctx, SQLDeleteFilmActor, filmID, actorID)
+	if err != nil {
+		logger.Errorln(err)
+
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf(myerrors.ErrTemplate, ErrActorNotInFilm)
+	}
+
+	return nil
+}
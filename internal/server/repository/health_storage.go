@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const migrationUpSuffix = ".up.sql"
+
+var (
+	ErrNoMigrations        = myerrors.NewError("Миграции не применены")
+	ErrNoMigrationsInDir   = myerrors.NewError("В директории нет миграций")
+	ErrMigrationNameFormat = myerrors.NewError("Имя миграции должно начинаться с версии: <версия>_<имя>.up.sql")
+)
+
+// GetLatestMigrationVersion returns version of the newest migration in dir, files are named
+// <version>_<name>.up.sql as golang-migrate creates them.
+func GetLatestMigrationVersion(dir string) (uint64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	var latestVersion uint64
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), migrationUpSuffix) {
+			continue
+		}
+
+		rawVersion, _, _ := strings.Cut(entry.Name(), "_")
+
+		version, err := strconv.ParseUint(rawVersion, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %s", ErrMigrationNameFormat, entry.Name())
+		}
+
+		latestVersion = max(latestVersion, version)
+	}
+
+	if latestVersion == 0 {
+		return 0, fmt.Errorf("%w: %s", ErrNoMigrationsInDir, dir)
+	}
+
+	return latestVersion, nil
+}
+
+type HealthStorage struct {
+	pool   *pgxpool.Pool
+	logger *zap.SugaredLogger
+}
+
+func NewHealthStorage(pool *pgxpool.Pool) (*HealthStorage, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return &HealthStorage{
+		pool:   pool,
+		logger: logger,
+	}, nil
+}
+
+func (h *HealthStorage) Ping(ctx context.Context) error {
+	err := h.pool.Ping(ctx)
+	if err != nil {
+		h.logger.Errorln(err)
+
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+// GetMigrationVersion returns version of applied migrations from schema_migrations of golang-migrate,
+// dirty is true if the last migration failed.
+func (h *HealthStorage) GetMigrationVersion(ctx context.Context) (version uint64, dirty bool, err error) {
+	SQLSelectMigrationVersion := `SELECT version, dirty FROM public."schema_migrations" LIMIT 1`
+
+	err = pgx.BeginFunc(ctx, h.pool, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, SQLSelectMigrationVersion).Scan(&version, &dirty)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNoMigrations
+		}
+
+		if err != nil {
+			h.logger.Errorln(err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, false, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return version, dirty, nil
+}
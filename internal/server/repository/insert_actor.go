@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/SanExpett/film-library-backend/pkg/models"
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"github.com/jackc/pgx/v5"
+)
+
+var NameSeqActor = pgx.Identifier{"public", "actor_id_seq"} //nolint:gochecknoglobals
+
+func InsertActor(ctx context.Context, tx pgx.Tx, preActor *models.ActorWithoutID, userID uint64) (uint64, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	SQLCreateActor := `INSERT INTO public."actor" (name, birthday, gender, author_id) VALUES ($1, $2, $3, $4);`
+
+	_, err = tx.Exec(ctx, SQLCreateActor,
+		preActor.Name, preActor.Birthday, preActor.Gender, userID)
+	if err != nil {
+		logger.Errorf("in InsertActor: preActor=%+v err=%+v", preActor, err)
+
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	id, err := GetLastValSeq(ctx, tx, NameSeqActor)
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/SanExpett/film-library-backend/pkg/models"
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"github.com/SanExpett/film-library-backend/pkg/principal"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+func SelectHasPermissionByUserID(ctx context.Context, tx pgx.Tx,
+	userID uint64, permission models.Permission,
+) (bool, error) {
+	SQLHasPermissionByUserID := `SELECT EXISTS(
+		SELECT 1
+		FROM public."user_role" ur
+		JOIN public."user" u ON u.id = ur.user_id AND u.suspended_at IS NULL
+		JOIN public."role_permission" rp ON rp.role_id = ur.role_id
+		JOIN public."permission" p ON p.id = rp.permission_id
+		WHERE ur.user_id = $1 AND p.name = $2)`
+
+	return SelectIsRowExists(ctx, tx, SQLHasPermissionByUserID, userID, permission)
+}
+
+var ErrPrincipalNotFound = myerrors.New(myerrors.KindUnauthorized, "principal_not_found",
+	"Пользователь не найден или заблокирован")
+
+type PermissionStorage struct {
+	pool   *pgxpool.Pool
+	logger *zap.SugaredLogger
+}
+
+func NewPermissionStorage(pool *pgxpool.Pool) (*PermissionStorage, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return &PermissionStorage{
+		pool:   pool,
+		logger: logger,
+	}, nil
+}
+
+func (p *PermissionStorage) HasPermission(ctx context.Context, userID uint64, permission models.Permission,
+) (bool, error) {
+	var hasPermission bool
+
+	err := pgx.BeginFunc(ctx, p.pool, func(tx pgx.Tx) error {
+		hasPermissionInner, err := SelectHasPermissionByUserID(ctx, tx, userID, permission)
+		if err != nil {
+			return err
+		}
+
+		hasPermission = hasPermissionInner
+
+		return nil
+	})
+	if err != nil {
+		p.logger.Errorln(err)
+
+		return false, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return hasPermission, nil
+}
+
+// GetPrincipal returns email, locale and roles of active user, suspended user is not found.
+func (p *PermissionStorage) GetPrincipal(ctx context.Context, userID uint64) (*principal.Principal, error) {
+	SQLSelectPrincipal := `SELECT u.email, u.locale, COALESCE(ARRAY_AGG(r.name) FILTER (WHERE r.name IS NOT NULL), '{}')
+		FROM public."user" u
+		LEFT JOIN public."user_role" ur ON ur.user_id = u.id
+		LEFT JOIN public."role" r ON r.id = ur.role_id
+		WHERE u.id = $1 AND u.suspended_at IS NULL
+		GROUP BY u.id`
+
+	userPrincipal := &principal.Principal{UserID: userID} //nolint:exhaustruct
+
+	err := pgx.BeginFunc(ctx, p.pool, func(tx pgx.Tx) error {
+		var roles []string
+
+		err := tx.QueryRow(ctx, SQLSelectPrincipal, userID).Scan(&userPrincipal.Email, &userPrincipal.Locale, &roles)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrPrincipalNotFound
+		}
+
+		if err != nil {
+			p.logger.Errorln(err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		for _, role := range roles {
+			userPrincipal.Roles = append(userPrincipal.Roles, models.Role(role))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return userPrincipal, nil
+}
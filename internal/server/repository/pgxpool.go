@@ -4,11 +4,19 @@ import (
 	"context"
 	"fmt"
 	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+	"github.com/SanExpett/film-library-backend/pkg/tracing"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 func NewPgxPool(ctx context.Context, urlDataBase string) (*pgxpool.Pool, error) {
-	pool, err := pgxpool.New(ctx, urlDataBase)
+	config, err := pgxpool.ParseConfig(urlDataBase)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	config.ConnConfig.Tracer = tracing.NewPgxTracer()
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
 		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
 	}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+func SelectIsSessionActive(ctx context.Context, tx pgx.Tx, sessionID uint64, userID uint64) (bool, error) {
+	SQLIsSessionActive := `SELECT EXISTS(SELECT 1 FROM public."session"
+		WHERE id=$1 AND user_id=$2 AND revoked_at IS NULL AND expires_at > NOW())`
+
+	return SelectIsRowExists(ctx, tx, SQLIsSessionActive, sessionID, userID)
+}
+
+// RevokeUserSessions revokes all active sessions of user, so access tokens of this user stop working at once.
+func RevokeUserSessions(ctx context.Context, tx pgx.Tx, userID uint64) error {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	SQLRevokeUserSessions := `UPDATE public."session" SET revoked_at=NOW() WHERE user_id=$1 AND revoked_at IS NULL`
+
+	_, err = tx.Exec(ctx, SQLRevokeUserSessions, userID)
+	if err != nil {
+		logger.Errorln(err)
+
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+type SessionStorage struct {
+	pool   *pgxpool.Pool
+	logger *zap.SugaredLogger
+}
+
+func NewSessionStorage(pool *pgxpool.Pool) (*SessionStorage, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return &SessionStorage{
+		pool:   pool,
+		logger: logger,
+	}, nil
+}
+
+func (s *SessionStorage) IsSessionActive(ctx context.Context, sessionID uint64, userID uint64) (bool, error) {
+	var isActive bool
+
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		isActiveInner, err := SelectIsSessionActive(ctx, tx, sessionID, userID)
+		if err != nil {
+			return err
+		}
+
+		isActive = isActiveInner
+
+		return nil
+	})
+	if err != nil {
+		s.logger.Errorln(err)
+
+		return false, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return isActive, nil
+}
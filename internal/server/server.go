@@ -6,47 +6,223 @@ import (
 	actorusecases "github.com/SanExpett/film-library-backend/internal/actor/usecases"
 	filmrepo "github.com/SanExpett/film-library-backend/internal/film/repository"
 	filmusecases "github.com/SanExpett/film-library-backend/internal/film/usecases"
+	"github.com/SanExpett/film-library-backend/internal/server/delivery"
 	"github.com/SanExpett/film-library-backend/internal/server/delivery/mux"
 	"github.com/SanExpett/film-library-backend/internal/server/repository"
+	serverusecases "github.com/SanExpett/film-library-backend/internal/server/usecases"
 	userrepo "github.com/SanExpett/film-library-backend/internal/user/repository"
 	userusecases "github.com/SanExpett/film-library-backend/internal/user/usecases"
 	"github.com/SanExpett/film-library-backend/pkg/config"
+	"github.com/SanExpett/film-library-backend/pkg/jwt"
+	"github.com/SanExpett/film-library-backend/pkg/mailer"
+	"github.com/SanExpett/film-library-backend/pkg/metrics"
 	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"github.com/SanExpett/film-library-backend/pkg/oidc"
+	"github.com/SanExpett/film-library-backend/pkg/tracing"
+	"github.com/SanExpett/film-library-backend/pkg/utils"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
 	"net/http"
+	"path/filepath"
 	"strings"
 	"time"
 )
 
 const (
 	basicTimeout = 10 * time.Second
+	// writeTimeoutMargin is left after the longest request timeout, so handler has time to send 504
+	writeTimeoutMargin = 5 * time.Second
+	// migrationsDir is relative to PathToRoot, the newest migration there is expected to be applied
+	migrationsDir = "db/migrations"
 )
 
+// writeTimeout is long enough for the longest request timeout, otherwise connection would be closed
+// before context of request is canceled and timeout of route would have no effect.
+func writeTimeout(requestTimeout time.Duration, routeTimeouts map[string]time.Duration) time.Duration {
+	longest := requestTimeout
+	for _, timeout := range routeTimeouts {
+		longest = max(longest, timeout)
+	}
+
+	return max(basicTimeout, longest+writeTimeoutMargin)
+}
+
+func newLoginAttemptStore(pool *pgxpool.Pool, kind string) (userusecases.ILoginAttemptStore, error) {
+	if kind == config.LoginAttemptStoreMemory {
+		return userrepo.NewMemoryLoginAttemptStorage(), nil
+	}
+
+	return userrepo.NewLoginAttemptStorage(pool) //nolint:wrapcheck
+}
+
+func newOIDCProviders(providersConfig []config.OIDCProvider) (map[string]userusecases.IOIDCProvider, error) {
+	providers := make(map[string]userusecases.IOIDCProvider, len(providersConfig))
+
+	for _, providerConfig := range providersConfig {
+		provider, err := oidc.NewProvider(&oidc.Config{
+			Name:         providerConfig.Name,
+			Issuer:       providerConfig.Issuer,
+			ClientID:     providerConfig.ClientID,
+			ClientSecret: providerConfig.ClientSecret,
+			RedirectURL:  providerConfig.RedirectURL,
+			Scopes:       providerConfig.Scopes,
+		})
+		if err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+
+		providers[providerConfig.Name] = provider
+	}
+
+	return providers, nil
+}
+
 type Server struct {
 	httpServer *http.Server
+	readiness  *delivery.Readiness
 }
 
-func (s *Server) Run(config *config.Config) error {
-	baseCtx := context.Background()
+// Run serves until ctx is canceled, then drains in-flight requests (see drain). The pool is closed
+// and the logger is flushed before Run returns.
+func (s *Server) Run(ctx context.Context, config *config.Config) error {
+	logger, err := my_logger.New(strings.Split(config.OutputLogPath, " "),
+		strings.Split(config.ErrorOutputLogPath, " "))
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	defer logger.Sync()
 
-	pool, err := repository.NewPgxPool(baseCtx, config.URLDataBase)
+	for _, pair := range config.SkippedRouteTimeouts {
+		logger.Warnf("skipped malformed pair of REQUEST_ROUTE_TIMEOUTS: %q, expected route=duration", pair)
+	}
+
+	shutdownTracing, err := tracing.Init(ctx, &tracing.Config{
+		Exporter:     config.TracingExporter,
+		OTLPEndpoint: config.TracingOTLPEndpoint,
+		OTLPInsecure: config.TracingOTLPInsecure,
+		ServiceName:  config.TracingServiceName,
+		SampleRatio:  config.TracingSampleRatio,
+	})
 	if err != nil {
 		return err //nolint:wrapcheck
 	}
 
-	logger, err := my_logger.New(strings.Split(config.OutputLogPath, " "),
-		strings.Split(config.ErrorOutputLogPath, " "))
+	// spans are flushed after in-flight requests are drained, ctx of Run is already canceled then
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			logger.Errorf("Shutdown: spans are not flushed: %+v", err)
+		}
+	}()
+
+	pool, err := repository.NewPgxPool(ctx, config.URLDataBase)
 	if err != nil {
 		return err //nolint:wrapcheck
 	}
 
-	defer logger.Sync()
+	defer pool.Close()
+
+	err = metrics.Register(metrics.NewPoolCollector(pool))
+	if err != nil {
+		return err
+	}
+
+	err = utils.SetPassHashParams(utils.PassHashParams{
+		Time:    config.PassHashTime,
+		Memory:  config.PassHashMemory,
+		Threads: config.PassHashThreads,
+	})
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	_, err = jwt.New(config.JwtKeys, &jwt.Options{
+		Issuer:    config.JwtIssuer,
+		Audience:  config.JwtAudience,
+		Leeway:    config.JwtLeeway,
+		TokenLife: config.JwtTokenLife,
+	})
+	if err != nil {
+		return err
+	}
+
+	permissionStorage, err := repository.NewPermissionStorage(pool)
+	if err != nil {
+		return err
+	}
+
+	policy, err := serverusecases.NewPolicy(permissionStorage)
+	if err != nil {
+		return err
+	}
+
+	sessionStorage, err := repository.NewSessionStorage(pool)
+	if err != nil {
+		return err
+	}
+
+	apiKeyStorage, err := repository.NewAPIKeyStorage(pool)
+	if err != nil {
+		return err
+	}
+
+	authenticator, err := delivery.NewAuthenticator(permissionStorage, sessionStorage, apiKeyStorage)
+	if err != nil {
+		return err
+	}
 
 	userStorage, err := userrepo.NewUserStorage(pool)
 	if err != nil {
 		return err
 	}
 
-	userService, err := userusecases.NewUserService(userStorage)
+	auditStorage, err := repository.NewAuditStorage(pool)
+	if err != nil {
+		return err
+	}
+
+	loginAttemptStore, err := newLoginAttemptStore(pool, config.LoginAttemptStore)
+	if err != nil {
+		return err
+	}
+
+	loginLimiter, err := userusecases.NewLoginLimiter(loginAttemptStore, auditStorage, &userusecases.LoginLimits{
+		MaxAttempts:      config.LoginMaxAttempts,
+		MaxAttemptsPerIP: config.LoginMaxAttemptsPerIP,
+		LockBase:         config.LoginLockBase,
+		LockMax:          config.LoginLockMax,
+		AttemptsWindow:   config.LoginAttemptsWindow,
+	})
+	if err != nil {
+		return err
+	}
+
+	userMailer, err := mailer.New(&mailer.Config{
+		Kind:         config.Mailer,
+		From:         config.MailFrom,
+		SMTPHost:     config.SMTPHost,
+		SMTPPort:     config.SMTPPort,
+		SMTPUser:     config.SMTPUser,
+		SMTPPassword: config.SMTPPassword,
+		Dir:          config.MailDir,
+	})
+	if err != nil {
+		return err
+	}
+
+	oidcProviders, err := newOIDCProviders(config.OIDCProviders)
+	if err != nil {
+		return err
+	}
+
+	userService, err := userusecases.NewUserService(userStorage, policy, loginLimiter, userMailer,
+		&userusecases.UserServiceOptions{
+			RefreshTokenLife:           config.RefreshTokenLife,
+			PasswordResetTokenLife:     config.PasswordResetTokenLife,
+			EmailVerificationTokenLife: config.EmailVerificationTokenLife,
+			AppURL:                     config.AppURL,
+			OIDCProviders:              oidcProviders,
+		})
 	if err != nil {
 		return err
 	}
@@ -56,7 +232,7 @@ func (s *Server) Run(config *config.Config) error {
 		return err
 	}
 
-	actorService, err := actorusecases.NewActorService(actorStorage)
+	actorService, err := actorusecases.NewActorService(actorStorage, policy)
 	if err != nil {
 		return err
 	}
@@ -66,13 +242,31 @@ func (s *Server) Run(config *config.Config) error {
 		return err
 	}
 
-	filmService, err := filmusecases.NewFilmService(filmStorage)
+	filmService, err := filmusecases.NewFilmService(filmStorage, policy)
 	if err != nil {
 		return err
 	}
 
-	handler, err := mux.NewMux(baseCtx, mux.NewConfigMux(config.AllowOrigin,
-		config.Schema, config.PortServer), userService, actorService, filmService, logger)
+	cookieConfig, err := delivery.NewCookieConfig(config.CookieDomain, config.CookieSecure, config.CookieSameSite)
+	if err != nil {
+		return err
+	}
+
+	migrationVersion, err := repository.GetLatestMigrationVersion(filepath.Join(config.PathToRoot, migrationsDir))
+	if err != nil {
+		return err
+	}
+
+	healthStorage, err := repository.NewHealthStorage(pool)
+	if err != nil {
+		return err
+	}
+
+	s.readiness = delivery.NewReadiness()
+
+	handler, err := mux.NewMux(mux.NewConfigMux(config.AllowOrigin, config.Schema, config.PortServer,
+		config.AllowLegacySignIn, cookieConfig, config.AppURL, config.RequestTimeout, config.RouteTimeouts,
+		migrationVersion), s.readiness, healthStorage, authenticator, userService, actorService, filmService, logger)
 	if err != nil {
 		return err
 	}
@@ -82,12 +276,47 @@ func (s *Server) Run(config *config.Config) error {
 		Handler:        handler,
 		MaxHeaderBytes: http.DefaultMaxHeaderBytes,
 		ReadTimeout:    basicTimeout,
-		WriteTimeout:   basicTimeout,
+		WriteTimeout:   writeTimeout(config.RequestTimeout, config.RouteTimeouts),
 	}
 
+	serveErr := make(chan error, 1)
+
+	go func() {
+		serveErr <- s.httpServer.ListenAndServe()
+	}()
+
+	s.readiness.SetReady(true)
 	logger.Infof("Start server:%s", config.PortServer)
 
-	return s.httpServer.ListenAndServe() //nolint:wrapcheck
+	select {
+	case err = <-serveErr:
+		return err //nolint:wrapcheck
+	case <-ctx.Done():
+	}
+
+	return s.drain(config.ShutdownDelay, config.ShutdownGracePeriod, logger)
+}
+
+// drain reports not ready and keeps serving for delay, so orchestrator stops sending new requests,
+// then waits up to gracePeriod for in-flight requests to finish.
+func (s *Server) drain(delay time.Duration, gracePeriod time.Duration, logger *zap.SugaredLogger) error {
+	s.readiness.SetReady(false)
+	logger.Infof("Shutdown: not ready, draining in %s", delay)
+	time.Sleep(delay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), gracePeriod)
+	defer cancel()
+
+	err := s.Shutdown(ctx)
+	if err != nil {
+		logger.Errorf("Shutdown: in-flight requests are not finished in %s: %+v", gracePeriod, err)
+
+		return err
+	}
+
+	logger.Infof("Shutdown: all requests are finished")
+
+	return nil
 }
 
 func (s *Server) Shutdown(ctx context.Context) error {
@@ -0,0 +1,64 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"github.com/SanExpett/film-library-backend/internal/server/repository"
+	"github.com/SanExpett/film-library-backend/pkg/models"
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"github.com/SanExpett/film-library-backend/pkg/principal"
+	"go.uber.org/zap"
+)
+
+var _ IPermissionStorage = (*repository.PermissionStorage)(nil)
+
+var (
+	ErrForbidden = myerrors.New(myerrors.KindForbidden, "forbidden",
+		"Недостаточно прав для выполнения этого действия")
+	ErrAPIKeyScopeDenied = myerrors.New(myerrors.KindForbidden, "api_key_scope_denied",
+		"Api ключ не позволяет выполнить это действие")
+)
+
+type IPermissionStorage interface {
+	HasPermission(ctx context.Context, userID uint64, permission models.Permission) (bool, error)
+}
+
+// Policy checks permissions of user roles. It is shared by film, actor and user services.
+type Policy struct {
+	storage IPermissionStorage
+	logger  *zap.SugaredLogger
+}
+
+func NewPolicy(permissionStorage IPermissionStorage) (*Policy, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return &Policy{storage: permissionStorage, logger: logger}, nil
+}
+
+// Check returns ErrForbidden if roles of user don't give permission. Request made by api key
+// is also limited by scopes of key.
+func (p *Policy) Check(ctx context.Context, userID uint64, permission models.Permission) error {
+	hasPermission, err := p.storage.HasPermission(ctx, userID, permission)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	if !hasPermission {
+		p.logger.Errorf("user id=%d has no permission %s", userID, permission)
+
+		return fmt.Errorf(myerrors.ErrTemplate, ErrForbidden)
+	}
+
+	if requestPrincipal, ok := principal.FromContext(ctx); ok && requestPrincipal.IsAPIKey() &&
+		!requestPrincipal.APIKey.HasScope(permission) {
+		p.logger.Errorf("api key id=%d of user id=%d has no scope %s", requestPrincipal.APIKey.ID, userID, permission)
+
+		return fmt.Errorf(myerrors.ErrTemplate, ErrAPIKeyScopeDenied)
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package delivery
+
+import (
+	"github.com/SanExpett/film-library-backend/internal/server/delivery"
+	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"github.com/SanExpett/film-library-backend/pkg/utils"
+	"net/http"
+)
+
+// Api keys are managed only from signed in session: GetSessionPrincipal rejects api keys,
+// so leaked key can't be used to issue new keys.
+
+// APIKeysHandler serves /api_keys, where GET returns keys of user and POST creates new key.
+func (u *UserHandler) APIKeysHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		u.GetAPIKeysHandler(w, r)
+	case http.MethodPost:
+		u.CreateAPIKeyHandler(w, r)
+	default:
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+	}
+}
+
+// CreateAPIKeyHandler godoc
+//
+//	@Summary    create api key
+//	@Description  create api key for machine client. Key is returned only once, it is sent in header
+//	@Description  Authorization: ApiKey <key>. Scopes must be permissions user has, expires_at is optional
+//	@Tags api_key
+//	@Accept      json
+//	@Produce    json
+//	@Param      preAPIKey  body models.APIKeyWithoutID true  "api key data"
+//	@Success    200  {object} CreatedAPIKeyResponse
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
+//	@Router      /api_keys [post]
+func (u *UserHandler) CreateAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	userPrincipal, err := delivery.GetSessionPrincipal(r)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	createdAPIKey, err := u.service.CreateAPIKey(ctx, r.Body, userPrincipal.UserID)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, u.logger,
+		NewCreatedAPIKeyResponse(delivery.StatusResponseSuccessful, createdAPIKey))
+	my_logger.FromCtx(r.Context()).Infof("in CreateAPIKeyHandler: created api key id=%d of user id=%d",
+		createdAPIKey.APIKey.ID, userPrincipal.UserID)
+}
+
+// GetAPIKeysHandler godoc
+//
+//	@Summary    get my api keys
+//	@Description  get not revoked api keys of user with their scopes, expiry and last use
+//	@Tags api_key
+//	@Produce    json
+//	@Success    200  {object} APIKeyListResponse
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Router      /api_keys [get]
+func (u *UserHandler) GetAPIKeysHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	userPrincipal, err := delivery.GetSessionPrincipal(r)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	apiKeys, err := u.service.GetAPIKeys(ctx, userPrincipal.UserID)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, u.logger, NewAPIKeyListResponse(delivery.StatusResponseSuccessful, apiKeys))
+	my_logger.FromCtx(r.Context()).Infof("in GetAPIKeysHandler: get api keys of user id=%d", userPrincipal.UserID)
+}
+
+// RevokeAPIKeyHandler godoc
+//
+//	@Summary    revoke api key
+//	@Description  revoke api key of user, requests with it are rejected at once
+//	@Tags api_key
+//	@Produce    json
+//	@Param      id  query uint64 true  "api key id"
+//	@Success    200  {object} delivery.Response
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
+//	@Router      /api_keys/revoke [post]
+func (u *UserHandler) RevokeAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	userPrincipal, err := delivery.GetSessionPrincipal(r)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	apiKeyID, err := utils.ParseUint64FromRequest(r, "id")
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	err = u.service.RevokeAPIKey(ctx, apiKeyID, userPrincipal.UserID)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, u.logger,
+		delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulRevokeAPIKey))
+	my_logger.FromCtx(r.Context()).Infof("in RevokeAPIKeyHandler: revoked api key id=%d of user id=%d",
+		apiKeyID, userPrincipal.UserID)
+}
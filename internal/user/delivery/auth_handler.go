@@ -6,7 +6,6 @@ import (
 	"github.com/SanExpett/film-library-backend/pkg/my_logger"
 	"io"
 	"net/http"
-	"time"
 
 	"github.com/SanExpett/film-library-backend/internal/server/delivery"
 	userusecases "github.com/SanExpett/film-library-backend/internal/user/usecases"
@@ -15,38 +14,88 @@ import (
 )
 
 const (
-	timeTokenLife = 24 * time.Hour
-
-	StatusUnauthorized = 401
-
 	ResponseSuccessfulSignUp = "Successful sign up"
 	ResponseSuccessfulSignIn = "Successful sign in"
 	ResponseSuccessfulLogOut = "Successful log out"
-
-	ErrUnauthorized = "Вы не авторизованны"
 )
 
 var _ IUserService = (*userusecases.UserService)(nil)
 
 type IUserService interface {
 	AddUser(ctx context.Context, r io.Reader) (*models.User, error)
-	GetUser(ctx context.Context, email string, password string) (*models.UserWithoutPassword, error)
+	SignIn(ctx context.Context, r io.Reader, ip string) (*models.UserWithoutPassword, error)
+	GetUser(ctx context.Context, email string, password string, ip string) (*models.UserWithoutPassword, error)
+	GrantRole(ctx context.Context, r io.Reader, userID uint64) error
+	RevokeRole(ctx context.Context, targetUserID uint64, role string, userID uint64) error
+	GetUserRoles(ctx context.Context, targetUserID uint64, userID uint64) ([]models.Role, error)
+	GetRolesList(ctx context.Context) ([]*models.RoleWithPermissions, error)
+	GetUsersList(ctx context.Context, limit uint64, offset uint64, userID uint64) ([]*models.UserWithRoles, error)
+	SearchUsersByEmail(ctx context.Context, searchedEmail string, limit uint64, offset uint64, userID uint64,
+	) ([]*models.UserWithRoles, error)
+	SuspendUser(ctx context.Context, targetUserID uint64, userID uint64) error
+	UnsuspendUser(ctx context.Context, targetUserID uint64, userID uint64) error
+	DeleteUser(ctx context.Context, targetUserID uint64, reassignToID uint64, userID uint64) error
+	UnlockUser(ctx context.Context, targetUserID uint64, userID uint64) error
+	RequestPasswordReset(ctx context.Context, r io.Reader) error
+	ConfirmPasswordReset(ctx context.Context, r io.Reader) error
+	RequestEmailVerification(ctx context.Context, userID uint64) error
+	ConfirmEmailVerification(ctx context.Context, r io.Reader) error
+	GetProfile(ctx context.Context, userID uint64) (*models.Profile, error)
+	UpdateProfile(ctx context.Context, r io.Reader, isPartialUpdate bool, userID uint64) error
+	ChangePassword(ctx context.Context, r io.Reader, userID uint64, sessionID uint64) error
+	RequestEmailChange(ctx context.Context, r io.Reader, userID uint64) error
+	ConfirmEmailChange(ctx context.Context, r io.Reader) error
+	DeleteAccount(ctx context.Context, r io.Reader, userID uint64) error
+	CreateSession(ctx context.Context, user *models.UserWithoutPassword, userAgent string, ip string,
+	) (*models.AuthSession, error)
+	RefreshSession(ctx context.Context, refreshToken string, userAgent string, ip string,
+	) (*models.AuthSession, error)
+	LogOut(ctx context.Context, sessionID uint64, userID uint64) error
+	LogOutByRefreshToken(ctx context.Context, refreshToken string) error
+	LogOutAll(ctx context.Context, userID uint64) error
+	GetSessions(ctx context.Context, userID uint64, currentSessionID uint64) ([]*models.Session, error)
+	CreateAPIKey(ctx context.Context, r io.Reader, userID uint64) (*models.CreatedAPIKey, error)
+	GetAPIKeys(ctx context.Context, userID uint64) ([]*models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, apiKeyID uint64, userID uint64) error
+	StartOIDCLogin(ctx context.Context, providerName string) (*models.OIDCLogin, error)
+	FinishOIDCLogin(ctx context.Context, providerName string, code string, state string, login *models.OIDCLogin,
+	) (*models.UserWithoutPassword, error)
 }
 
 type UserHandler struct {
 	service IUserService
-	logger  *zap.SugaredLogger
+	jwtKeys *jwt.KeySet
+	// authenticator reads access token on logout, route of logout is not behind middleware.Auth
+	authenticator *delivery.Authenticator
+	// allowLegacySignIn enables deprecated GET /signin with credentials in query string
+	allowLegacySignIn bool
+	cookieConfig      *delivery.CookieConfig
+	// appURL is address of frontend, user is redirected there after sign in by oidc provider
+	appURL string
+	logger *zap.SugaredLogger
 }
 
-func NewUserHandler(userService IUserService) (*UserHandler, error) {
+func NewUserHandler(userService IUserService, authenticator *delivery.Authenticator, allowLegacySignIn bool,
+	cookieConfig *delivery.CookieConfig, appURL string,
+) (*UserHandler, error) {
 	logger, err := my_logger.Get()
 	if err != nil {
 		return nil, err
 	}
 
+	jwtKeys, err := jwt.Get()
+	if err != nil {
+		return nil, err
+	}
+
 	return &UserHandler{
-		service: userService,
-		logger:  logger,
+		service:           userService,
+		jwtKeys:           jwtKeys,
+		authenticator:     authenticator,
+		allowLegacySignIn: allowLegacySignIn,
+		cookieConfig:      cookieConfig,
+		appURL:            appURL,
+		logger:            logger,
 	}, nil
 }
 
@@ -55,9 +104,7 @@ func NewUserHandler(userService IUserService) (*UserHandler, error) {
 //	@Summary    signup
 //	@Description  signup in app
 //
-//	@Description Error.status can be:
-//	@Description StatusErrBadRequest      = 400
-//	@Description  StatusErrInternalServer  = 500
+//	@Description Errors are sent with http status of error as application/problem+json
 //	@Tags auth
 //
 //	@Accept      json
@@ -66,7 +113,8 @@ func NewUserHandler(userService IUserService) (*UserHandler, error) {
 //	@Success    200  {object} delivery.Response
 //	@Failure    405  {string} string
 //	@Failure    500  {string} string
-//	@Failure    222  {object} delivery.ErrorResponse "Error"
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
 //	@Router      /signup [post]
 func (u *UserHandler) SignUpHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -79,49 +127,79 @@ func (u *UserHandler) SignUpHandler(w http.ResponseWriter, r *http.Request) {
 
 	user, err := u.service.AddUser(ctx, r.Body)
 	if err != nil {
-		delivery.HandleErr(w, u.logger, err)
+		delivery.HandleErr(w, r, u.logger, err)
 
 		return
 	}
 
-	expire := time.Now().Add(timeTokenLife)
-
-	jwtStr, err := jwt.GenerateJwtToken(
-		&jwt.UserJwtPayload{UserID: user.ID, Email: user.Email, Expire: expire.Unix()}, jwt.Secret, u.logger)
+	err = u.startSession(w, r, &models.UserWithoutPassword{ID: user.ID, Email: user.Email}) //nolint:exhaustruct
 	if err != nil {
-		delivery.SendErrResponse(w, u.logger,
-			delivery.NewErrResponse(delivery.StatusErrInternalServer, delivery.ErrInternalServer))
+		delivery.HandleErr(w, r, u.logger, err)
 
 		return
 	}
 
-	cookie := &http.Cookie{ //nolint:exhaustruct
-		Name:    delivery.CookieAuthName,
-		Value:   jwtStr,
-		Expires: expire,
-		Path:    "/",
-	}
-
-	http.SetCookie(w, cookie)
 	delivery.SendOkResponse(w, u.logger, delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulSignUp))
-	u.logger.Infof("in SignUpHandler: added user: %+v", user)
+	my_logger.FromCtx(r.Context()).Infof("in SignUpHandler: added user: %+v", user)
 }
 
 // SignInHandler godoc
 //
 //	@Summary    signin
-//	@Description  signin in app
+//	@Description  signin in app, credentials are passed in json body.
+//	@Description  After several failed attempts account and ip are locked for a while, lock grows with every next failure
 //	@Tags auth
+//	@Accept      json
+//	@Produce    json
+//	@Param      credentials  body models.UserWithoutID true  "user credentials for signin"
+//	@Success    200  {object} delivery.Response
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
+//	@Router      /signin [post]
+func (u *UserHandler) SignInHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet && u.allowLegacySignIn {
+		u.LegacySignInHandler(w, r)
+
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	user, err := u.service.SignIn(ctx, r.Body, clientIP(r))
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	u.finishSignIn(w, r, user)
+}
+
+// LegacySignInHandler godoc
+//
+//	@Summary    signin (deprecated)
+//	@Description  old signin with credentials in query string, they end up in access logs and browser history.
+//	@Description  Works only if ALLOW_LEGACY_SIGNIN is enabled, use POST /signin instead
+//	@Tags auth
+//	@Deprecated
 //	@Produce    json
 //	@Param      email  query string true  "user email for signin"
 //	@Param      password  query string true  "user password for signin"
 //	@Success    200  {object} delivery.Response
 //	@Failure    405  {string} string
 //	@Failure    500  {string} string
-//	@Failure    222  {object} delivery.ErrorResponse "Error"
+//	@Failure    default  {object} delivery.Problem "Error"
 //	@Router      /signin [get]
-func (u *UserHandler) SignInHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
+func (u *UserHandler) LegacySignInHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet || !u.allowLegacySignIn {
 		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
 
 		return
@@ -129,55 +207,46 @@ func (u *UserHandler) SignInHandler(w http.ResponseWriter, r *http.Request) {
 
 	ctx := r.Context()
 
+	my_logger.FromCtx(r.Context()).Warnf("in LegacySignInHandler: deprecated GET /signin is used, user agent: %s",
+		r.UserAgent())
+	w.Header().Set("Deprecation", "true")
+
 	email := r.URL.Query().Get("email")
 	password := r.URL.Query().Get("password")
 
-	user, err := u.service.GetUser(ctx, email, password)
+	user, err := u.service.GetUser(ctx, email, password, clientIP(r))
 	if err != nil {
-		delivery.HandleErr(w, u.logger, err)
+		delivery.HandleErr(w, r, u.logger, err)
 
 		return
 	}
 
-	expire := time.Now().Add(timeTokenLife)
+	u.finishSignIn(w, r, user)
+}
 
-	jwtStr, err := jwt.GenerateJwtToken(&jwt.UserJwtPayload{
-		UserID: user.ID,
-		Email:  user.Email,
-		Expire: expire.Unix(),
-	},
-		jwt.Secret,
-		u.logger,
-	)
+func (u *UserHandler) finishSignIn(w http.ResponseWriter, r *http.Request, user *models.UserWithoutPassword) {
+	err := u.startSession(w, r, user)
 	if err != nil {
-		delivery.SendErrResponse(w, u.logger,
-			delivery.NewErrResponse(delivery.StatusErrInternalServer, delivery.ErrInternalServer))
+		delivery.HandleErr(w, r, u.logger, err)
 
 		return
 	}
 
-	cookie := &http.Cookie{ //nolint:exhaustruct
-		Name:    delivery.CookieAuthName,
-		Value:   jwtStr,
-		Expires: expire,
-		Path:    "/",
-	}
-
-	http.SetCookie(w, cookie)
 	delivery.SendOkResponse(w, u.logger, delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulSignIn))
-	u.logger.Infof("in SignInHandler: signin user: %+v", user)
+	my_logger.FromCtx(r.Context()).Infof("in SignInHandler: signin user: %+v", user)
 }
 
 // LogOutHandler godoc
 //
 //	@Summary    logout
-//	@Description  logout in app
+//	@Description  logout in app, session is revoked on server, so its access and refresh tokens stop working
 //	@Tags auth
 //	@Produce    json
 //	@Success    200  {object} delivery.Response
 //	@Failure    405  {string} string
 //	@Failure    500  {string} string
-//	@Failure    222  {object} delivery.ErrorResponse "Error"
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
 //	@Router      /logout [post]
 func (u *UserHandler) LogOutHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -186,17 +255,33 @@ func (u *UserHandler) LogOutHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cookie, err := r.Cookie(delivery.CookieAuthName)
-	if err != nil {
-		u.logger.Errorln(err)
-		delivery.SendErrResponse(w, u.logger, delivery.NewErrResponse(StatusUnauthorized, ErrUnauthorized))
+	ctx := r.Context()
+
+	// access token can be already expired, then session is found by refresh token
+	userPayload, errPayload := u.authenticator.GetJwtPayloadFromCookie(r)
+	refreshCookie, errRefresh := r.Cookie(delivery.CookieRefreshName)
+
+	var err error
+
+	switch {
+	case errPayload == nil:
+		err = u.service.LogOut(ctx, userPayload.SessionID, userPayload.UserID)
+	case errRefresh == nil:
+		err = u.service.LogOutByRefreshToken(ctx, refreshCookie.Value)
+	default:
+		my_logger.FromCtx(r.Context()).Errorln(errPayload, errRefresh)
+		delivery.HandleErr(w, r, u.logger, delivery.ErrNoPrincipal)
 
 		return
 	}
 
-	cookie.Expires = time.Now()
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
 
-	http.SetCookie(w, cookie)
+	u.clearAuthCookies(w)
 	delivery.SendOkResponse(w, u.logger, delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulLogOut))
-	u.logger.Infof("in LogOutHandler: logout user with cookie: %+v", cookie)
+	my_logger.FromCtx(r.Context()).Infof("in LogOutHandler: logout session")
 }
@@ -0,0 +1,35 @@
+package delivery
+
+import (
+	"github.com/SanExpett/film-library-backend/internal/server/delivery"
+	"net/http"
+)
+
+// CSRFTokenHandler godoc
+//
+//	@Summary    get csrf token
+//	@Description  issues new csrf token and sets it in csrf_token cookie. The token has to be sent
+//	@Description  in X-CSRF-Token header of every POST, PUT, PATCH and DELETE request
+//	@Tags auth
+//	@Produce    json
+//	@Success    200  {object} delivery.CSRFTokenResponse
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Router      /csrf_token [get]
+func (u *UserHandler) CSRFTokenHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	csrfToken, err := delivery.SetNewCSRFToken(w, u.cookieConfig)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, u.logger, delivery.NewCSRFTokenResponse(csrfToken))
+}
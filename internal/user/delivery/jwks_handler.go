@@ -0,0 +1,33 @@
+package delivery
+
+import (
+	"encoding/json"
+	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"net/http"
+)
+
+// JWKSHandler publishes public keys used to sign jwt, so other services can verify tokens of this backend.
+// It is served on /.well-known/jwks.json outside of api base path, so it has no swagger annotations.
+func (u *UserHandler) JWKSHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	rawJSON, err := json.Marshal(u.jwtKeys.JWKS())
+	if err != nil {
+		my_logger.FromCtx(r.Context()).Errorln(err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+
+	_, err = w.Write(rawJSON)
+	if err != nil {
+		my_logger.FromCtx(r.Context()).Errorln(err)
+	}
+}
@@ -0,0 +1,317 @@
+package delivery
+
+import (
+	"github.com/SanExpett/film-library-backend/internal/server/delivery"
+	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"github.com/SanExpett/film-library-backend/pkg/utils"
+	"net/http"
+)
+
+// GetUsersListHandler godoc
+//
+//	@Summary    get users list
+//	@Description  get users with their roles by limit and offset. Needs permission user:manage
+//	@Tags user
+//	@Accept      json
+//	@Produce    json
+//	@Param      limit  query uint64 true  "limit users"
+//	@Param      offset  query uint64 true  "offset of users"
+//	@Success    200  {object} UserWithRolesListResponse
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Router      /user/get_list [get]
+func (u *UserHandler) GetUsersListHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	userID, err := delivery.GetUserID(r)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	limit, err := utils.ParseUint64FromRequest(r, "limit")
+	if err != nil {
+		limit = 10
+	}
+
+	offset, err := utils.ParseUint64FromRequest(r, "offset")
+	if err != nil {
+		offset = 0
+	}
+
+	users, err := u.service.GetUsersList(ctx, limit, offset, userID)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, u.logger, NewUserWithRolesListResponse(delivery.StatusResponseSuccessful, users))
+	my_logger.FromCtx(r.Context()).Infof("in GetUsersListHandler: get users list: %+v", users)
+}
+
+// SearchUsersByEmailHandler godoc
+//
+//	@Summary    search users
+//	@Description  search users by part of email. Needs permission user:manage
+//	@Tags user
+//	@Produce    json
+//	@Param      email  query string true  "searched part of email"
+//	@Param      limit  query uint64 true  "limit users"
+//	@Param      offset  query uint64 true  "offset of users"
+//	@Success    200  {object} UserWithRolesListResponse
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Router      /user/search_by_email [get]
+func (u *UserHandler) SearchUsersByEmailHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	userID, err := delivery.GetUserID(r)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	searchedEmail := utils.ParseStringFromRequest(r, "email")
+
+	limit, err := utils.ParseUint64FromRequest(r, "limit")
+	if err != nil {
+		limit = 10
+	}
+
+	offset, err := utils.ParseUint64FromRequest(r, "offset")
+	if err != nil {
+		offset = 0
+	}
+
+	users, err := u.service.SearchUsersByEmail(ctx, searchedEmail, limit, offset, userID)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, u.logger, NewUserWithRolesListResponse(delivery.StatusResponseSuccessful, users))
+	my_logger.FromCtx(r.Context()).Infof("in SearchUsersByEmailHandler: search users by email=%s: %+v",
+		searchedEmail, users)
+}
+
+// SuspendUserHandler godoc
+//
+//	@Summary    suspend user
+//	@Description  suspend user account. Suspended user can't sign in, loses all permissions and all sessions.
+//	@Description  Needs permission user:manage
+//	@Tags user
+//	@Accept      json
+//	@Produce    json
+//	@Param      id  query uint64 true  "user id"
+//	@Success    200  {object} delivery.Response
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
+//	@Router      /user/suspend [post]
+func (u *UserHandler) SuspendUserHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	userID, err := delivery.GetUserID(r)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	targetUserID, err := utils.ParseUint64FromRequest(r, "id")
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	err = u.service.SuspendUser(ctx, targetUserID, userID)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, u.logger,
+		delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulSuspend))
+	my_logger.FromCtx(r.Context()).Infof("in SuspendUserHandler: suspend user id=%d", targetUserID)
+}
+
+// UnsuspendUserHandler godoc
+//
+//	@Summary    unsuspend user
+//	@Description  unsuspend user account. Needs permission user:manage
+//	@Tags user
+//	@Accept      json
+//	@Produce    json
+//	@Param      id  query uint64 true  "user id"
+//	@Success    200  {object} delivery.Response
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
+//	@Router      /user/unsuspend [post]
+func (u *UserHandler) UnsuspendUserHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	userID, err := delivery.GetUserID(r)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	targetUserID, err := utils.ParseUint64FromRequest(r, "id")
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	err = u.service.UnsuspendUser(ctx, targetUserID, userID)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, u.logger,
+		delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulUnsuspend))
+	my_logger.FromCtx(r.Context()).Infof("in UnsuspendUserHandler: unsuspend user id=%d", targetUserID)
+}
+
+// DeleteUserHandler godoc
+//
+//	@Summary     delete user
+//	@Description  delete user. Films and actors of deleted user are handed over to user reassign_to,
+//	@Description  by default to user who deletes. Needs permission user:manage
+//	@Tags user
+//	@Accept      json
+//	@Produce    json
+//	@Param      id  query uint64 true  "user id"
+//	@Param      reassign_to  query uint64 false  "id of user who gets films and actors"
+//	@Success    200  {object} delivery.Response
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
+//	@Router      /user/delete [delete]
+func (u *UserHandler) DeleteUserHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	userID, err := delivery.GetUserID(r)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	targetUserID, err := utils.ParseUint64FromRequest(r, "id")
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	reassignToID, err := utils.ParseUint64FromRequest(r, "reassign_to")
+	if err != nil {
+		reassignToID = 0
+	}
+
+	err = u.service.DeleteUser(ctx, targetUserID, reassignToID, userID)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, u.logger,
+		delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulDeleteUser))
+	my_logger.FromCtx(r.Context()).Infof("in DeleteUserHandler: delete user id=%d", targetUserID)
+}
+
+// UnlockUserHandler godoc
+//
+//	@Summary    unlock user
+//	@Description  remove sign in lock of account after too many failed attempts. Needs permission user:manage
+//	@Tags user
+//	@Accept      json
+//	@Produce    json
+//	@Param      id  query uint64 true  "user id"
+//	@Success    200  {object} delivery.Response
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
+//	@Router      /user/unlock [post]
+func (u *UserHandler) UnlockUserHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	userID, err := delivery.GetUserID(r)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	targetUserID, err := utils.ParseUint64FromRequest(r, "id")
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	err = u.service.UnlockUser(ctx, targetUserID, userID)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, u.logger,
+		delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulUnlock))
+	my_logger.FromCtx(r.Context()).Infof("in UnlockUserHandler: unlock user id=%d", targetUserID)
+}
@@ -0,0 +1,138 @@
+package delivery
+
+import (
+	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/SanExpett/film-library-backend/internal/server/delivery"
+	"github.com/SanExpett/film-library-backend/pkg/models"
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+)
+
+const (
+	cookieOIDCLoginName = "oidc_login"
+	oidcCookiePath      = "/api/v1/oidc"
+	oidcLoginLife       = 10 * time.Minute
+
+	oidcLoginSeparator = "."
+	oidcLoginParts     = 4
+)
+
+var ErrOIDCDenied = myerrors.New(myerrors.KindUnauthorized, "oidc_denied", "Вход через oidc провайдера отменен")
+
+// setOIDCLoginCookie keeps provider and secrets of started login until provider redirects back.
+// Provider redirect is cross-site navigation, so cookie is at most SameSite=Lax, otherwise browser doesn't send it.
+func (u *UserHandler) setOIDCLoginCookie(w http.ResponseWriter, providerName string, login *models.OIDCLogin) {
+	value := strings.Join([]string{providerName, login.State, login.Nonce, login.CodeVerifier}, oidcLoginSeparator)
+
+	cookie := u.cookieConfig.NewCookie(cookieOIDCLoginName, value, oidcCookiePath, time.Now().Add(oidcLoginLife), true)
+	if cookie.SameSite == http.SameSiteStrictMode {
+		cookie.SameSite = http.SameSiteLaxMode
+	}
+
+	http.SetCookie(w, cookie)
+}
+
+func getOIDCLoginFromCookie(r *http.Request) (string, *models.OIDCLogin) {
+	cookie, err := r.Cookie(cookieOIDCLoginName)
+	if err != nil {
+		return "", nil
+	}
+
+	parts := strings.Split(cookie.Value, oidcLoginSeparator)
+	if len(parts) != oidcLoginParts {
+		return "", nil
+	}
+
+	return parts[0], &models.OIDCLogin{State: parts[1], Nonce: parts[2], CodeVerifier: parts[3]} //nolint:exhaustruct
+}
+
+// OIDCLoginHandler godoc
+//
+//	@Summary    signin by oidc provider
+//	@Description  start sign in by external identity provider: redirects to login page of provider,
+//	@Description  which redirects back to /oidc/callback
+//	@Tags auth
+//	@Param      provider  query string true  "name of provider from OIDC_PROVIDERS"
+//	@Success    302  {string} string "redirect"
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Router      /oidc/login [get]
+func (u *UserHandler) OIDCLoginHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	providerName := r.URL.Query().Get("provider")
+
+	login, err := u.service.StartOIDCLogin(ctx, providerName)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	u.setOIDCLoginCookie(w, providerName, login)
+	http.Redirect(w, r, login.AuthURL, http.StatusFound)
+}
+
+// OIDCCallbackHandler godoc
+//
+//	@Summary    oidc callback
+//	@Description  provider redirects here after login. External identity is linked to user with the same
+//	@Description  verified email or new user is created, then session is started and user is redirected to app
+//	@Tags auth
+//	@Param      code  query string true  "authorization code"
+//	@Param      state  query string true  "state of login"
+//	@Success    302  {string} string "redirect"
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Router      /oidc/callback [get]
+func (u *UserHandler) OIDCCallbackHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	providerName, login := getOIDCLoginFromCookie(r)
+	// login can be finished only once
+	http.SetCookie(w, u.cookieConfig.NewExpiredCookie(cookieOIDCLoginName, oidcCookiePath, true))
+
+	query := r.URL.Query()
+	if providerError := query.Get("error"); providerError != "" {
+		my_logger.FromCtx(r.Context()).Errorf("in OIDCCallbackHandler: provider %s: error=%s %s",
+			providerName, providerError, query.Get("error_description"))
+		delivery.HandleErr(w, r, u.logger, ErrOIDCDenied)
+
+		return
+	}
+
+	user, err := u.service.FinishOIDCLogin(ctx, providerName, query.Get("code"), query.Get("state"), login)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	err = u.startSession(w, r, user)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	http.Redirect(w, r, u.appURL, http.StatusFound)
+	my_logger.FromCtx(r.Context()).Infof("in OIDCCallbackHandler: signin user id=%d by provider %s",
+		user.ID, providerName)
+}
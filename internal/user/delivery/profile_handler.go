@@ -0,0 +1,263 @@
+package delivery
+
+import (
+	"github.com/SanExpett/film-library-backend/internal/server/delivery"
+	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"net/http"
+)
+
+// MeHandler serves /me, where GET returns profile and DELETE deletes account.
+func (u *UserHandler) MeHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		u.GetMeHandler(w, r)
+	case http.MethodDelete:
+		u.DeleteMeHandler(w, r)
+	default:
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+	}
+}
+
+// GetMeHandler godoc
+//
+//	@Summary    get me
+//	@Description  get profile of current user
+//	@Tags me
+//	@Produce    json
+//	@Success    200  {object} ProfileResponse
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Router      /me [get]
+func (u *UserHandler) GetMeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	userID, err := delivery.GetUserID(r)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	profile, err := u.service.GetProfile(ctx, userID)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, u.logger, NewProfileResponse(delivery.StatusResponseSuccessful, profile))
+}
+
+// DeleteMeHandler godoc
+//
+//	@Summary    delete me
+//	@Description  delete account of current user, current password is required. Films and actors created by user
+//	@Description  are handed over to admin, the only admin can't delete account
+//	@Tags me
+//	@Accept      json
+//	@Produce    json
+//	@Param      deletion  body models.AccountDeletion true  "current password"
+//	@Success    200  {object} delivery.Response
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
+//	@Router      /me [delete]
+func (u *UserHandler) DeleteMeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	userPrincipal, err := delivery.GetSessionPrincipal(r)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	err = u.service.DeleteAccount(ctx, r.Body, userPrincipal.UserID)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	u.clearAuthCookies(w)
+	delivery.SendOkResponse(w, u.logger,
+		delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulDeleteAccount))
+	my_logger.FromCtx(r.Context()).Infof("in DeleteMeHandler: deleted user id=%d", userPrincipal.UserID)
+}
+
+// UpdateProfileHandler godoc
+//
+//	@Summary    update profile
+//	@Description  update display name, avatar url and locale of current user. PATCH updates only passed fields
+//	@Tags me
+//	@Accept      json
+//	@Produce    json
+//	@Param      preProfile  body models.ProfileWithoutID true  "profile data, for PATCH fields are optional"
+//	@Success    200  {object} delivery.Response
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
+//	@Router      /me/profile [patch]
+//	@Router      /me/profile [put]
+func (u *UserHandler) UpdateProfileHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch && r.Method != http.MethodPut {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	userID, err := delivery.GetUserID(r)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	err = u.service.UpdateProfile(ctx, r.Body, r.Method == http.MethodPatch, userID)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, u.logger,
+		delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulUpdateProfile))
+	my_logger.FromCtx(r.Context()).Infof("in UpdateProfileHandler: updated profile of user id=%d", userID)
+}
+
+// ChangePasswordHandler godoc
+//
+//	@Summary    change password
+//	@Description  change password of current user, current password is required. Other sessions are revoked
+//	@Tags me
+//	@Accept      json
+//	@Produce    json
+//	@Param      passwordChange  body models.PasswordChange true  "current and new password"
+//	@Success    200  {object} delivery.Response
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
+//	@Router      /me/password [post]
+func (u *UserHandler) ChangePasswordHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	userPrincipal, err := delivery.GetSessionPrincipal(r)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	err = u.service.ChangePassword(ctx, r.Body, userPrincipal.UserID, userPrincipal.SessionID)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, u.logger,
+		delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulChangePassword))
+	my_logger.FromCtx(r.Context()).Infof("in ChangePasswordHandler: changed password of user id=%d",
+		userPrincipal.UserID)
+}
+
+// RequestEmailChangeHandler godoc
+//
+//	@Summary    request email change
+//	@Description  send confirmation link to new email, current password is required.
+//	@Description  Email is changed only after the link is opened
+//	@Tags me
+//	@Accept      json
+//	@Produce    json
+//	@Param      emailChange  body models.EmailChange true  "new email and current password"
+//	@Success    200  {object} delivery.Response
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
+//	@Router      /me/email [post]
+func (u *UserHandler) RequestEmailChangeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	userPrincipal, err := delivery.GetSessionPrincipal(r)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	err = u.service.RequestEmailChange(ctx, r.Body, userPrincipal.UserID)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, u.logger,
+		delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulEmailChangeRequest))
+}
+
+// ConfirmEmailChangeHandler godoc
+//
+//	@Summary    confirm email change
+//	@Description  replace email of account by new one using token from email, token works once
+//	@Tags me
+//	@Accept      json
+//	@Produce    json
+//	@Param      confirm  body models.UserTokenConfirm true  "token from email"
+//	@Success    200  {object} delivery.Response
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
+//	@Router      /me/email/confirm [post]
+func (u *UserHandler) ConfirmEmailChangeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	err := u.service.ConfirmEmailChange(ctx, r.Body)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, u.logger,
+		delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulEmailChange))
+	my_logger.FromCtx(r.Context()).Infof("in ConfirmEmailChangeHandler: email is changed")
+}
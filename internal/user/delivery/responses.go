@@ -0,0 +1,105 @@
+package delivery
+
+import "github.com/SanExpett/film-library-backend/pkg/models"
+
+const (
+	ResponseSuccessfulGrantRole  = "Роль успешно выдана"
+	ResponseSuccessfulRevokeRole = "Роль успешно снята"
+	ResponseSuccessfulSuspend    = "Пользователь успешно заблокирован"
+	ResponseSuccessfulUnsuspend  = "Пользователь успешно разблокирован"
+	ResponseSuccessfulDeleteUser = "Пользователь успешно удален"
+	ResponseSuccessfulUnlock     = "Вход пользователя успешно разблокирован"
+
+	ResponseSuccessfulUpdateProfile      = "Профиль успешно обновлен"
+	ResponseSuccessfulChangePassword     = "Пароль успешно изменен"
+	ResponseSuccessfulEmailChangeRequest = "Ссылка для смены email отправлена на новый email"
+	ResponseSuccessfulEmailChange        = "Email успешно изменен"
+	ResponseSuccessfulDeleteAccount      = "Аккаунт успешно удален"
+
+	ResponseSuccessfulRevokeAPIKey = "Api ключ успешно отозван"
+)
+
+type RoleListResponse struct {
+	Status int           `json:"status"`
+	Body   []models.Role `json:"body"`
+}
+
+func NewRoleListResponse(status int, body []models.Role) *RoleListResponse {
+	return &RoleListResponse{
+		Status: status,
+		Body:   body,
+	}
+}
+
+type RoleWithPermissionsListResponse struct {
+	Status int                           `json:"status"`
+	Body   []*models.RoleWithPermissions `json:"body"`
+}
+
+func NewRoleWithPermissionsListResponse(status int, body []*models.RoleWithPermissions,
+) *RoleWithPermissionsListResponse {
+	return &RoleWithPermissionsListResponse{
+		Status: status,
+		Body:   body,
+	}
+}
+
+type UserWithRolesListResponse struct {
+	Status int                     `json:"status"`
+	Body   []*models.UserWithRoles `json:"body"`
+}
+
+func NewUserWithRolesListResponse(status int, body []*models.UserWithRoles) *UserWithRolesListResponse {
+	return &UserWithRolesListResponse{
+		Status: status,
+		Body:   body,
+	}
+}
+
+type SessionListResponse struct {
+	Status int               `json:"status"`
+	Body   []*models.Session `json:"body"`
+}
+
+func NewSessionListResponse(status int, body []*models.Session) *SessionListResponse {
+	return &SessionListResponse{
+		Status: status,
+		Body:   body,
+	}
+}
+
+type ProfileResponse struct {
+	Status int             `json:"status"`
+	Body   *models.Profile `json:"body"`
+}
+
+func NewProfileResponse(status int, body *models.Profile) *ProfileResponse {
+	return &ProfileResponse{
+		Status: status,
+		Body:   body,
+	}
+}
+
+type APIKeyListResponse struct {
+	Status int              `json:"status"`
+	Body   []*models.APIKey `json:"body"`
+}
+
+func NewAPIKeyListResponse(status int, body []*models.APIKey) *APIKeyListResponse {
+	return &APIKeyListResponse{
+		Status: status,
+		Body:   body,
+	}
+}
+
+type CreatedAPIKeyResponse struct {
+	Status int                   `json:"status"`
+	Body   *models.CreatedAPIKey `json:"body"`
+}
+
+func NewCreatedAPIKeyResponse(status int, body *models.CreatedAPIKey) *CreatedAPIKeyResponse {
+	return &CreatedAPIKeyResponse{
+		Status: status,
+		Body:   body,
+	}
+}
@@ -0,0 +1,185 @@
+package delivery
+
+import (
+	"github.com/SanExpett/film-library-backend/internal/server/delivery"
+	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"github.com/SanExpett/film-library-backend/pkg/utils"
+	"net/http"
+)
+
+// GrantRoleHandler godoc
+//
+//	@Summary    grant role
+//	@Description  grant role to user. Needs permission role:manage
+//	@Description Errors are sent with http status of error as application/problem+json
+//	@Tags user
+//
+//	@Accept      json
+//	@Produce    json
+//	@Param      userRole  body models.UserRole true  "user id and role name"
+//	@Success    200  {object} delivery.Response
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
+//	@Router      /user/grant_role [post]
+func (u *UserHandler) GrantRoleHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	userID, err := delivery.GetUserID(r)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	err = u.service.GrantRole(ctx, r.Body, userID)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, u.logger,
+		delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulGrantRole))
+	my_logger.FromCtx(r.Context()).Infof("in GrantRoleHandler: grant role by user id=%d", userID)
+}
+
+// RevokeRoleHandler godoc
+//
+//	@Summary    revoke role
+//	@Description  revoke role from user. Needs permission role:manage.
+//	@Description  Admin can not revoke admin role from themselves
+//	@Tags user
+//	@Accept      json
+//	@Produce    json
+//	@Param      user_id  query uint64 true  "user id"
+//	@Param      role  query string true  "role name: viewer, editor, moderator or admin"
+//	@Success    200  {object} delivery.Response
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
+//	@Router      /user/revoke_role [delete]
+func (u *UserHandler) RevokeRoleHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	userID, err := delivery.GetUserID(r)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	targetUserID, err := utils.ParseUint64FromRequest(r, "user_id")
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	role := utils.ParseStringFromRequest(r, "role")
+
+	err = u.service.RevokeRole(ctx, targetUserID, role, userID)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, u.logger,
+		delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulRevokeRole))
+	my_logger.FromCtx(r.Context()).Infof("in RevokeRoleHandler: revoke role=%s from user id=%d", role, targetUserID)
+}
+
+// GetUserRolesHandler godoc
+//
+//	@Summary    get user roles
+//	@Description  get roles of user. Roles of other users need permission role:manage
+//	@Tags user
+//	@Accept      json
+//	@Produce    json
+//	@Param      user_id  query uint64 true  "user id"
+//	@Success    200  {object} RoleListResponse
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Router      /user/get_roles [get]
+func (u *UserHandler) GetUserRolesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	userID, err := delivery.GetUserID(r)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	targetUserID, err := utils.ParseUint64FromRequest(r, "user_id")
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	roles, err := u.service.GetUserRoles(ctx, targetUserID, userID)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, u.logger, NewRoleListResponse(delivery.StatusResponseSuccessful, roles))
+	my_logger.FromCtx(r.Context()).Infof("in GetUserRolesHandler: get roles of user id=%d: %+v", targetUserID, roles)
+}
+
+// GetRolesListHandler godoc
+//
+//	@Summary    get roles list
+//	@Description  get all roles with their permissions
+//	@Tags user
+//	@Accept      json
+//	@Produce    json
+//	@Success    200  {object} RoleWithPermissionsListResponse
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Router      /user/get_roles_list [get]
+func (u *UserHandler) GetRolesListHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	roles, err := u.service.GetRolesList(ctx)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, u.logger,
+		NewRoleWithPermissionsListResponse(delivery.StatusResponseSuccessful, roles))
+	my_logger.FromCtx(r.Context()).Infof("in GetRolesListHandler: get roles list: %+v", roles)
+}
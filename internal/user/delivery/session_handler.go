@@ -0,0 +1,201 @@
+package delivery
+
+import (
+	"github.com/SanExpett/film-library-backend/internal/server/delivery"
+	"github.com/SanExpett/film-library-backend/pkg/jwt"
+	"github.com/SanExpett/film-library-backend/pkg/models"
+	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	ResponseSuccessfulRefresh   = "Successful refresh"
+	ResponseSuccessfulLogOutAll = "Successful log out of all devices"
+
+	refreshCookiePath = "/api/v1"
+)
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// setAuthCookies sets short-lived access token and refresh token of session.
+// Refresh token is sent only to api, js can't read both of them.
+func (u *UserHandler) setAuthCookies(w http.ResponseWriter, authSession *models.AuthSession) error {
+	expire := time.Now().Add(u.jwtKeys.TokenLife())
+
+	jwtStr, err := jwt.GenerateJwtToken(&jwt.UserJwtPayload{ //nolint:exhaustruct
+		UserID:    authSession.User.ID,
+		Email:     authSession.User.Email,
+		Expire:    expire.Unix(),
+		SessionID: authSession.Session.ID,
+	},
+		u.jwtKeys,
+		u.logger,
+	)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	http.SetCookie(w, u.cookieConfig.NewCookie(delivery.CookieAuthName, jwtStr, "/", expire, true))
+	http.SetCookie(w, u.cookieConfig.NewCookie(delivery.CookieRefreshName, authSession.RefreshToken,
+		refreshCookiePath, authSession.Session.ExpiresAt, true))
+
+	// csrf token is changed with session, so token known before sign in is useless after it
+	_, err = delivery.SetNewCSRFToken(w, u.cookieConfig)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return nil
+}
+
+func (u *UserHandler) clearAuthCookies(w http.ResponseWriter) {
+	http.SetCookie(w, u.cookieConfig.NewExpiredCookie(delivery.CookieAuthName, "/", true))
+	http.SetCookie(w, u.cookieConfig.NewExpiredCookie(delivery.CookieRefreshName, refreshCookiePath, true))
+}
+
+func (u *UserHandler) startSession(w http.ResponseWriter, r *http.Request, user *models.UserWithoutPassword) error {
+	authSession, err := u.service.CreateSession(r.Context(), user, r.UserAgent(), clientIP(r))
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return u.setAuthCookies(w, authSession)
+}
+
+// RefreshHandler godoc
+//
+//	@Summary    refresh
+//	@Description  get new access token by refresh token from cookie. Refresh token is rotated,
+//	@Description  reusing of old refresh token revokes the whole session
+//	@Tags auth
+//	@Produce    json
+//	@Success    200  {object} delivery.Response
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
+//	@Router      /refresh [post]
+func (u *UserHandler) RefreshHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	refreshCookie, err := r.Cookie(delivery.CookieRefreshName)
+	if err != nil {
+		my_logger.FromCtx(r.Context()).Errorln(err)
+		delivery.HandleErr(w, r, u.logger, delivery.ErrNoPrincipal)
+
+		return
+	}
+
+	authSession, err := u.service.RefreshSession(ctx, refreshCookie.Value, r.UserAgent(), clientIP(r))
+	if err != nil {
+		u.clearAuthCookies(w)
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	err = u.setAuthCookies(w, authSession)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, u.logger, delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulRefresh))
+	my_logger.FromCtx(r.Context()).Infof("in RefreshHandler: refresh session id=%d", authSession.Session.ID)
+}
+
+// LogOutAllHandler godoc
+//
+//	@Summary    logout of all devices
+//	@Description  revoke all sessions of user
+//	@Tags auth
+//	@Produce    json
+//	@Success    200  {object} delivery.Response
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
+//	@Router      /logout_all [post]
+func (u *UserHandler) LogOutAllHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	userPrincipal, err := delivery.GetSessionPrincipal(r)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	err = u.service.LogOutAll(ctx, userPrincipal.UserID)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	u.clearAuthCookies(w)
+	delivery.SendOkResponse(w, u.logger,
+		delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulLogOutAll))
+	my_logger.FromCtx(r.Context()).Infof("in LogOutAllHandler: logout all sessions of user id=%d",
+		userPrincipal.UserID)
+}
+
+// GetSessionsHandler godoc
+//
+//	@Summary    get my sessions
+//	@Description  get active sessions of user, current session is marked by is_current
+//	@Tags auth
+//	@Produce    json
+//	@Success    200  {object} SessionListResponse
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Router      /sessions [get]
+func (u *UserHandler) GetSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	userPrincipal, err := delivery.GetSessionPrincipal(r)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	sessions, err := u.service.GetSessions(ctx, userPrincipal.UserID, userPrincipal.SessionID)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, u.logger, NewSessionListResponse(delivery.StatusResponseSuccessful, sessions))
+	my_logger.FromCtx(r.Context()).Infof("in GetSessionsHandler: get sessions of user id=%d", userPrincipal.UserID)
+}
@@ -0,0 +1,157 @@
+package delivery
+
+import (
+	"github.com/SanExpett/film-library-backend/internal/server/delivery"
+	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"net/http"
+)
+
+const (
+	ResponseSuccessfulPasswordResetRequest = "Если такой email зарегистрирован, на него отправлена ссылка для восстановления"
+	ResponseSuccessfulPasswordReset        = "Пароль успешно изменен"
+	ResponseSuccessfulVerificationRequest  = "Ссылка для подтверждения отправлена на email"
+	ResponseSuccessfulEmailVerification    = "Email успешно подтвержден"
+)
+
+// RequestPasswordResetHandler godoc
+//
+//	@Summary    request password reset
+//	@Description  send link for password reset to email. Response is the same whether email is registered or not
+//	@Tags auth
+//	@Accept      json
+//	@Produce    json
+//	@Param      request  body models.PasswordResetRequest true  "email of account"
+//	@Success    200  {object} delivery.Response
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
+//	@Router      /password_reset/request [post]
+func (u *UserHandler) RequestPasswordResetHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	err := u.service.RequestPasswordReset(ctx, r.Body)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, u.logger,
+		delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulPasswordResetRequest))
+}
+
+// ConfirmPasswordResetHandler godoc
+//
+//	@Summary    confirm password reset
+//	@Description  set new password by token from email. Token works once, all sessions of user are revoked
+//	@Tags auth
+//	@Accept      json
+//	@Produce    json
+//	@Param      confirm  body models.PasswordResetConfirm true  "token from email and new password"
+//	@Success    200  {object} delivery.Response
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
+//	@Router      /password_reset/confirm [post]
+func (u *UserHandler) ConfirmPasswordResetHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	err := u.service.ConfirmPasswordReset(ctx, r.Body)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, u.logger,
+		delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulPasswordReset))
+	my_logger.FromCtx(r.Context()).Infof("in ConfirmPasswordResetHandler: password is reset")
+}
+
+// RequestEmailVerificationHandler godoc
+//
+//	@Summary    request email verification
+//	@Description  send new link for email verification to email of current user, earlier links stop working
+//	@Tags auth
+//	@Produce    json
+//	@Success    200  {object} delivery.Response
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
+//	@Router      /email_verification/request [post]
+func (u *UserHandler) RequestEmailVerificationHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	userID, err := delivery.GetUserID(r)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	err = u.service.RequestEmailVerification(ctx, userID)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, u.logger,
+		delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulVerificationRequest))
+}
+
+// ConfirmEmailVerificationHandler godoc
+//
+//	@Summary    confirm email verification
+//	@Description  confirm email by token from email, token works once
+//	@Tags auth
+//	@Accept      json
+//	@Produce    json
+//	@Param      confirm  body models.UserTokenConfirm true  "token from email"
+//	@Success    200  {object} delivery.Response
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.Problem "Error"
+//	@Security    CSRFToken
+//	@Router      /email_verification/confirm [post]
+func (u *UserHandler) ConfirmEmailVerificationHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+
+	err := u.service.ConfirmEmailVerification(ctx, r.Body)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, u.logger,
+		delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulEmailVerification))
+	my_logger.FromCtx(r.Context()).Infof("in ConfirmEmailVerificationHandler: email is verified")
+}
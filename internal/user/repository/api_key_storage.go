@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/SanExpett/film-library-backend/internal/server/repository"
+	"github.com/SanExpett/film-library-backend/pkg/metrics"
+	"github.com/SanExpett/film-library-backend/pkg/models"
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+	"github.com/SanExpett/film-library-backend/pkg/tracing"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+var ErrAPIKeyNotFound = myerrors.New(myerrors.KindNotFound, "api_key_not_found", "Api ключ не найден или уже отозван")
+
+func (u *UserStorage) CreateAPIKey(ctx context.Context, preAPIKey *models.PreAPIKey) (*models.APIKey, error) {
+	ctx, span := tracing.Start(ctx, "UserStorage.CreateAPIKey")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageUser, "CreateAPIKey", time.Now())
+
+	SQLCreateAPIKey := `INSERT INTO public."api_key" (user_id, name, prefix, key_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, user_id, name, prefix, scopes, created_at, expires_at, last_used_at`
+
+	apiKey := new(models.APIKey)
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		scopes := make([]string, 0, len(preAPIKey.Scopes))
+		for _, scope := range preAPIKey.Scopes {
+			scopes = append(scopes, string(scope))
+		}
+
+		err := tx.QueryRow(ctx, SQLCreateAPIKey, preAPIKey.UserID, preAPIKey.Name, preAPIKey.Prefix,
+			preAPIKey.KeyHash, scopes, preAPIKey.ExpiresAt).Scan(&apiKey.ID, &apiKey.UserID, &apiKey.Name,
+			&apiKey.Prefix, &scopes, &apiKey.CreatedAt, &apiKey.ExpiresAt, &apiKey.LastUsedAt)
+		if err != nil {
+			u.logger.Errorf("in CreateAPIKey: userID=%d err=%+v", preAPIKey.UserID, err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		apiKey.Scopes = repository.ScopesToPermissions(scopes)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return apiKey, nil
+}
+
+// GetAPIKeys returns not revoked keys of user, expired keys are returned too, so user can see why key stopped working.
+func (u *UserStorage) GetAPIKeys(ctx context.Context, userID uint64) ([]*models.APIKey, error) {
+	ctx, span := tracing.Start(ctx, "UserStorage.GetAPIKeys")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageUser, "GetAPIKeys", time.Now())
+
+	SQLSelectAPIKeys := `SELECT id, user_id, name, prefix, scopes, created_at, expires_at, last_used_at
+		FROM public."api_key"
+		WHERE user_id=$1 AND revoked_at IS NULL
+		ORDER BY created_at DESC`
+
+	var slAPIKeys []*models.APIKey
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		apiKeysRows, err := tx.Query(ctx, SQLSelectAPIKeys, userID)
+		if err != nil {
+			u.logger.Errorln(err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		curAPIKey := new(models.APIKey)
+
+		var curScopes []string
+
+		_, err = pgx.ForEachRow(apiKeysRows, []any{
+			&curAPIKey.ID, &curAPIKey.UserID, &curAPIKey.Name, &curAPIKey.Prefix, &curScopes,
+			&curAPIKey.CreatedAt, &curAPIKey.ExpiresAt, &curAPIKey.LastUsedAt,
+		}, func() error {
+			slAPIKeys = append(slAPIKeys, &models.APIKey{
+				ID:         curAPIKey.ID,
+				UserID:     curAPIKey.UserID,
+				Name:       curAPIKey.Name,
+				Prefix:     curAPIKey.Prefix,
+				Scopes:     repository.ScopesToPermissions(curScopes),
+				CreatedAt:  curAPIKey.CreatedAt,
+				ExpiresAt:  curAPIKey.ExpiresAt,
+				LastUsedAt: curAPIKey.LastUsedAt,
+			})
+
+			return nil
+		})
+		if err != nil {
+			u.logger.Errorln(err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return slAPIKeys, nil
+}
+
+func (u *UserStorage) RevokeAPIKey(ctx context.Context, apiKeyID uint64, userID uint64) error {
+	ctx, span := tracing.Start(ctx, "UserStorage.RevokeAPIKey")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageUser, "RevokeAPIKey", time.Now())
+
+	SQLRevokeAPIKey := `UPDATE public."api_key" SET revoked_at=NOW()
+		WHERE id=$1 AND user_id=$2 AND revoked_at IS NULL`
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, SQLRevokeAPIKey, apiKeyID, userID)
+		if err != nil {
+			u.logger.Errorln(err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		if result.RowsAffected() == 0 {
+			return ErrAPIKeyNotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/SanExpett/film-library-backend/internal/server/repository"
+	"github.com/SanExpett/film-library-backend/pkg/metrics"
+	"github.com/SanExpett/film-library-backend/pkg/models"
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+	"github.com/SanExpett/film-library-backend/pkg/tracing"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+var (
+	ErrIdentityNoEmail = myerrors.New(myerrors.KindUnauthorized, "oidc_no_email",
+		"Oidc провайдер не передал email пользователя")
+	ErrIdentityEmailNotVerified = myerrors.New(myerrors.KindForbidden, "oidc_email_not_verified",
+		"Email не подтвержден у oidc провайдера, подтвердите его у провайдера или войдите паролем")
+)
+
+type identityUser struct {
+	user            *models.UserWithoutPassword
+	isSuspended     bool
+	isEmailVerified bool
+}
+
+func (u *UserStorage) selectUserByIdentity(ctx context.Context, tx pgx.Tx, identity *models.ExternalIdentity,
+) (*identityUser, error) {
+	SQLSelectUserByIdentity := `UPDATE public."user_identity" i SET last_login_at=NOW(), email=$3
+		FROM public."user" u
+		WHERE i.issuer=$1 AND i.subject=$2 AND u.id = i.user_id
+		RETURNING u.id, u.email, u.created_at, u.suspended_at IS NOT NULL`
+
+	found := &identityUser{user: new(models.UserWithoutPassword)} //nolint:exhaustruct
+
+	err := tx.QueryRow(ctx, SQLSelectUserByIdentity, identity.Issuer, identity.Subject, identity.Email).
+		Scan(&found.user.ID, &found.user.Email, &found.user.CreatedAt, &found.isSuspended)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil //nolint:nilnil
+	}
+
+	if err != nil {
+		u.logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return found, nil
+}
+
+func (u *UserStorage) selectUserByEmail(ctx context.Context, tx pgx.Tx, email string) (*identityUser, error) {
+	SQLSelectUserByEmail := `SELECT id, email, created_at, suspended_at IS NOT NULL, email_verified_at IS NOT NULL
+		FROM public."user" WHERE email=$1`
+
+	found := &identityUser{user: new(models.UserWithoutPassword)} //nolint:exhaustruct
+
+	err := tx.QueryRow(ctx, SQLSelectUserByEmail, email).
+		Scan(&found.user.ID, &found.user.Email, &found.user.CreatedAt, &found.isSuspended, &found.isEmailVerified)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil //nolint:nilnil
+	}
+
+	if err != nil {
+		u.logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return found, nil
+}
+
+func (u *UserStorage) createUserByIdentity(ctx context.Context, tx pgx.Tx, identity *models.ExternalIdentity,
+	passwordHash string,
+) (*identityUser, error) {
+	SQLCreateUser := `INSERT INTO public."user" (email, password, email_verified_at, display_name)
+		VALUES ($1, $2, CASE WHEN $3 THEN NOW() END, $4)
+		RETURNING id, email, created_at`
+
+	created := &identityUser{user: new(models.UserWithoutPassword), isSuspended: false, isEmailVerified: false}
+
+	err := tx.QueryRow(ctx, SQLCreateUser, identity.Email, passwordHash, identity.EmailVerified,
+		identity.Name).
+		Scan(&created.user.ID, &created.user.Email, &created.user.CreatedAt)
+	if err != nil {
+		u.logger.Errorf("in createUserByIdentity: email=%s err=%+v", identity.Email, err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	err = u.grantRole(ctx, tx, created.user.ID, models.RoleViewer)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return created, nil
+}
+
+// reclaimUnverifiedUser takes account with not verified email away from whoever registered it.
+// Anyone can sign up with someone else's email, so password of such account, its sessions, api keys, tokens
+// and linked identities are not trusted once owner of email signs in by provider.
+func (u *UserStorage) reclaimUnverifiedUser(ctx context.Context, tx pgx.Tx, userID uint64,
+	passwordHash string,
+) error {
+	SQLReclaimUser := `UPDATE public."user" SET password=$2, email_verified_at=NOW(), pending_email=NULL WHERE id=$1`
+	SQLRevokeAPIKeys := `UPDATE public."api_key" SET revoked_at=NOW() WHERE user_id=$1 AND revoked_at IS NULL`
+	SQLInvalidateTokens := `UPDATE public."user_token" SET used_at=NOW() WHERE user_id=$1 AND used_at IS NULL`
+	SQLUnlinkIdentities := `DELETE FROM public."user_identity" WHERE user_id=$1`
+
+	_, err := tx.Exec(ctx, SQLReclaimUser, userID, passwordHash)
+	if err != nil {
+		u.logger.Errorf("in reclaimUnverifiedUser: userID=%d err=%+v", userID, err)
+
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	for _, SQLRevoke := range []string{SQLRevokeAPIKeys, SQLInvalidateTokens, SQLUnlinkIdentities} {
+		_, err = tx.Exec(ctx, SQLRevoke, userID)
+		if err != nil {
+			u.logger.Errorf("in reclaimUnverifiedUser: userID=%d err=%+v", userID, err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+	}
+
+	return repository.RevokeUserSessions(ctx, tx, userID)
+}
+
+func (u *UserStorage) linkIdentity(ctx context.Context, tx pgx.Tx, userID uint64,
+	identity *models.ExternalIdentity,
+) error {
+	SQLLinkIdentity := `INSERT INTO public."user_identity" (user_id, issuer, subject, email) VALUES ($1, $2, $3, $4)`
+
+	_, err := tx.Exec(ctx, SQLLinkIdentity, userID, identity.Issuer, identity.Subject, identity.Email)
+	if err != nil {
+		u.logger.Errorf("in linkIdentity: userID=%d issuer=%s err=%+v", userID, identity.Issuer, err)
+
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+// SignInByIdentity returns user linked to external identity. Not linked identity is accepted only if provider
+// verified its email, otherwise anyone could register someone else's email at provider and take it here.
+// Identity is linked to user with the same email or to new user if there is no such one. If email of that user
+// isn't verified here, account could be registered by someone else, so it is reclaimed before linking.
+// passwordHash is hash of random password: user can set own password later by password reset.
+// Suspended user is rejected before any change, so failed sign in doesn't touch account.
+func (u *UserStorage) SignInByIdentity(ctx context.Context, identity *models.ExternalIdentity, passwordHash string,
+) (*models.UserWithoutPassword, error) {
+	ctx, span := tracing.Start(ctx, "UserStorage.SignInByIdentity")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageUser, "SignInByIdentity", time.Now())
+
+	var found *identityUser
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		var err error
+
+		found, err = u.selectUserByIdentity(ctx, tx, identity)
+		if err != nil {
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		if found != nil {
+			if found.isSuspended {
+				return ErrUserSuspended
+			}
+
+			return nil
+		}
+
+		if identity.Email == "" {
+			return ErrIdentityNoEmail
+		}
+
+		if !identity.EmailVerified {
+			return ErrIdentityEmailNotVerified
+		}
+
+		found, err = u.selectUserByEmail(ctx, tx, identity.Email)
+		if err != nil {
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		switch {
+		case found == nil:
+			found, err = u.createUserByIdentity(ctx, tx, identity, passwordHash)
+			if err != nil {
+				return fmt.Errorf(myerrors.ErrTemplate, err)
+			}
+		case found.isSuspended:
+			return ErrUserSuspended
+		case !found.isEmailVerified:
+			err = u.reclaimUnverifiedUser(ctx, tx, found.user.ID, passwordHash)
+			if err != nil {
+				return fmt.Errorf(myerrors.ErrTemplate, err)
+			}
+		}
+
+		return u.linkIdentity(ctx, tx, found.user.ID, identity)
+	})
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return found.user, nil
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type loginAttempt struct {
+	failedCount  uint64
+	lastFailedAt time.Time
+	lockedUntil  time.Time
+}
+
+// MemoryLoginAttemptStorage keeps failed sign in attempts in memory of one instance.
+// It is meant for tests and local runs, attempts are lost on restart.
+type MemoryLoginAttemptStorage struct {
+	mu       sync.Mutex
+	attempts map[string]*loginAttempt
+}
+
+func NewMemoryLoginAttemptStorage() *MemoryLoginAttemptStorage {
+	return &MemoryLoginAttemptStorage{attempts: make(map[string]*loginAttempt)} //nolint:exhaustruct
+}
+
+func (m *MemoryLoginAttemptStorage) GetLockedUntil(_ context.Context, key string) (time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	attempt, ok := m.attempts[key]
+	if !ok || !attempt.lockedUntil.After(time.Now()) {
+		return time.Time{}, nil
+	}
+
+	return attempt.lockedUntil, nil
+}
+
+func (m *MemoryLoginAttemptStorage) AddFailedAttempt(_ context.Context, key string, window time.Duration,
+) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+
+	attempt, ok := m.attempts[key]
+	if !ok {
+		attempt = new(loginAttempt)
+		m.attempts[key] = attempt
+	}
+
+	if attempt.lastFailedAt.Before(now.Add(-window)) {
+		attempt.failedCount = 0
+	}
+
+	attempt.failedCount++
+	attempt.lastFailedAt = now
+
+	return attempt.failedCount, nil
+}
+
+func (m *MemoryLoginAttemptStorage) LockUntil(_ context.Context, key string, lockedUntil time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if attempt, ok := m.attempts[key]; ok {
+		attempt.lockedUntil = lockedUntil
+	}
+
+	return nil
+}
+
+func (m *MemoryLoginAttemptStorage) Reset(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.attempts, key)
+
+	return nil
+}
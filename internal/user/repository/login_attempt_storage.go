@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"time"
+)
+
+// LoginAttemptStorage keeps failed sign in attempts in postgres, so lockouts are shared between
+// instances of server and survive restart.
+type LoginAttemptStorage struct {
+	pool   *pgxpool.Pool
+	logger *zap.SugaredLogger
+}
+
+func NewLoginAttemptStorage(pool *pgxpool.Pool) (*LoginAttemptStorage, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoginAttemptStorage{
+		pool:   pool,
+		logger: logger,
+	}, nil
+}
+
+func (l *LoginAttemptStorage) GetLockedUntil(ctx context.Context, key string) (time.Time, error) {
+	SQLGetLockedUntil := `SELECT locked_until FROM public."login_attempt" WHERE key=$1 AND locked_until > NOW()`
+
+	var lockedUntil time.Time
+
+	err := pgx.BeginFunc(ctx, l.pool, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, SQLGetLockedUntil, key).Scan(&lockedUntil)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+
+		if err != nil {
+			l.logger.Errorln(err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return time.Time{}, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return lockedUntil, nil
+}
+
+// AddFailedAttempt counts failed attempt and returns number of failed attempts in a row.
+// The count starts again if previous failure was earlier than window ago.
+func (l *LoginAttemptStorage) AddFailedAttempt(ctx context.Context, key string, window time.Duration,
+) (uint64, error) {
+	SQLAddFailedAttempt := `INSERT INTO public."login_attempt" (key, failed_count, last_failed_at)
+		VALUES ($1, 1, NOW())
+		ON CONFLICT (key) DO UPDATE SET
+			failed_count=CASE WHEN login_attempt.last_failed_at < $2 THEN 1 ELSE login_attempt.failed_count + 1 END,
+			last_failed_at=NOW()
+		RETURNING failed_count`
+
+	var failedCount uint64
+
+	err := pgx.BeginFunc(ctx, l.pool, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, SQLAddFailedAttempt, key, time.Now().Add(-window)).Scan(&failedCount)
+		if err != nil {
+			l.logger.Errorf("in AddFailedAttempt: key=%s err=%+v", key, err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return failedCount, nil
+}
+
+func (l *LoginAttemptStorage) LockUntil(ctx context.Context, key string, lockedUntil time.Time) error {
+	SQLLockUntil := `UPDATE public."login_attempt" SET locked_until=$2 WHERE key=$1`
+
+	err := pgx.BeginFunc(ctx, l.pool, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, SQLLockUntil, key, lockedUntil)
+		if err != nil {
+			l.logger.Errorf("in LockUntil: key=%s err=%+v", key, err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+// Reset forgets failed attempts and removes lock.
+func (l *LoginAttemptStorage) Reset(ctx context.Context, key string) error {
+	SQLReset := `DELETE FROM public."login_attempt" WHERE key=$1`
+
+	err := pgx.BeginFunc(ctx, l.pool, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, SQLReset, key)
+		if err != nil {
+			l.logger.Errorln(err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
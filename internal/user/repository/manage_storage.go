@@ -0,0 +1,292 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/SanExpett/film-library-backend/internal/server/repository"
+	"github.com/SanExpett/film-library-backend/pkg/metrics"
+	"github.com/SanExpett/film-library-backend/pkg/models"
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+	"github.com/SanExpett/film-library-backend/pkg/tracing"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+var (
+	ErrUserAlreadySuspended = myerrors.New(myerrors.KindConflict, "user_already_suspended",
+		"Пользователь уже заблокирован")
+	ErrUserNotSuspended   = myerrors.New(myerrors.KindConflict, "user_not_suspended", "Пользователь не заблокирован")
+	ErrReassignToNotExist = myerrors.New(myerrors.KindNotFound, "reassign_to_not_exist",
+		"Пользователя, которому передаются фильмы и актеры, не существует")
+)
+
+func (u *UserStorage) selectUsersWithRoles(ctx context.Context, tx pgx.Tx,
+	where squirrel.Sqlizer, limit uint64, offset uint64,
+) ([]*models.UserWithRoles, error) {
+	query := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).
+		Select(`u.id, u.email, u.created_at, u.suspended_at,
+			COALESCE(ARRAY_AGG(r.name ORDER BY r.id) FILTER (WHERE r.name IS NOT NULL), '{}')`).
+		From(`public."user" u`).
+		LeftJoin(`public."user_role" ur ON ur.user_id = u.id`).
+		LeftJoin(`public."role" r ON r.id = ur.role_id`).
+		GroupBy("u.id").OrderBy("u.id ASC").Limit(limit).Offset(offset)
+
+	if where != nil {
+		query = query.Where(where)
+	}
+
+	SQLQuery, args, err := query.ToSql()
+	if err != nil {
+		u.logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	rowsUsers, err := tx.Query(ctx, SQLQuery, args...)
+	if err != nil {
+		u.logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	curUser := new(models.UserWithRoles)
+
+	var roles []string
+
+	var slUsers []*models.UserWithRoles
+
+	_, err = pgx.ForEachRow(rowsUsers, []any{
+		&curUser.ID, &curUser.Email, &curUser.CreatedAt, &curUser.SuspendedAt, &roles,
+	}, func() error {
+		user := &models.UserWithRoles{
+			ID:          curUser.ID,
+			Email:       curUser.Email,
+			CreatedAt:   curUser.CreatedAt,
+			SuspendedAt: curUser.SuspendedAt,
+			Roles:       make([]models.Role, 0, len(roles)),
+		}
+
+		for _, role := range roles {
+			user.Roles = append(user.Roles, models.Role(role))
+		}
+
+		slUsers = append(slUsers, user)
+
+		return nil
+	})
+	if err != nil {
+		u.logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return slUsers, nil
+}
+
+func (u *UserStorage) GetUsersList(ctx context.Context, limit uint64, offset uint64,
+) ([]*models.UserWithRoles, error) {
+	ctx, span := tracing.Start(ctx, "UserStorage.GetUsersList")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageUser, "GetUsersList", time.Now())
+
+	var slUsers []*models.UserWithRoles
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		slUsersInner, err := u.selectUsersWithRoles(ctx, tx, nil, limit, offset)
+		if err != nil {
+			return err
+		}
+
+		slUsers = slUsersInner
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return slUsers, nil
+}
+
+func (u *UserStorage) SearchUsersByEmail(ctx context.Context, searchedEmail string, limit uint64, offset uint64,
+) ([]*models.UserWithRoles, error) {
+	ctx, span := tracing.Start(ctx, "UserStorage.SearchUsersByEmail")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageUser, "SearchUsersByEmail", time.Now())
+
+	var slUsers []*models.UserWithRoles
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		slUsersInner, err := u.selectUsersWithRoles(ctx, tx,
+			squirrel.ILike{"u.email": "%" + searchedEmail + "%"}, limit, offset)
+		if err != nil {
+			return err
+		}
+
+		slUsers = slUsersInner
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return slUsers, nil
+}
+
+func (u *UserStorage) setSuspended(ctx context.Context, userID uint64, isSuspended bool) error {
+	SQLSuspendUser := `UPDATE public."user" SET suspended_at=NOW() WHERE id=$1 AND suspended_at IS NULL;`
+	SQLUnsuspendUser := `UPDATE public."user" SET suspended_at=NULL WHERE id=$1 AND suspended_at IS NOT NULL;`
+
+	SQLQuery := SQLUnsuspendUser
+	errNoAffected := ErrUserNotSuspended
+
+	if isSuspended {
+		SQLQuery = SQLSuspendUser
+		errNoAffected = ErrUserAlreadySuspended
+	}
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		isUserExists, err := u.isUserExists(ctx, tx, userID)
+		if err != nil {
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		if !isUserExists {
+			return ErrUserNotExist
+		}
+
+		result, err := tx.Exec(ctx, SQLQuery, userID)
+		if err != nil {
+			u.logger.Errorf("in setSuspended: userID=%d err=%+v", userID, err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		if result.RowsAffected() == 0 {
+			return errNoAffected
+		}
+
+		if isSuspended {
+			err = repository.RevokeUserSessions(ctx, tx, userID)
+			if err != nil {
+				return fmt.Errorf(myerrors.ErrTemplate, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+// SuspendUser blocks account. Suspended user can't sign in and has no permissions,
+// all sessions are revoked, so tokens issued earlier stop working at once.
+func (u *UserStorage) SuspendUser(ctx context.Context, userID uint64) error {
+	ctx, span := tracing.Start(ctx, "UserStorage.SuspendUser")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageUser, "SuspendUser", time.Now())
+
+	return u.setSuspended(ctx, userID, true)
+}
+
+func (u *UserStorage) UnsuspendUser(ctx context.Context, userID uint64) error {
+	ctx, span := tracing.Start(ctx, "UserStorage.UnsuspendUser")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageUser, "UnsuspendUser", time.Now())
+
+	return u.setSuspended(ctx, userID, false)
+}
+
+func (u *UserStorage) deleteUser(ctx context.Context, tx pgx.Tx, userID uint64, reassignToID uint64) error {
+	SQLReassignFilms := `UPDATE public."film" SET author_id=$2 WHERE author_id=$1;`
+	SQLReassignActors := `UPDATE public."actor" SET author_id=$2 WHERE author_id=$1;`
+	SQLDeleteUser := `DELETE FROM public."user" WHERE id=$1;`
+
+	isReassignToExists, err := u.isUserExists(ctx, tx, reassignToID)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	if !isReassignToExists {
+		return ErrReassignToNotExist
+	}
+
+	for _, SQLReassign := range []string{SQLReassignFilms, SQLReassignActors} {
+		_, err = tx.Exec(ctx, SQLReassign, userID, reassignToID)
+		if err != nil {
+			u.logger.Errorf("in deleteUser: userID=%d reassignToID=%d err=%+v", userID, reassignToID, err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+	}
+
+	_, err = tx.Exec(ctx, SQLDeleteUser, userID)
+	if err != nil {
+		u.logger.Errorln(err)
+
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+// DeleteUser removes user and hands over films and actors created by this user to reassignToID.
+func (u *UserStorage) DeleteUser(ctx context.Context, userID uint64, reassignToID uint64) error {
+	ctx, span := tracing.Start(ctx, "UserStorage.DeleteUser")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageUser, "DeleteUser", time.Now())
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		isUserExists, err := u.isUserExists(ctx, tx, userID)
+		if err != nil {
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		if !isUserExists {
+			return ErrUserNotExist
+		}
+
+		return u.deleteUser(ctx, tx, userID, reassignToID)
+	})
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+func (u *UserStorage) GetUserEmail(ctx context.Context, userID uint64) (string, error) {
+	ctx, span := tracing.Start(ctx, "UserStorage.GetUserEmail")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageUser, "GetUserEmail", time.Now())
+
+	SQLGetUserEmail := `SELECT email FROM public."user" WHERE id=$1`
+
+	var email string
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, SQLGetUserEmail, userID).Scan(&email)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrUserNotExist
+		}
+
+		if err != nil {
+			u.logger.Errorln(err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return email, nil
+}
@@ -0,0 +1,355 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/SanExpett/film-library-backend/internal/server/repository"
+	"github.com/SanExpett/film-library-backend/pkg/metrics"
+	"github.com/SanExpett/film-library-backend/pkg/models"
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+	"github.com/SanExpett/film-library-backend/pkg/tracing"
+	"github.com/SanExpett/film-library-backend/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+var (
+	ErrEmailNotChanged = myerrors.New(myerrors.KindConflict, "email_not_changed", "Новый email совпадает с текущим")
+	ErrDeleteLastAdmin = myerrors.New(myerrors.KindConflict, "delete_last_admin",
+		"Нельзя удалить единственного администратора")
+	ErrNoAdminToReassign = myerrors.New(myerrors.KindConflict, "no_admin_to_reassign",
+		"Некому передать ваши фильмы и актеров, обратитесь к администратору")
+)
+
+func (u *UserStorage) selectProfileByID(ctx context.Context, tx pgx.Tx, userID uint64) (*models.Profile, error) {
+	SQLSelectProfile := `SELECT id, email, display_name, avatar_url, locale, email_verified_at IS NOT NULL,
+		pending_email, created_at FROM public."user" WHERE id=$1`
+
+	profile := new(models.Profile)
+
+	err := tx.QueryRow(ctx, SQLSelectProfile, userID).Scan(&profile.ID, &profile.Email, &profile.DisplayName,
+		&profile.AvatarURL, &profile.Locale, &profile.EmailVerified, &profile.PendingEmail, &profile.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrUserNotExist
+	}
+
+	if err != nil {
+		u.logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return profile, nil
+}
+
+func (u *UserStorage) GetProfile(ctx context.Context, userID uint64) (*models.Profile, error) {
+	ctx, span := tracing.Start(ctx, "UserStorage.GetProfile")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageUser, "GetProfile", time.Now())
+
+	var profile *models.Profile
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		profileInner, err := u.selectProfileByID(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+
+		profile = profileInner
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return profile, nil
+}
+
+func (u *UserStorage) UpdateProfile(ctx context.Context, userID uint64, updateFields map[string]interface{}) error {
+	ctx, span := tracing.Start(ctx, "UserStorage.UpdateProfile")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageUser, "UpdateProfile", time.Now())
+
+	if len(updateFields) == 0 {
+		return fmt.Errorf(myerrors.ErrTemplate, ErrNoUpdateFields)
+	}
+
+	query := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).Update(`public."user"`).
+		Where(squirrel.Eq{"id": userID}).SetMap(updateFields)
+
+	queryString, args, err := query.ToSql()
+	if err != nil {
+		u.logger.Errorln(err)
+
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	err = pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, queryString, args...)
+		if err != nil {
+			u.logger.Errorf("in UpdateProfile: userID=%d err=%+v", userID, err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		if result.RowsAffected() == 0 {
+			return ErrNoAffectedUserRows
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+// checkPassword returns ErrWrongPassword if password doesn't match password of user.
+func (u *UserStorage) checkPassword(ctx context.Context, tx pgx.Tx, userID uint64, password string) error {
+	SQLSelectPassword := `SELECT password FROM public."user" WHERE id=$1`
+
+	var hashPass string
+
+	err := tx.QueryRow(ctx, SQLSelectPassword, userID).Scan(&hashPass)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrUserNotExist
+	}
+
+	if err != nil {
+		u.logger.Errorln(err)
+
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	if isMatch, _ := utils.ComparePassAndHash(hashPass, password); !isMatch {
+		return ErrWrongPassword
+	}
+
+	return nil
+}
+
+// ChangePassword sets new password if current one is right and revokes all sessions except currentSessionID,
+// so other devices have to sign in with new password.
+func (u *UserStorage) ChangePassword(ctx context.Context, userID uint64, currentPassword string,
+	newPasswordHash string, currentSessionID uint64,
+) error {
+	ctx, span := tracing.Start(ctx, "UserStorage.ChangePassword")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageUser, "ChangePassword", time.Now())
+
+	SQLUpdatePassword := `UPDATE public."user" SET password=$2 WHERE id=$1`
+	SQLRevokeOtherSessions := `UPDATE public."session" SET revoked_at=NOW()
+		WHERE user_id=$1 AND id<>$2 AND revoked_at IS NULL`
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		err := u.checkPassword(ctx, tx, userID, currentPassword)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, SQLUpdatePassword, userID, newPasswordHash)
+		if err != nil {
+			u.logger.Errorf("in ChangePassword: userID=%d err=%+v", userID, err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		_, err = tx.Exec(ctx, SQLRevokeOtherSessions, userID, currentSessionID)
+		if err != nil {
+			u.logger.Errorln(err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+// RequestEmailChange saves new email as pending and token to confirm it. Email itself is changed only
+// after confirmation, so typo in new email doesn't lock user out.
+func (u *UserStorage) RequestEmailChange(ctx context.Context, userID uint64, password string, newEmail string,
+	tokenHash string, expiresAt time.Time,
+) error {
+	ctx, span := tracing.Start(ctx, "UserStorage.RequestEmailChange")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageUser, "RequestEmailChange", time.Now())
+
+	SQLSetPendingEmail := `UPDATE public."user" SET pending_email=$2 WHERE id=$1 AND email<>$2`
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		err := u.checkPassword(ctx, tx, userID, password)
+		if err != nil {
+			return err
+		}
+
+		emailBusy, err := u.isEmailBusy(ctx, tx, newEmail)
+		if err != nil {
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		result, err := tx.Exec(ctx, SQLSetPendingEmail, userID, newEmail)
+		if err != nil {
+			u.logger.Errorf("in RequestEmailChange: userID=%d err=%+v", userID, err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		if result.RowsAffected() == 0 {
+			return ErrEmailNotChanged
+		}
+
+		if emailBusy {
+			return ErrEmailBusy
+		}
+
+		return u.createUserToken(ctx, tx, userID, models.UserTokenEmailChange, tokenHash, expiresAt)
+	})
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+// ConfirmEmailChange replaces email by pending one. New email is verified by the fact of confirmation.
+func (u *UserStorage) ConfirmEmailChange(ctx context.Context, tokenHash string) error {
+	ctx, span := tracing.Start(ctx, "UserStorage.ConfirmEmailChange")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageUser, "ConfirmEmailChange", time.Now())
+
+	SQLSelectPendingEmail := `SELECT pending_email FROM public."user" WHERE id=$1 AND pending_email IS NOT NULL`
+	SQLChangeEmail := `UPDATE public."user" SET email=pending_email, pending_email=NULL, email_verified_at=NOW()
+		WHERE id=$1`
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		userID, err := u.useUserToken(ctx, tx, models.UserTokenEmailChange, tokenHash)
+		if err != nil {
+			return err
+		}
+
+		var pendingEmail string
+
+		err = tx.QueryRow(ctx, SQLSelectPendingEmail, userID).Scan(&pendingEmail)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrUserTokenInvalid
+		}
+
+		if err != nil {
+			u.logger.Errorln(err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		// email could be taken by someone else while link was waiting
+		emailBusy, err := u.isEmailBusy(ctx, tx, pendingEmail)
+		if err != nil {
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		if emailBusy {
+			return ErrEmailBusy
+		}
+
+		_, err = tx.Exec(ctx, SQLChangeEmail, userID)
+		if err != nil {
+			u.logger.Errorf("in ConfirmEmailChange: userID=%d err=%+v", userID, err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+// selectOtherAdminID returns the oldest admin except userID, or 0 if there is no such admin.
+func (u *UserStorage) selectOtherAdminID(ctx context.Context, tx pgx.Tx, userID uint64) (uint64, error) {
+	SQLSelectOtherAdmin := `SELECT u.id FROM public."user" u
+		JOIN public."user_role" ur ON ur.user_id = u.id
+		JOIN public."role" r ON r.id = ur.role_id
+		WHERE r.name=$2 AND u.id<>$1 AND u.suspended_at IS NULL
+		ORDER BY u.id LIMIT 1`
+
+	var adminID uint64
+
+	err := tx.QueryRow(ctx, SQLSelectOtherAdmin, userID, models.RoleAdmin).Scan(&adminID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+
+	if err != nil {
+		u.logger.Errorln(err)
+
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return adminID, nil
+}
+
+// DeleteAccount deletes own account of user after password check. Films and actors created by user
+// are handed over to the oldest admin, the only admin can't delete account.
+func (u *UserStorage) DeleteAccount(ctx context.Context, userID uint64, password string) error {
+	ctx, span := tracing.Start(ctx, "UserStorage.DeleteAccount")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageUser, "DeleteAccount", time.Now())
+
+	SQLIsAdmin := `SELECT EXISTS(SELECT 1 FROM public."user_role" ur JOIN public."role" r ON r.id = ur.role_id
+		WHERE ur.user_id=$1 AND r.name=$2)`
+	SQLHasContent := `SELECT EXISTS(SELECT 1 FROM public."film" WHERE author_id=$1)
+		OR EXISTS(SELECT 1 FROM public."actor" WHERE author_id=$1)`
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		err := u.checkPassword(ctx, tx, userID, password)
+		if err != nil {
+			return err
+		}
+
+		adminID, err := u.selectOtherAdminID(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+
+		if adminID != 0 {
+			return u.deleteUser(ctx, tx, userID, adminID)
+		}
+
+		isAdmin, err := repository.SelectIsRowExists(ctx, tx, SQLIsAdmin, userID, models.RoleAdmin)
+		if err != nil {
+			return err
+		}
+
+		if isAdmin {
+			return ErrDeleteLastAdmin
+		}
+
+		hasContent, err := repository.SelectIsRowExists(ctx, tx, SQLHasContent, userID)
+		if err != nil {
+			return err
+		}
+
+		if hasContent {
+			return ErrNoAdminToReassign
+		}
+
+		// nothing to hand over, so user is reassigned to itself and just deleted
+		return u.deleteUser(ctx, tx, userID, userID)
+	})
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/SanExpett/film-library-backend/pkg/metrics"
+	"github.com/SanExpett/film-library-backend/pkg/models"
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+	"github.com/SanExpett/film-library-backend/pkg/tracing"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+var (
+	ErrUserNotExist       = myerrors.New(myerrors.KindNotFound, "user_not_exist", "Такого пользователя не существует")
+	ErrRoleNotExist       = myerrors.New(myerrors.KindNotFound, "role_not_exist", "Такой роли не существует")
+	ErrUserAlreadyHasRole = myerrors.New(myerrors.KindConflict, "user_already_has_role",
+		"У пользователя уже есть эта роль")
+	ErrUserHasNoRole = myerrors.New(myerrors.KindNotFound, "user_has_no_role", "У пользователя нет этой роли")
+)
+
+func (u *UserStorage) isUserExists(ctx context.Context, tx pgx.Tx, userID uint64) (bool, error) {
+	SQLIsUserExists := `SELECT EXISTS(SELECT 1 FROM public."user" WHERE id=$1)`
+
+	var isExists bool
+
+	if err := tx.QueryRow(ctx, SQLIsUserExists, userID).Scan(&isExists); err != nil {
+		u.logger.Errorln(err)
+
+		return false, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return isExists, nil
+}
+
+func (u *UserStorage) isRoleExists(ctx context.Context, tx pgx.Tx, role models.Role) (bool, error) {
+	SQLIsRoleExists := `SELECT EXISTS(SELECT 1 FROM public."role" WHERE name=$1)`
+
+	var isExists bool
+
+	if err := tx.QueryRow(ctx, SQLIsRoleExists, role).Scan(&isExists); err != nil {
+		u.logger.Errorln(err)
+
+		return false, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return isExists, nil
+}
+
+func (u *UserStorage) grantRole(ctx context.Context, tx pgx.Tx, userID uint64, role models.Role) error {
+	SQLGrantRole := `INSERT INTO public."user_role" (user_id, role_id)
+		SELECT $1, id FROM public."role" WHERE name=$2
+		ON CONFLICT DO NOTHING;`
+
+	result, err := tx.Exec(ctx, SQLGrantRole, userID, role)
+	if err != nil {
+		u.logger.Errorf("in grantRole: userID=%d role=%s err=%+v", userID, role, err)
+
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf(myerrors.ErrTemplate, ErrUserAlreadyHasRole)
+	}
+
+	return nil
+}
+
+func (u *UserStorage) GrantRole(ctx context.Context, userID uint64, role models.Role) error {
+	ctx, span := tracing.Start(ctx, "UserStorage.GrantRole")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageUser, "GrantRole", time.Now())
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		isUserExists, err := u.isUserExists(ctx, tx, userID)
+		if err != nil {
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		if !isUserExists {
+			return ErrUserNotExist
+		}
+
+		isRoleExists, err := u.isRoleExists(ctx, tx, role)
+		if err != nil {
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		if !isRoleExists {
+			return ErrRoleNotExist
+		}
+
+		err = u.grantRole(ctx, tx, userID, role)
+		if err != nil {
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+func (u *UserStorage) RevokeRole(ctx context.Context, userID uint64, role models.Role) error {
+	ctx, span := tracing.Start(ctx, "UserStorage.RevokeRole")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageUser, "RevokeRole", time.Now())
+
+	SQLRevokeRole := `DELETE FROM public."user_role"
+		WHERE user_id=$1 AND role_id=(SELECT id FROM public."role" WHERE name=$2);`
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		isRoleExists, err := u.isRoleExists(ctx, tx, role)
+		if err != nil {
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		if !isRoleExists {
+			return ErrRoleNotExist
+		}
+
+		result, err := tx.Exec(ctx, SQLRevokeRole, userID, role)
+		if err != nil {
+			u.logger.Errorf("in RevokeRole: userID=%d role=%s err=%+v", userID, role, err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		if result.RowsAffected() == 0 {
+			return ErrUserHasNoRole
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+func (u *UserStorage) GetUserRoles(ctx context.Context, userID uint64) ([]models.Role, error) {
+	ctx, span := tracing.Start(ctx, "UserStorage.GetUserRoles")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageUser, "GetUserRoles", time.Now())
+
+	SQLGetUserRoles := `SELECT r.name
+		FROM public."user_role" ur
+		JOIN public."role" r ON r.id = ur.role_id
+		WHERE ur.user_id=$1
+		ORDER BY r.id;`
+
+	var slRoles []models.Role
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		isUserExists, err := u.isUserExists(ctx, tx, userID)
+		if err != nil {
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		if !isUserExists {
+			return ErrUserNotExist
+		}
+
+		rolesRows, err := tx.Query(ctx, SQLGetUserRoles, userID)
+		if err != nil {
+			u.logger.Errorln(err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		var role models.Role
+
+		_, err = pgx.ForEachRow(rolesRows, []any{&role}, func() error {
+			slRoles = append(slRoles, role)
+
+			return nil
+		})
+		if err != nil {
+			u.logger.Errorln(err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return slRoles, nil
+}
+
+func (u *UserStorage) GetRolesList(ctx context.Context) ([]*models.RoleWithPermissions, error) {
+	ctx, span := tracing.Start(ctx, "UserStorage.GetRolesList")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageUser, "GetRolesList", time.Now())
+
+	SQLGetRolesList := `SELECT r.name, COALESCE(ARRAY_AGG(p.name ORDER BY p.id)
+			FILTER (WHERE p.name IS NOT NULL), '{}')
+		FROM public."role" r
+		LEFT JOIN public."role_permission" rp ON rp.role_id = r.id
+		LEFT JOIN public."permission" p ON p.id = rp.permission_id
+		GROUP BY r.id, r.name
+		ORDER BY r.id;`
+
+	var slRoles []*models.RoleWithPermissions
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		rolesRows, err := tx.Query(ctx, SQLGetRolesList)
+		if err != nil {
+			u.logger.Errorln(err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		var name string
+
+		var permissions []string
+
+		_, err = pgx.ForEachRow(rolesRows, []any{&name, &permissions}, func() error {
+			role := &models.RoleWithPermissions{
+				Name:        models.Role(name),
+				Permissions: make([]models.Permission, 0, len(permissions)),
+			}
+
+			for _, permission := range permissions {
+				role.Permissions = append(role.Permissions, models.Permission(permission))
+			}
+
+			slRoles = append(slRoles, role)
+
+			return nil
+		})
+		if err != nil {
+			u.logger.Errorln(err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return slRoles, nil
+}
@@ -0,0 +1,277 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/SanExpett/film-library-backend/internal/server/repository"
+	"github.com/SanExpett/film-library-backend/pkg/metrics"
+	"github.com/SanExpett/film-library-backend/pkg/models"
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+	"github.com/SanExpett/film-library-backend/pkg/tracing"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+var (
+	ErrSessionNotFound = myerrors.New(myerrors.KindNotFound, "session_not_found",
+		"Сессия не найдена или уже завершена")
+	ErrRefreshTokenReused = myerrors.New(myerrors.KindUnauthorized, "refresh_token_reused",
+		"Refresh токен уже был использован, сессия завершена")
+)
+
+func (u *UserStorage) selectSessionByID(ctx context.Context, tx pgx.Tx, sessionID uint64) (*models.Session, error) {
+	SQLSelectSession := `SELECT id, user_id, user_agent, ip, created_at, last_used_at, expires_at
+		FROM public."session" WHERE id=$1`
+
+	session := new(models.Session)
+
+	err := tx.QueryRow(ctx, SQLSelectSession, sessionID).Scan(&session.ID, &session.UserID,
+		&session.UserAgent, &session.IP, &session.CreatedAt, &session.LastUsedAt, &session.ExpiresAt)
+	if err != nil {
+		u.logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return session, nil
+}
+
+func (u *UserStorage) CreateSession(ctx context.Context, preSession *models.SessionWithoutID,
+) (*models.Session, error) {
+	ctx, span := tracing.Start(ctx, "UserStorage.CreateSession")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageUser, "CreateSession", time.Now())
+
+	SQLCreateSession := `INSERT INTO public."session" (user_id, refresh_token_hash, user_agent, ip, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, user_id, user_agent, ip, created_at, last_used_at, expires_at`
+
+	session := new(models.Session)
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, SQLCreateSession, preSession.UserID, preSession.RefreshTokenHash,
+			preSession.UserAgent, preSession.IP, preSession.ExpiresAt).Scan(&session.ID, &session.UserID,
+			&session.UserAgent, &session.IP, &session.CreatedAt, &session.LastUsedAt, &session.ExpiresAt)
+		if err != nil {
+			u.logger.Errorf("in CreateSession: userID=%d err=%+v", preSession.UserID, err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return session, nil
+}
+
+// revokeIfRefreshTokenReused revokes session if refresh token was already rotated. It means that
+// token was stolen, and both thief and user have to sign in again.
+func (u *UserStorage) revokeIfRefreshTokenReused(ctx context.Context, tx pgx.Tx, refreshTokenHash string,
+) (bool, error) {
+	SQLRevokeReusedSession := `UPDATE public."session" SET revoked_at=NOW()
+		WHERE previous_refresh_token_hash=$1 AND revoked_at IS NULL`
+
+	result, err := tx.Exec(ctx, SQLRevokeReusedSession, refreshTokenHash)
+	if err != nil {
+		u.logger.Errorln(err)
+
+		return false, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return result.RowsAffected() != 0, nil
+}
+
+// RotateSession replaces refresh token of active session by new one and returns session with its user.
+// Expiration of session is set once at sign in and isn't moved by rotation, so session can't live forever.
+func (u *UserStorage) RotateSession(ctx context.Context, refreshTokenHash string,
+	preSession *models.SessionWithoutID,
+) (*models.AuthSession, error) {
+	ctx, span := tracing.Start(ctx, "UserStorage.RotateSession")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageUser, "RotateSession", time.Now())
+
+	SQLRotateSession := `UPDATE public."session" s
+		SET previous_refresh_token_hash=s.refresh_token_hash, refresh_token_hash=$2,
+			user_agent=$3, ip=$4, last_used_at=NOW()
+		FROM public."user" u
+		WHERE s.refresh_token_hash=$1 AND s.revoked_at IS NULL AND s.expires_at > NOW()
+			AND u.id = s.user_id AND u.suspended_at IS NULL
+		RETURNING s.id, u.id, u.email, u.created_at`
+
+	authSession := &models.AuthSession{User: new(models.UserWithoutPassword)} //nolint:exhaustruct
+
+	var isReused bool
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		var sessionID uint64
+
+		err := tx.QueryRow(ctx, SQLRotateSession, refreshTokenHash, preSession.RefreshTokenHash,
+			preSession.UserAgent, preSession.IP).
+			Scan(&sessionID, &authSession.User.ID, &authSession.User.Email, &authSession.User.CreatedAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			isReused, err = u.revokeIfRefreshTokenReused(ctx, tx, refreshTokenHash)
+			if err != nil {
+				return fmt.Errorf(myerrors.ErrTemplate, err)
+			}
+
+			return nil
+		}
+
+		if err != nil {
+			u.logger.Errorln(err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		authSession.Session, err = u.selectSessionByID(ctx, tx, sessionID)
+		if err != nil {
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	// revocation of reused session has to be committed, so error is returned after transaction
+	if isReused {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrRefreshTokenReused)
+	}
+
+	if authSession.Session == nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrSessionNotFound)
+	}
+
+	return authSession, nil
+}
+
+func (u *UserStorage) RevokeSession(ctx context.Context, sessionID uint64, userID uint64) error {
+	ctx, span := tracing.Start(ctx, "UserStorage.RevokeSession")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageUser, "RevokeSession", time.Now())
+
+	SQLRevokeSession := `UPDATE public."session" SET revoked_at=NOW()
+		WHERE id=$1 AND user_id=$2 AND revoked_at IS NULL`
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, SQLRevokeSession, sessionID, userID)
+		if err != nil {
+			u.logger.Errorln(err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		if result.RowsAffected() == 0 {
+			return ErrSessionNotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+func (u *UserStorage) RevokeSessionByRefreshToken(ctx context.Context, refreshTokenHash string) error {
+	ctx, span := tracing.Start(ctx, "UserStorage.RevokeSessionByRefreshToken")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageUser, "RevokeSessionByRefreshToken", time.Now())
+
+	SQLRevokeSession := `UPDATE public."session" SET revoked_at=NOW()
+		WHERE refresh_token_hash=$1 AND revoked_at IS NULL`
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, SQLRevokeSession, refreshTokenHash)
+		if err != nil {
+			u.logger.Errorln(err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		if result.RowsAffected() == 0 {
+			return ErrSessionNotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+func (u *UserStorage) RevokeAllSessions(ctx context.Context, userID uint64) error {
+	ctx, span := tracing.Start(ctx, "UserStorage.RevokeAllSessions")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageUser, "RevokeAllSessions", time.Now())
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		return repository.RevokeUserSessions(ctx, tx, userID)
+	})
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+func (u *UserStorage) GetActiveSessions(ctx context.Context, userID uint64) ([]*models.Session, error) {
+	ctx, span := tracing.Start(ctx, "UserStorage.GetActiveSessions")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageUser, "GetActiveSessions", time.Now())
+
+	SQLSelectSessions := `SELECT id, user_id, user_agent, ip, created_at, last_used_at, expires_at
+		FROM public."session"
+		WHERE user_id=$1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_used_at DESC`
+
+	var slSessions []*models.Session
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		sessionsRows, err := tx.Query(ctx, SQLSelectSessions, userID)
+		if err != nil {
+			u.logger.Errorln(err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		curSession := new(models.Session)
+
+		_, err = pgx.ForEachRow(sessionsRows, []any{
+			&curSession.ID, &curSession.UserID, &curSession.UserAgent, &curSession.IP,
+			&curSession.CreatedAt, &curSession.LastUsedAt, &curSession.ExpiresAt,
+		}, func() error {
+			slSessions = append(slSessions, &models.Session{ //nolint:exhaustruct
+				ID:         curSession.ID,
+				UserID:     curSession.UserID,
+				UserAgent:  curSession.UserAgent,
+				IP:         curSession.IP,
+				CreatedAt:  curSession.CreatedAt,
+				LastUsedAt: curSession.LastUsedAt,
+				ExpiresAt:  curSession.ExpiresAt,
+			})
+
+			return nil
+		})
+		if err != nil {
+			u.logger.Errorln(err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return slSessions, nil
+}
@@ -2,26 +2,35 @@ package repository
 
 import (
 	"context"
-	"encoding/hex"
 	"errors"
 	"fmt"
 	"github.com/SanExpett/film-library-backend/internal/server/repository"
+	"github.com/SanExpett/film-library-backend/pkg/metrics"
 	"github.com/SanExpett/film-library-backend/pkg/models"
 	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
 	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"github.com/SanExpett/film-library-backend/pkg/tracing"
 	"github.com/SanExpett/film-library-backend/pkg/utils"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
+	"time"
 )
 
 var (
-	ErrEmailBusy          = myerrors.NewError("Такой email уже занят")
-	ErrEmailNotExist      = myerrors.NewError("Такой email не существует")
-	ErrPhoneBusy          = myerrors.NewError("Такой телефон уже занят")
-	ErrWrongPassword      = myerrors.NewError("Некорректный пароль")
-	ErrNoUpdateFields     = myerrors.NewError("Вы пытаетесь обновить пустое количество полей")
-	ErrNoAffectedUserRows = myerrors.NewError("Не получилось обновить данные пользователя")
+	ErrEmailBusy      = myerrors.New(myerrors.KindConflict, "email_busy", "Такой email уже занят")
+	ErrEmailNotExist  = myerrors.New(myerrors.KindNotFound, "email_not_exist", "Такой email не существует")
+	ErrPhoneBusy      = myerrors.New(myerrors.KindConflict, "phone_busy", "Такой телефон уже занят")
+	ErrWrongPassword  = myerrors.New(myerrors.KindForbidden, "wrong_password", "Некорректный пароль")
+	ErrNoUpdateFields = myerrors.New(myerrors.KindBadRequest, "user_no_update_fields",
+		"Вы пытаетесь обновить пустое количество полей")
+	ErrNoAffectedUserRows = myerrors.New(myerrors.KindNotFound, "user_not_updated",
+		"Не получилось обновить данные пользователя")
+	ErrUserSuspended = myerrors.New(myerrors.KindForbidden, "user_suspended", "Аккаунт пользователя заблокирован")
+	// ErrInvalidCredentials is returned on sign in both for unknown email and wrong password,
+	// so response doesn't tell whether account with email exists
+	ErrInvalidCredentials = myerrors.New(myerrors.KindUnauthorized, "invalid_credentials",
+		"Неверный email или пароль")
 
 	NameSeqUser = pgx.Identifier{"public", "user_id_seq"} //nolint:gochecknoglobals
 )
@@ -62,6 +71,10 @@ func (u *UserStorage) createUser(ctx context.Context, tx pgx.Tx, preUser *models
 }
 
 func (u *UserStorage) AddUser(ctx context.Context, preUser *models.UserWithoutID) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserStorage.AddUser")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageUser, "AddUser", time.Now())
+
 	user := models.User{} //nolint:exhaustruct
 
 	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
@@ -86,6 +99,11 @@ func (u *UserStorage) AddUser(ctx context.Context, preUser *models.UserWithoutID
 
 		user.ID = id
 
+		err = u.grantRole(ctx, tx, id, models.RoleViewer)
+		if err != nil {
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -117,24 +135,49 @@ func (u *UserStorage) isEmailBusy(ctx context.Context, tx pgx.Tx, email string)
 	return true, nil
 }
 
-func (u *UserStorage) getUserByEmail(ctx context.Context, tx pgx.Tx, email string) (*models.User, error) {
-	SQLGetUserByEmail := `SELECT id, email, password FROM public."user" WHERE email=$1;`
+func (u *UserStorage) getUserByEmail(ctx context.Context, tx pgx.Tx, email string) (*models.User, bool, error) {
+	SQLGetUserByEmail := `SELECT id, email, password, suspended_at IS NOT NULL FROM public."user" WHERE email=$1;`
 	userLine := tx.QueryRow(ctx, SQLGetUserByEmail, email)
 
 	user := models.User{ //nolint:exhaustruct
 		Email: email,
 	}
 
-	if err := userLine.Scan(&user.ID, &user.Email, &user.Password); err != nil {
+	var isSuspended bool
+
+	if err := userLine.Scan(&user.ID, &user.Email, &user.Password, &isSuspended); err != nil {
 		u.logger.Errorln(err)
 
-		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+		return nil, false, fmt.Errorf(myerrors.ErrTemplate, err)
 	}
 
-	return &user, nil
+	return &user, isSuspended, nil
+}
+
+// rehashPassword replaces outdated hash while plain password is known, it is done on successful sign in.
+func (u *UserStorage) rehashPassword(ctx context.Context, tx pgx.Tx, userID uint64, password string) error {
+	SQLUpdatePassword := `UPDATE public."user" SET password=$2 WHERE id=$1`
+
+	passwordHash, err := utils.HashPass(password)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	_, err = tx.Exec(ctx, SQLUpdatePassword, userID, passwordHash)
+	if err != nil {
+		u.logger.Errorf("in rehashPassword: userID=%d err=%+v", userID, err)
+
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
 }
 
 func (u *UserStorage) GetUser(ctx context.Context, email string, password string) (*models.UserWithoutPassword, error) {
+	ctx, span := tracing.Start(ctx, "UserStorage.GetUser")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageUser, "GetUser", time.Now())
+
 	user := &models.User{}                           //nolint:exhaustruct
 	userWithoutPass := &models.UserWithoutPassword{} //nolint:exhaustruct
 
@@ -145,21 +188,27 @@ func (u *UserStorage) GetUser(ctx context.Context, email string, password string
 		}
 
 		if !emailBusy {
-			return ErrEmailNotExist
+			return ErrInvalidCredentials
 		}
 
-		user, err = u.getUserByEmail(ctx, tx, email)
+		var isSuspended bool
+
+		user, isSuspended, err = u.getUserByEmail(ctx, tx, email)
 		if err != nil {
 			return fmt.Errorf(myerrors.ErrTemplate, err)
 		}
 
-		hashPass, err := hex.DecodeString(user.Password)
-		if err != nil {
-			return fmt.Errorf(myerrors.ErrTemplate, err)
+		isMatch, needsRehash := utils.ComparePassAndHash(user.Password, password)
+		if !isMatch {
+			return ErrInvalidCredentials
+		}
+
+		if isSuspended {
+			return ErrUserSuspended
 		}
 
-		if !utils.ComparePassAndHash(hashPass, password) {
-			return ErrWrongPassword
+		if needsRehash {
+			return u.rehashPassword(ctx, tx, user.ID, password)
 		}
 
 		return nil
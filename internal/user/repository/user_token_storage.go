@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/SanExpett/film-library-backend/internal/server/repository"
+	"github.com/SanExpett/film-library-backend/pkg/metrics"
+	"github.com/SanExpett/film-library-backend/pkg/models"
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+	"github.com/SanExpett/film-library-backend/pkg/tracing"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+var (
+	ErrUserTokenInvalid = myerrors.New(myerrors.KindBadRequest, "user_token_invalid",
+		"Ссылка недействительна или устарела")
+	ErrEmailAlreadyVerified = myerrors.New(myerrors.KindConflict, "email_already_verified", "Email уже подтвержден")
+)
+
+// createUserToken saves new token and invalidates earlier unused tokens with the same purpose,
+// so only the last sent link works.
+func (u *UserStorage) createUserToken(ctx context.Context, tx pgx.Tx, userID uint64,
+	purpose models.UserTokenPurpose, tokenHash string, expiresAt time.Time,
+) error {
+	SQLInvalidateTokens := `UPDATE public."user_token" SET used_at=NOW()
+		WHERE user_id=$1 AND purpose=$2 AND used_at IS NULL`
+	SQLCreateToken := `INSERT INTO public."user_token" (user_id, purpose, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)`
+
+	_, err := tx.Exec(ctx, SQLInvalidateTokens, userID, purpose)
+	if err != nil {
+		u.logger.Errorln(err)
+
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	_, err = tx.Exec(ctx, SQLCreateToken, userID, purpose, tokenHash, expiresAt)
+	if err != nil {
+		u.logger.Errorf("in createUserToken: userID=%d purpose=%s err=%+v", userID, purpose, err)
+
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+// useUserToken marks token as used and returns its user. Expired, used and unknown tokens give ErrUserTokenInvalid.
+func (u *UserStorage) useUserToken(ctx context.Context, tx pgx.Tx, purpose models.UserTokenPurpose,
+	tokenHash string,
+) (uint64, error) {
+	SQLUseToken := `UPDATE public."user_token" SET used_at=NOW()
+		WHERE token_hash=$1 AND purpose=$2 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id`
+
+	var userID uint64
+
+	err := tx.QueryRow(ctx, SQLUseToken, tokenHash, purpose).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrUserTokenInvalid
+	}
+
+	if err != nil {
+		u.logger.Errorln(err)
+
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return userID, nil
+}
+
+func (u *UserStorage) CreatePasswordResetToken(ctx context.Context, email string, tokenHash string,
+	expiresAt time.Time,
+) error {
+	ctx, span := tracing.Start(ctx, "UserStorage.CreatePasswordResetToken")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageUser, "CreatePasswordResetToken", time.Now())
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		user, isSuspended, err := u.getUserByEmail(ctx, tx, email)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrEmailNotExist
+		}
+
+		if err != nil {
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		if isSuspended {
+			return ErrUserSuspended
+		}
+
+		return u.createUserToken(ctx, tx, user.ID, models.UserTokenPasswordReset, tokenHash, expiresAt)
+	})
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+// ResetPassword sets new password by reset token and revokes all sessions, so whoever knew
+// old password loses access.
+func (u *UserStorage) ResetPassword(ctx context.Context, tokenHash string, passwordHash string) error {
+	ctx, span := tracing.Start(ctx, "UserStorage.ResetPassword")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageUser, "ResetPassword", time.Now())
+
+	SQLUpdatePassword := `UPDATE public."user" SET password=$2 WHERE id=$1`
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		userID, err := u.useUserToken(ctx, tx, models.UserTokenPasswordReset, tokenHash)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, SQLUpdatePassword, userID, passwordHash)
+		if err != nil {
+			u.logger.Errorf("in ResetPassword: userID=%d err=%+v", userID, err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		return repository.RevokeUserSessions(ctx, tx, userID)
+	})
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+// CreateEmailVerificationToken saves token and returns email it has to be sent to.
+func (u *UserStorage) CreateEmailVerificationToken(ctx context.Context, userID uint64, tokenHash string,
+	expiresAt time.Time,
+) (string, error) {
+	ctx, span := tracing.Start(ctx, "UserStorage.CreateEmailVerificationToken")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageUser, "CreateEmailVerificationToken", time.Now())
+
+	SQLGetEmail := `SELECT email, email_verified_at IS NOT NULL FROM public."user" WHERE id=$1`
+
+	var email string
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		var isVerified bool
+
+		err := tx.QueryRow(ctx, SQLGetEmail, userID).Scan(&email, &isVerified)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrUserNotExist
+		}
+
+		if err != nil {
+			u.logger.Errorln(err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		if isVerified {
+			return ErrEmailAlreadyVerified
+		}
+
+		return u.createUserToken(ctx, tx, userID, models.UserTokenEmailVerification, tokenHash, expiresAt)
+	})
+	if err != nil {
+		return "", fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return email, nil
+}
+
+func (u *UserStorage) VerifyEmail(ctx context.Context, tokenHash string) error {
+	ctx, span := tracing.Start(ctx, "UserStorage.VerifyEmail")
+	defer span.End()
+	defer metrics.ObserveQuery(metrics.StorageUser, "VerifyEmail", time.Now())
+
+	SQLVerifyEmail := `UPDATE public."user" SET email_verified_at=NOW() WHERE id=$1 AND email_verified_at IS NULL`
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		userID, err := u.useUserToken(ctx, tx, models.UserTokenEmailVerification, tokenHash)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, SQLVerifyEmail, userID)
+		if err != nil {
+			u.logger.Errorf("in VerifyEmail: userID=%d err=%+v", userID, err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
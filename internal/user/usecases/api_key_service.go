@@ -0,0 +1,112 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"github.com/SanExpett/film-library-backend/pkg/models"
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+	"github.com/SanExpett/film-library-backend/pkg/tracing"
+	"github.com/SanExpett/film-library-backend/pkg/utils"
+	"io"
+	"time"
+)
+
+const (
+	// apiKeyPrefix marks keys of this service, so leaked key is easy to find by secret scanners.
+	apiKeyPrefix = "flk_"
+	// lenAPIKeyShownPrefix is length of key start which is stored as is and shown in list of keys.
+	lenAPIKeyShownPrefix = len(apiKeyPrefix) + 8
+)
+
+var (
+	ErrAPIKeyUnknownScope = myerrors.New(myerrors.KindValidation, "api_key_scope_unknown",
+		"Неизвестное право в scopes api ключа")
+	ErrAPIKeyExpiresAt = myerrors.New(myerrors.KindValidation, "api_key_expires_at_invalid",
+		"Срок действия api ключа должен быть в будущем")
+)
+
+func (u *UserService) CreateAPIKey(ctx context.Context, r io.Reader, userID uint64) (*models.CreatedAPIKey, error) {
+	ctx, span := tracing.Start(ctx, "UserService.CreateAPIKey")
+	defer span.End()
+
+	preAPIKey, err := ValidateAPIKeyWithoutID(r)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	if preAPIKey.ExpiresAt != nil && !preAPIKey.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrAPIKeyExpiresAt)
+	}
+
+	// key can't give more than user has at the moment of creation, and later it is still limited by roles
+	for _, scope := range preAPIKey.Scopes {
+		if !models.IsKnownPermission(scope) {
+			u.logger.Errorf("in CreateAPIKey: unknown scope %s", scope)
+
+			return nil, fmt.Errorf(myerrors.ErrTemplate, ErrAPIKeyUnknownScope)
+		}
+
+		err = u.policy.Check(ctx, userID, scope)
+		if err != nil {
+			return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+	}
+
+	secretToken, _, err := newSecretToken()
+	if err != nil {
+		u.logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	key := apiKeyPrefix + secretToken
+
+	keyHash, err := utils.Hash256([]byte(key))
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	apiKey, err := u.storage.CreateAPIKey(ctx, &models.PreAPIKey{
+		UserID:    userID,
+		Name:      preAPIKey.Name,
+		Prefix:    key[:lenAPIKeyShownPrefix],
+		KeyHash:   keyHash,
+		Scopes:    preAPIKey.Scopes,
+		ExpiresAt: preAPIKey.ExpiresAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	apiKey.Sanitize()
+
+	return &models.CreatedAPIKey{APIKey: apiKey, Key: key}, nil
+}
+
+func (u *UserService) GetAPIKeys(ctx context.Context, userID uint64) ([]*models.APIKey, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetAPIKeys")
+	defer span.End()
+
+	apiKeys, err := u.storage.GetAPIKeys(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	for _, apiKey := range apiKeys {
+		apiKey.Sanitize()
+	}
+
+	return apiKeys, nil
+}
+
+func (u *UserService) RevokeAPIKey(ctx context.Context, apiKeyID uint64, userID uint64) error {
+	ctx, span := tracing.Start(ctx, "UserService.RevokeAPIKey")
+	defer span.End()
+
+	err := u.storage.RevokeAPIKey(ctx, apiKeyID, userID)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,198 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	serverrepo "github.com/SanExpett/film-library-backend/internal/server/repository"
+	userrepo "github.com/SanExpett/film-library-backend/internal/user/repository"
+	"github.com/SanExpett/film-library-backend/pkg/models"
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	prefixAccountKey = "account:"
+	prefixIPKey      = "ip:"
+
+	// maxLockShift bounds exponent of backoff, so lock duration doesn't overflow
+	maxLockShift = 30
+)
+
+var (
+	_ ILoginAttemptStore = (*userrepo.LoginAttemptStorage)(nil)
+	_ ILoginAttemptStore = (*userrepo.MemoryLoginAttemptStorage)(nil)
+	_ IAuditLog          = (*serverrepo.AuditStorage)(nil)
+
+	ErrTooManySignInAttempts = myerrors.New(myerrors.KindTooManyRequests, "too_many_sign_in_attempts",
+		"Слишком много неудачных попыток входа, вход временно заблокирован")
+)
+
+// ILoginAttemptStore counts failed sign in attempts by key, key is email of account or ip of client.
+type ILoginAttemptStore interface {
+	GetLockedUntil(ctx context.Context, key string) (time.Time, error)
+	AddFailedAttempt(ctx context.Context, key string, window time.Duration) (uint64, error)
+	LockUntil(ctx context.Context, key string, lockedUntil time.Time) error
+	Reset(ctx context.Context, key string) error
+}
+
+type IAuditLog interface {
+	AddAuditEntry(ctx context.Context, entry *models.AuditEntry) error
+}
+
+// LoginLimits configure LoginLimiter. After MaxAttempts failures in a row account is locked for LockBase,
+// every next failure doubles lock up to LockMax. The same is done for ip with MaxAttemptsPerIP.
+// Failures older than AttemptsWindow are forgotten.
+type LoginLimits struct {
+	MaxAttempts      uint64
+	MaxAttemptsPerIP uint64
+	LockBase         time.Duration
+	LockMax          time.Duration
+	AttemptsWindow   time.Duration
+}
+
+type LoginLimiter struct {
+	store    ILoginAttemptStore
+	auditLog IAuditLog
+	limits   *LoginLimits
+	logger   *zap.SugaredLogger
+}
+
+func NewLoginLimiter(store ILoginAttemptStore, auditLog IAuditLog, limits *LoginLimits) (*LoginLimiter, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoginLimiter{store: store, auditLog: auditLog, limits: limits, logger: logger}, nil
+}
+
+func (l *LoginLimiter) lockDuration(failedCount uint64, maxAttempts uint64) time.Duration {
+	if failedCount < maxAttempts {
+		return 0
+	}
+
+	shift := failedCount - maxAttempts
+	// LockBase << shift is compared with LockMax before shifting, otherwise it could overflow into short lock
+	if shift > maxLockShift || l.limits.LockBase > l.limits.LockMax>>shift {
+		return l.limits.LockMax
+	}
+
+	return l.limits.LockBase << shift
+}
+
+func (l *LoginLimiter) keys(email string, ip string) []string {
+	keys := []string{prefixAccountKey + email}
+	if ip != "" {
+		keys = append(keys, prefixIPKey+ip)
+	}
+
+	return keys
+}
+
+// Check returns ErrTooManySignInAttempts if account or ip is locked now.
+func (l *LoginLimiter) Check(ctx context.Context, email string, ip string) error {
+	for _, key := range l.keys(email, ip) {
+		lockedUntil, err := l.store.GetLockedUntil(ctx, key)
+		if err != nil {
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		if !lockedUntil.IsZero() {
+			return fmt.Errorf("%w, повторите после %s", ErrTooManySignInAttempts,
+				lockedUntil.UTC().Format(time.RFC3339))
+		}
+	}
+
+	return nil
+}
+
+func (l *LoginLimiter) registerFailure(ctx context.Context, key string, maxAttempts uint64, entry *models.AuditEntry,
+) error {
+	failedCount, err := l.store.AddFailedAttempt(ctx, key, l.limits.AttemptsWindow)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	lockDuration := l.lockDuration(failedCount, maxAttempts)
+	if lockDuration == 0 {
+		return nil
+	}
+
+	lockedUntil := time.Now().Add(lockDuration)
+
+	err = l.store.LockUntil(ctx, key, lockedUntil)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	entry.Details = fmt.Sprintf("failed_count=%d locked_until=%s", failedCount, lockedUntil.UTC().Format(time.RFC3339))
+	l.logger.Warnf("in registerFailure: %s %s, %s", entry.Action, entry.Target, entry.Details)
+
+	err = l.auditLog.AddAuditEntry(ctx, entry)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+// RegisterFailure counts failed attempt for account and ip and locks them when limits are exceeded.
+// Attempt is counted even if client disconnects, otherwise attacker could drop requests with wrong password.
+func (l *LoginLimiter) RegisterFailure(ctx context.Context, email string, ip string) error {
+	ctx = context.WithoutCancel(ctx)
+
+	err := l.registerFailure(ctx, prefixAccountKey+email, l.limits.MaxAttempts, &models.AuditEntry{ //nolint:exhaustruct
+		Action: models.AuditActionAccountLocked,
+		Target: email,
+		IP:     ip,
+	})
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	if ip == "" {
+		return nil
+	}
+
+	err = l.registerFailure(ctx, prefixIPKey+ip, l.limits.MaxAttemptsPerIP, &models.AuditEntry{ //nolint:exhaustruct
+		Action: models.AuditActionIPLocked,
+		Target: ip,
+		IP:     ip,
+	})
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+// RegisterSuccess forgets failed attempts of account. Attempts of ip are kept, otherwise
+// attacker could reset them by signing in own account between guesses.
+func (l *LoginLimiter) RegisterSuccess(ctx context.Context, email string) error {
+	err := l.store.Reset(ctx, prefixAccountKey+email)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+func (l *LoginLimiter) UnlockAccount(ctx context.Context, email string, actorID uint64) error {
+	err := l.store.Reset(ctx, prefixAccountKey+email)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	err = l.auditLog.AddAuditEntry(ctx, &models.AuditEntry{ //nolint:exhaustruct
+		ActorID: &actorID,
+		Action:  models.AuditActionAccountUnlocked,
+		Target:  email,
+	})
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,336 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	userrepo "github.com/SanExpett/film-library-backend/internal/user/repository"
+	"github.com/SanExpett/film-library-backend/pkg/models"
+)
+
+const (
+	testEmail      = "user@example.com"
+	testOtherEmail = "other@example.com"
+	testIP         = "10.0.0.1"
+	testOtherIP    = "10.0.0.2"
+)
+
+type fakeAuditLog struct {
+	mu      sync.Mutex
+	entries []*models.AuditEntry
+}
+
+func (f *fakeAuditLog) AddAuditEntry(_ context.Context, entry *models.AuditEntry) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.entries = append(f.entries, entry)
+
+	return nil
+}
+
+func (f *fakeAuditLog) actions() []models.AuditAction {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	actions := make([]models.AuditAction, 0, len(f.entries))
+	for _, entry := range f.entries {
+		actions = append(actions, entry.Action)
+	}
+
+	return actions
+}
+
+func newTestLoginLimiter(t *testing.T, limits *LoginLimits) (*LoginLimiter, *fakeAuditLog) {
+	t.Helper()
+
+	auditLog := new(fakeAuditLog)
+
+	limiter, err := NewLoginLimiter(userrepo.NewMemoryLoginAttemptStorage(), auditLog, limits)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return limiter, auditLog
+}
+
+func TestLoginLimiterLockDuration(t *testing.T) {
+	t.Parallel()
+
+	limiter, _ := newTestLoginLimiter(t, &LoginLimits{ //nolint:exhaustruct
+		LockBase: time.Minute,
+		LockMax:  10 * time.Minute,
+	})
+
+	hourLimiter, _ := newTestLoginLimiter(t, &LoginLimits{ //nolint:exhaustruct
+		LockBase: time.Hour,
+		LockMax:  24 * time.Hour,
+	})
+
+	// base << 25 is 2^64 + 2^25 nanoseconds, it wraps to 33ms without check of overflow
+	overflowLimiter, _ := newTestLoginLimiter(t, &LoginLimits{ //nolint:exhaustruct
+		LockBase: 1<<39 + 1,
+		LockMax:  24 * time.Hour,
+	})
+
+	tests := []struct {
+		name        string
+		limiter     *LoginLimiter
+		failedCount uint64
+		maxAttempts uint64
+		want        time.Duration
+	}{
+		{name: "below limit", limiter: limiter, failedCount: 2, maxAttempts: 3, want: 0},
+		{name: "first lock is base", limiter: limiter, failedCount: 3, maxAttempts: 3, want: time.Minute},
+		{name: "second lock is doubled", limiter: limiter, failedCount: 4, maxAttempts: 3, want: 2 * time.Minute},
+		{name: "third lock is doubled again", limiter: limiter, failedCount: 5, maxAttempts: 3, want: 4 * time.Minute},
+		{name: "lock is capped", limiter: limiter, failedCount: 8, maxAttempts: 3, want: 10 * time.Minute},
+		{name: "hours below cap", limiter: hourLimiter, failedCount: 4, maxAttempts: 1, want: 8 * time.Hour},
+		{name: "shift beyond max shift", limiter: limiter, failedCount: 1000, maxAttempts: 3, want: 10 * time.Minute},
+		{name: "shift overflowing int64", limiter: overflowLimiter, failedCount: 26, maxAttempts: 1, want: 24 * time.Hour},
+		{name: "max attempts of one", limiter: limiter, failedCount: 1, maxAttempts: 1, want: time.Minute},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := test.limiter.lockDuration(test.failedCount, test.maxAttempts)
+			if got != test.want {
+				t.Errorf("lockDuration(%d, %d) = %s, want %s", test.failedCount, test.maxAttempts, got, test.want)
+			}
+		})
+	}
+}
+
+type loginStep struct {
+	email     string
+	ip        string
+	isSuccess bool
+}
+
+func TestLoginLimiter(t *testing.T) {
+	t.Parallel()
+
+	limits := &LoginLimits{
+		MaxAttempts:      3,
+		MaxAttemptsPerIP: 5,
+		LockBase:         time.Minute,
+		LockMax:          time.Hour,
+		AttemptsWindow:   time.Hour,
+	}
+
+	repeat := func(count int, step loginStep) []loginStep {
+		steps := make([]loginStep, 0, count)
+		for i := 0; i < count; i++ {
+			steps = append(steps, step)
+		}
+
+		return steps
+	}
+
+	tests := []struct {
+		name        string
+		limits      *LoginLimits
+		steps       []loginStep
+		checkEmail  string
+		checkIP     string
+		wantLocked  bool
+		wantActions []models.AuditAction
+	}{
+		{
+			name:       "failures below account limit",
+			limits:     limits,
+			steps:      repeat(2, loginStep{email: testEmail, ip: testIP}), //nolint:exhaustruct
+			checkEmail: testEmail,
+			checkIP:    testIP,
+			wantLocked: false,
+		},
+		{
+			name:        "account is locked after max attempts",
+			limits:      limits,
+			steps:       repeat(3, loginStep{email: testEmail, ip: testIP}), //nolint:exhaustruct
+			checkEmail:  testEmail,
+			checkIP:     testOtherIP,
+			wantLocked:  true,
+			wantActions: []models.AuditAction{models.AuditActionAccountLocked},
+		},
+		{
+			name:        "lock of account doesn't affect other account from the same ip",
+			limits:      limits,
+			steps:       repeat(3, loginStep{email: testEmail, ip: testIP}), //nolint:exhaustruct
+			checkEmail:  testOtherEmail,
+			checkIP:     testIP,
+			wantLocked:  false,
+			wantActions: []models.AuditAction{models.AuditActionAccountLocked},
+		},
+		{
+			name:   "ip is locked after failures for different accounts",
+			limits: limits,
+			steps: []loginStep{
+				{email: "a@example.com", ip: testIP}, //nolint:exhaustruct
+				{email: "b@example.com", ip: testIP}, //nolint:exhaustruct
+				{email: "c@example.com", ip: testIP}, //nolint:exhaustruct
+				{email: "d@example.com", ip: testIP}, //nolint:exhaustruct
+				{email: "e@example.com", ip: testIP}, //nolint:exhaustruct
+			},
+			checkEmail:  testOtherEmail,
+			checkIP:     testIP,
+			wantLocked:  true,
+			wantActions: []models.AuditAction{models.AuditActionIPLocked},
+		},
+		{
+			name:   "success resets account failures",
+			limits: limits,
+			steps: append(repeat(2, loginStep{email: testEmail, ip: testIP}), //nolint:exhaustruct
+				loginStep{email: testEmail, ip: testIP, isSuccess: true},
+				loginStep{email: testEmail, ip: testIP}), //nolint:exhaustruct
+			checkEmail: testEmail,
+			checkIP:    testOtherIP,
+			wantLocked: false,
+		},
+		{
+			name:   "success doesn't reset ip failures",
+			limits: limits,
+			steps: []loginStep{
+				{email: "a@example.com", ip: testIP},                 //nolint:exhaustruct
+				{email: "b@example.com", ip: testIP},                 //nolint:exhaustruct
+				{email: testOtherEmail, ip: testIP, isSuccess: true}, //nolint:exhaustruct
+				{email: "c@example.com", ip: testIP},                 //nolint:exhaustruct
+				{email: "d@example.com", ip: testIP},                 //nolint:exhaustruct
+				{email: "e@example.com", ip: testIP},                 //nolint:exhaustruct
+			},
+			checkEmail:  testOtherEmail,
+			checkIP:     testIP,
+			wantLocked:  true,
+			wantActions: []models.AuditAction{models.AuditActionIPLocked},
+		},
+		{
+			name: "failures older than window are forgotten",
+			limits: &LoginLimits{
+				MaxAttempts:      3,
+				MaxAttemptsPerIP: 5,
+				LockBase:         time.Minute,
+				LockMax:          time.Hour,
+				AttemptsWindow:   time.Nanosecond,
+			},
+			steps:      repeat(10, loginStep{email: testEmail, ip: testIP}), //nolint:exhaustruct
+			checkEmail: testEmail,
+			checkIP:    testIP,
+			wantLocked: false,
+		},
+		{
+			name:       "failures without ip count only for account",
+			limits:     limits,
+			steps:      repeat(5, loginStep{email: testEmail}), //nolint:exhaustruct
+			checkEmail: testOtherEmail,
+			checkIP:    testIP,
+			wantLocked: false,
+			wantActions: []models.AuditAction{
+				models.AuditActionAccountLocked, models.AuditActionAccountLocked, models.AuditActionAccountLocked,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			limiter, auditLog := newTestLoginLimiter(t, test.limits)
+
+			for _, step := range test.steps {
+				var err error
+				if step.isSuccess {
+					err = limiter.RegisterSuccess(ctx, step.email)
+				} else {
+					err = limiter.RegisterFailure(ctx, step.email, step.ip)
+				}
+
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := limiter.Check(ctx, test.checkEmail, test.checkIP)
+			if isLocked := errors.Is(err, ErrTooManySignInAttempts); isLocked != test.wantLocked {
+				t.Errorf("Check() err = %v, want locked = %t", err, test.wantLocked)
+			}
+
+			gotActions := auditLog.actions()
+			if len(gotActions) != len(test.wantActions) {
+				t.Fatalf("audit actions = %v, want %v", gotActions, test.wantActions)
+			}
+
+			for i := range gotActions {
+				if gotActions[i] != test.wantActions[i] {
+					t.Errorf("audit actions = %v, want %v", gotActions, test.wantActions)
+				}
+			}
+		})
+	}
+}
+
+func TestLoginLimiterBackoffGrows(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := userrepo.NewMemoryLoginAttemptStorage()
+
+	limiter, err := NewLoginLimiter(store, new(fakeAuditLog), &LoginLimits{
+		MaxAttempts:      2,
+		MaxAttemptsPerIP: 100,
+		LockBase:         time.Minute,
+		LockMax:          5 * time.Minute,
+		AttemptsWindow:   time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantLocks := []time.Duration{0, time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute}
+
+	for i, wantLock := range wantLocks {
+		start := time.Now()
+
+		err = limiter.RegisterFailure(ctx, testEmail, testIP)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		lockedUntil, err := store.GetLockedUntil(ctx, prefixAccountKey+testEmail)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if wantLock == 0 {
+			if !lockedUntil.IsZero() {
+				t.Errorf("failure %d: locked until %s, want not locked", i+1, lockedUntil)
+			}
+
+			continue
+		}
+
+		gotLock := lockedUntil.Sub(start)
+		if gotLock < wantLock || gotLock > wantLock+time.Second {
+			t.Errorf("failure %d: locked for %s, want %s", i+1, gotLock, wantLock)
+		}
+	}
+
+	err = limiter.UnlockAccount(ctx, testEmail, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = limiter.Check(ctx, testEmail, "")
+	if err != nil {
+		t.Errorf("Check() after unlock err = %v, want nil", err)
+	}
+}
@@ -0,0 +1,17 @@
+package usecases
+
+import (
+	"os"
+	"testing"
+
+	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+)
+
+func TestMain(m *testing.M) {
+	_, err := my_logger.New([]string{os.DevNull}, []string{os.DevNull})
+	if err != nil {
+		panic(err)
+	}
+
+	os.Exit(m.Run())
+}
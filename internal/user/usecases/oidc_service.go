@@ -0,0 +1,123 @@
+package usecases
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"github.com/SanExpett/film-library-backend/pkg/models"
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+	"github.com/SanExpett/film-library-backend/pkg/oidc"
+	"github.com/SanExpett/film-library-backend/pkg/tracing"
+	"github.com/SanExpett/film-library-backend/pkg/utils"
+)
+
+var _ IOIDCProvider = (*oidc.Provider)(nil)
+
+var (
+	ErrUnknownOIDCProvider = myerrors.New(myerrors.KindNotFound, "oidc_provider_unknown", "Неизвестный oidc провайдер")
+	ErrOIDCState           = myerrors.New(myerrors.KindBadRequest, "oidc_state_invalid",
+		"Некорректное состояние входа через oidc, начните вход заново")
+	ErrOIDCNoCode = myerrors.New(myerrors.KindBadRequest, "oidc_no_code",
+		"Oidc провайдер не передал код авторизации")
+)
+
+type IOIDCProvider interface {
+	AuthCodeURL(ctx context.Context, state string, nonce string, codeVerifier string) (string, error)
+	Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (*oidc.Claims, error)
+}
+
+func (u *UserService) getOIDCProvider(providerName string) (IOIDCProvider, error) {
+	provider, ok := u.options.OIDCProviders[providerName]
+	if !ok {
+		u.logger.Errorf("in getOIDCProvider: unknown provider %s", providerName)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrUnknownOIDCProvider)
+	}
+
+	return provider, nil
+}
+
+// StartOIDCLogin makes secrets of new sign in by provider. State protects callback from csrf,
+// nonce binds id token to this sign in and code verifier (PKCE) binds authorization code to it.
+func (u *UserService) StartOIDCLogin(ctx context.Context, providerName string) (*models.OIDCLogin, error) {
+	ctx, span := tracing.Start(ctx, "UserService.StartOIDCLogin")
+	defer span.End()
+
+	provider, err := u.getOIDCProvider(providerName)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	login := new(models.OIDCLogin)
+
+	for _, secret := range []*string{&login.State, &login.Nonce, &login.CodeVerifier} {
+		*secret, _, err = newSecretToken()
+		if err != nil {
+			u.logger.Errorln(err)
+
+			return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+	}
+
+	login.AuthURL, err = provider.AuthCodeURL(ctx, login.State, login.Nonce, login.CodeVerifier)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return login, nil
+}
+
+// FinishOIDCLogin checks state returned by provider against started login, exchanges code
+// and returns user linked to identity, creating one on first sign in.
+func (u *UserService) FinishOIDCLogin(ctx context.Context, providerName string, code string, state string,
+	login *models.OIDCLogin,
+) (*models.UserWithoutPassword, error) {
+	ctx, span := tracing.Start(ctx, "UserService.FinishOIDCLogin")
+	defer span.End()
+
+	provider, err := u.getOIDCProvider(providerName)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	if login == nil || login.State == "" || subtle.ConstantTimeCompare([]byte(login.State), []byte(state)) != 1 {
+		u.logger.Errorf("in FinishOIDCLogin: provider %s: state mismatch", providerName)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrOIDCState)
+	}
+
+	if code == "" {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrOIDCNoCode)
+	}
+
+	claims, err := provider.Exchange(ctx, code, login.CodeVerifier, login.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	randomPassword, _, err := newSecretToken()
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	passwordHash, err := utils.HashPass(randomPassword)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	identity := &models.ExternalIdentity{
+		Issuer:        claims.Issuer,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+	}
+	identity.Trim()
+
+	user, err := u.storage.SignInByIdentity(ctx, identity, passwordHash)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return user, nil
+}
@@ -0,0 +1,192 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SanExpett/film-library-backend/pkg/models"
+	"github.com/SanExpett/film-library-backend/pkg/oidc"
+	"github.com/SanExpett/film-library-backend/pkg/oidc/oidctest"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testSubject = "alice"
+	testCode    = "code"
+)
+
+func newTestOIDCService(t *testing.T, stub *oidctest.Provider) (*UserService, *fakeUserStorage) {
+	t.Helper()
+
+	provider, err := oidc.NewProvider(stub.Config())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service, storage, _ := newTestUserService(t, &UserServiceOptions{ //nolint:exhaustruct
+		OIDCProviders: map[string]IOIDCProvider{oidctest.Name: provider},
+	})
+
+	return service, storage
+}
+
+func TestFinishOIDCLogin(t *testing.T) {
+	t.Parallel()
+
+	stub := oidctest.NewProvider(t)
+
+	tests := []struct {
+		name string
+		// change makes claims of valid token invalid
+		change   func(claims jwt.MapClaims)
+		keyID    string
+		state    string
+		noCode   bool
+		provider string
+		wantErr  error
+	}{
+		{
+			name:     "valid sign in",
+			change:   func(jwt.MapClaims) {},
+			provider: oidctest.Name,
+			wantErr:  nil,
+		},
+		{
+			name:     "state mismatch",
+			change:   func(jwt.MapClaims) {},
+			state:    "other state",
+			provider: oidctest.Name,
+			wantErr:  ErrOIDCState,
+		},
+		{
+			name:     "no code",
+			change:   func(jwt.MapClaims) {},
+			noCode:   true,
+			provider: oidctest.Name,
+			wantErr:  ErrOIDCNoCode,
+		},
+		{
+			name:     "unknown provider",
+			change:   func(jwt.MapClaims) {},
+			provider: "other",
+			wantErr:  ErrUnknownOIDCProvider,
+		},
+		{
+			name:     "wrong nonce",
+			change:   func(claims jwt.MapClaims) { claims["nonce"] = "nonce of other sign in" },
+			provider: oidctest.Name,
+			wantErr:  oidc.ErrInvalidIDToken,
+		},
+		{
+			name:     "wrong audience",
+			change:   func(claims jwt.MapClaims) { claims["aud"] = "other-client" },
+			provider: oidctest.Name,
+			wantErr:  oidc.ErrInvalidIDToken,
+		},
+		{
+			name: "wrong azp",
+			change: func(claims jwt.MapClaims) {
+				claims["aud"] = []string{oidctest.ClientID, "other-client"}
+				claims["azp"] = "other-client"
+			},
+			provider: oidctest.Name,
+			wantErr:  oidc.ErrInvalidIDToken,
+		},
+		{
+			name: "expired token",
+			change: func(claims jwt.MapClaims) {
+				claims["iat"] = time.Now().Add(-time.Hour).Unix()
+				claims["exp"] = time.Now().Add(-10 * time.Minute).Unix()
+			},
+			provider: oidctest.Name,
+			wantErr:  oidc.ErrInvalidIDToken,
+		},
+		{
+			name:     "unknown kid",
+			change:   func(jwt.MapClaims) {},
+			keyID:    "unknown-key",
+			provider: oidctest.Name,
+			wantErr:  oidc.ErrUnknownKey,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			service, storage := newTestOIDCService(t, stub)
+
+			login, err := service.StartOIDCLogin(ctx, oidctest.Name)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			claims := stub.Claims(testSubject, login.Nonce)
+			test.change(claims)
+
+			keyID := test.keyID
+			if keyID == "" {
+				keyID = oidctest.KeyID
+			}
+
+			// every sign in gets own code, so parallel tests don't share id tokens
+			code := testCode + " " + test.name
+			stub.SetCode(code, stub.SignIDToken(t, claims, keyID))
+
+			if test.noCode {
+				code = ""
+			}
+
+			state := login.State
+			if test.state != "" {
+				state = test.state
+			}
+
+			user, err := service.FinishOIDCLogin(ctx, test.provider, code, state, login)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("FinishOIDCLogin() err = %v, want %v", err, test.wantErr)
+			}
+
+			calls := storage.recorded()
+
+			if test.wantErr != nil {
+				if len(calls) != 0 {
+					t.Errorf("storage calls = %+v after error, want none", calls)
+				}
+
+				return
+			}
+
+			if len(calls) != 1 || calls[0].method != "SignInByIdentity" {
+				t.Fatalf("storage calls = %+v, want SignInByIdentity", calls)
+			}
+
+			identity := calls[0].identity
+			if identity.Issuer != stub.Issuer() || identity.Subject != testSubject || !identity.EmailVerified {
+				t.Errorf("signed in identity = %+v", identity)
+			}
+
+			if user.Email != testSubject+"@example.com" {
+				t.Errorf("user email = %s, want %s", user.Email, testSubject+"@example.com")
+			}
+		})
+	}
+}
+
+func TestFinishOIDCLoginWithoutStartedLogin(t *testing.T) {
+	t.Parallel()
+
+	service, _ := newTestOIDCService(t, oidctest.NewProvider(t))
+
+	for _, login := range []*models.OIDCLogin{nil, {}} { //nolint:exhaustruct
+		_, err := service.FinishOIDCLogin(context.Background(), oidctest.Name, testCode, "", login)
+		if !errors.Is(err, ErrOIDCState) {
+			t.Errorf("FinishOIDCLogin(login=%+v) err = %v, want %v", login, err, ErrOIDCState)
+		}
+	}
+}
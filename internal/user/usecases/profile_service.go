@@ -0,0 +1,152 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"github.com/SanExpett/film-library-backend/pkg/mailer"
+	"github.com/SanExpett/film-library-backend/pkg/models"
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+	"github.com/SanExpett/film-library-backend/pkg/tracing"
+	"github.com/SanExpett/film-library-backend/pkg/utils"
+	"io"
+	"time"
+)
+
+func (u *UserService) GetProfile(ctx context.Context, userID uint64) (*models.Profile, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetProfile")
+	defer span.End()
+
+	profile, err := u.storage.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	profile.Sanitize()
+
+	return profile, nil
+}
+
+func (u *UserService) UpdateProfile(ctx context.Context, r io.Reader, isPartialUpdate bool, userID uint64) error {
+	ctx, span := tracing.Start(ctx, "UserService.UpdateProfile")
+	defer span.End()
+
+	var preProfile *models.ProfileWithoutID
+
+	var err error
+
+	if isPartialUpdate {
+		preProfile, err = ValidatePartOfProfile(r)
+	} else {
+		preProfile, err = ValidateProfile(r)
+	}
+
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	updateFieldsMap := utils.StructToMap(preProfile)
+
+	err = u.storage.UpdateProfile(ctx, userID, updateFieldsMap)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+// ChangePassword keeps current session, other sessions of user are revoked.
+func (u *UserService) ChangePassword(ctx context.Context, r io.Reader, userID uint64, sessionID uint64) error {
+	ctx, span := tracing.Start(ctx, "UserService.ChangePassword")
+	defer span.End()
+
+	passwordChange, err := ValidatePasswordChange(r)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	newPasswordHash, err := utils.HashPass(passwordChange.NewPassword)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	err = u.storage.ChangePassword(ctx, userID, passwordChange.CurrentPassword, newPasswordHash, sessionID)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+// RequestEmailChange sends confirmation link to new email, email is changed only after it is opened.
+func (u *UserService) RequestEmailChange(ctx context.Context, r io.Reader, userID uint64) error {
+	ctx, span := tracing.Start(ctx, "UserService.RequestEmailChange")
+	defer span.End()
+
+	emailChange, err := ValidateEmailChange(r)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	token, tokenHash, err := newSecretToken()
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	expiresAt := time.Now().Add(u.options.EmailVerificationTokenLife)
+
+	err = u.storage.RequestEmailChange(ctx, userID, emailChange.Password, emailChange.Email, tokenHash, expiresAt)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	err = u.mailer.Send(ctx, &mailer.Message{
+		To:      emailChange.Email,
+		Subject: subjectEmailChange,
+		Body: fmt.Sprintf(bodyEmailChange, u.newLink(pathChangeEmail, token),
+			expiresAt.UTC().Format(time.RFC1123)),
+	})
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+func (u *UserService) ConfirmEmailChange(ctx context.Context, r io.Reader) error {
+	ctx, span := tracing.Start(ctx, "UserService.ConfirmEmailChange")
+	defer span.End()
+
+	tokenConfirm, err := ValidateUserTokenConfirm(r)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	tokenHash, err := utils.Hash256([]byte(tokenConfirm.Token))
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	err = u.storage.ConfirmEmailChange(ctx, tokenHash)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+func (u *UserService) DeleteAccount(ctx context.Context, r io.Reader, userID uint64) error {
+	ctx, span := tracing.Start(ctx, "UserService.DeleteAccount")
+	defer span.End()
+
+	accountDeletion, err := ValidateAccountDeletion(r)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	err = u.storage.DeleteAccount(ctx, userID, accountDeletion.Password)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/SanExpett/film-library-backend/pkg/models"
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+	"github.com/SanExpett/film-library-backend/pkg/tracing"
+	"github.com/SanExpett/film-library-backend/pkg/utils"
+	"time"
+)
+
+const lenSecretToken = 32
+
+var ErrEmptyRefreshToken = myerrors.New(myerrors.KindUnauthorized, "refresh_token_empty", "Отсутствует refresh токен")
+
+// newSecretToken returns random token for client and its hash for storage, so leaked database
+// doesn't give working tokens.
+func newSecretToken() (string, string, error) {
+	rawToken := make([]byte, lenSecretToken)
+
+	_, err := rand.Read(rawToken)
+	if err != nil {
+		return "", "", fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	token := hex.EncodeToString(rawToken)
+
+	tokenHash, err := utils.Hash256([]byte(token))
+	if err != nil {
+		return "", "", fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return token, tokenHash, nil
+}
+
+func (u *UserService) newPreSession(userID uint64, userAgent string, ip string,
+) (*models.SessionWithoutID, string, error) {
+	refreshToken, refreshTokenHash, err := newSecretToken()
+	if err != nil {
+		u.logger.Errorln(err)
+
+		return nil, "", fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	preSession := &models.SessionWithoutID{
+		UserID:           userID,
+		RefreshTokenHash: refreshTokenHash,
+		UserAgent:        userAgent,
+		IP:               ip,
+		ExpiresAt:        time.Now().Add(u.options.RefreshTokenLife),
+	}
+
+	preSession.Trim()
+
+	return preSession, refreshToken, nil
+}
+
+func (u *UserService) CreateSession(ctx context.Context, user *models.UserWithoutPassword,
+	userAgent string, ip string,
+) (*models.AuthSession, error) {
+	ctx, span := tracing.Start(ctx, "UserService.CreateSession")
+	defer span.End()
+
+	preSession, refreshToken, err := u.newPreSession(user.ID, userAgent, ip)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	session, err := u.storage.CreateSession(ctx, preSession)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return &models.AuthSession{Session: session, User: user, RefreshToken: refreshToken}, nil
+}
+
+// RefreshSession rotates refresh token: old one can't be used anymore, reusing it revokes session.
+func (u *UserService) RefreshSession(ctx context.Context, refreshToken string, userAgent string, ip string,
+) (*models.AuthSession, error) {
+	ctx, span := tracing.Start(ctx, "UserService.RefreshSession")
+	defer span.End()
+
+	if refreshToken == "" {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrEmptyRefreshToken)
+	}
+
+	refreshTokenHash, err := utils.Hash256([]byte(refreshToken))
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	preSession, rotatedRefreshToken, err := u.newPreSession(0, userAgent, ip)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	authSession, err := u.storage.RotateSession(ctx, refreshTokenHash, preSession)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	authSession.RefreshToken = rotatedRefreshToken
+
+	return authSession, nil
+}
+
+func (u *UserService) LogOut(ctx context.Context, sessionID uint64, userID uint64) error {
+	ctx, span := tracing.Start(ctx, "UserService.LogOut")
+	defer span.End()
+
+	err := u.storage.RevokeSession(ctx, sessionID, userID)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+func (u *UserService) LogOutByRefreshToken(ctx context.Context, refreshToken string) error {
+	ctx, span := tracing.Start(ctx, "UserService.LogOutByRefreshToken")
+	defer span.End()
+
+	if refreshToken == "" {
+		return fmt.Errorf(myerrors.ErrTemplate, ErrEmptyRefreshToken)
+	}
+
+	refreshTokenHash, err := utils.Hash256([]byte(refreshToken))
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	err = u.storage.RevokeSessionByRefreshToken(ctx, refreshTokenHash)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+func (u *UserService) LogOutAll(ctx context.Context, userID uint64) error {
+	ctx, span := tracing.Start(ctx, "UserService.LogOutAll")
+	defer span.End()
+
+	err := u.storage.RevokeAllSessions(ctx, userID)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+func (u *UserService) GetSessions(ctx context.Context, userID uint64, currentSessionID uint64,
+) ([]*models.Session, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetSessions")
+	defer span.End()
+
+	sessions, err := u.storage.GetActiveSessions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	for _, session := range sessions {
+		session.IsCurrent = session.ID == currentSessionID
+		session.Sanitize()
+	}
+
+	return sessions, nil
+}
@@ -2,38 +2,110 @@ package usecases
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	serverusecases "github.com/SanExpett/film-library-backend/internal/server/usecases"
 	userrepo "github.com/SanExpett/film-library-backend/internal/user/repository"
+	"github.com/SanExpett/film-library-backend/pkg/mailer"
+	"github.com/SanExpett/film-library-backend/pkg/metrics"
 	"github.com/SanExpett/film-library-backend/pkg/models"
 	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
 	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"github.com/SanExpett/film-library-backend/pkg/tracing"
 	"github.com/SanExpett/film-library-backend/pkg/utils"
 	"go.uber.org/zap"
 	"io"
+	"strings"
+	"time"
 )
 
-var _ IUserStorage = (*userrepo.UserStorage)(nil)
+var (
+	_ IUserStorage = (*userrepo.UserStorage)(nil)
+	_ IPolicy      = (*serverusecases.Policy)(nil)
+)
 
 type IUserStorage interface {
 	AddUser(ctx context.Context, preUser *models.UserWithoutID) (*models.User, error)
 	GetUser(ctx context.Context, email string, password string) (*models.UserWithoutPassword, error)
+	GrantRole(ctx context.Context, userID uint64, role models.Role) error
+	RevokeRole(ctx context.Context, userID uint64, role models.Role) error
+	GetUserRoles(ctx context.Context, userID uint64) ([]models.Role, error)
+	GetRolesList(ctx context.Context) ([]*models.RoleWithPermissions, error)
+	GetUsersList(ctx context.Context, limit uint64, offset uint64) ([]*models.UserWithRoles, error)
+	SearchUsersByEmail(ctx context.Context, searchedEmail string, limit uint64, offset uint64,
+	) ([]*models.UserWithRoles, error)
+	SuspendUser(ctx context.Context, userID uint64) error
+	UnsuspendUser(ctx context.Context, userID uint64) error
+	DeleteUser(ctx context.Context, userID uint64, reassignToID uint64) error
+	GetUserEmail(ctx context.Context, userID uint64) (string, error)
+	CreatePasswordResetToken(ctx context.Context, email string, tokenHash string, expiresAt time.Time) error
+	ResetPassword(ctx context.Context, tokenHash string, passwordHash string) error
+	CreateEmailVerificationToken(ctx context.Context, userID uint64, tokenHash string, expiresAt time.Time,
+	) (string, error)
+	VerifyEmail(ctx context.Context, tokenHash string) error
+	GetProfile(ctx context.Context, userID uint64) (*models.Profile, error)
+	UpdateProfile(ctx context.Context, userID uint64, updateFields map[string]interface{}) error
+	ChangePassword(ctx context.Context, userID uint64, currentPassword string, newPasswordHash string,
+		currentSessionID uint64) error
+	RequestEmailChange(ctx context.Context, userID uint64, password string, newEmail string, tokenHash string,
+		expiresAt time.Time) error
+	ConfirmEmailChange(ctx context.Context, tokenHash string) error
+	DeleteAccount(ctx context.Context, userID uint64, password string) error
+	CreateSession(ctx context.Context, preSession *models.SessionWithoutID) (*models.Session, error)
+	RotateSession(ctx context.Context, refreshTokenHash string, preSession *models.SessionWithoutID,
+	) (*models.AuthSession, error)
+	RevokeSession(ctx context.Context, sessionID uint64, userID uint64) error
+	RevokeSessionByRefreshToken(ctx context.Context, refreshTokenHash string) error
+	RevokeAllSessions(ctx context.Context, userID uint64) error
+	GetActiveSessions(ctx context.Context, userID uint64) ([]*models.Session, error)
+	CreateAPIKey(ctx context.Context, preAPIKey *models.PreAPIKey) (*models.APIKey, error)
+	GetAPIKeys(ctx context.Context, userID uint64) ([]*models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, apiKeyID uint64, userID uint64) error
+	SignInByIdentity(ctx context.Context, identity *models.ExternalIdentity, passwordHash string,
+	) (*models.UserWithoutPassword, error)
+}
+
+type IPolicy interface {
+	Check(ctx context.Context, userID uint64, permission models.Permission) error
+}
+
+// UserServiceOptions are lifetimes of tokens issued by UserService. AppURL is address of frontend,
+// links from emails lead there. OIDCProviders are identity providers for sign in by name.
+type UserServiceOptions struct {
+	RefreshTokenLife           time.Duration
+	PasswordResetTokenLife     time.Duration
+	EmailVerificationTokenLife time.Duration
+	AppURL                     string
+	OIDCProviders              map[string]IOIDCProvider
 }
 
 type UserService struct {
-	storage IUserStorage
-	logger  *zap.SugaredLogger
+	storage      IUserStorage
+	policy       IPolicy
+	loginLimiter *LoginLimiter
+	mailer       mailer.Mailer
+	options      *UserServiceOptions
+	logger       *zap.SugaredLogger
 }
 
-func NewUserService(userStorage IUserStorage) (*UserService, error) {
+func NewUserService(userStorage IUserStorage, policy IPolicy, loginLimiter *LoginLimiter, userMailer mailer.Mailer,
+	options *UserServiceOptions,
+) (*UserService, error) {
 	logger, err := my_logger.Get()
 	if err != nil {
 		return nil, err
 	}
 
-	return &UserService{storage: userStorage, logger: logger}, nil
+	return &UserService{
+		storage: userStorage, policy: policy, loginLimiter: loginLimiter, mailer: userMailer, options: options,
+		logger: logger,
+	}, nil
 }
 
 func (u *UserService) AddUser(ctx context.Context, r io.Reader) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.AddUser")
+	defer span.End()
+
 	userWithoutID, err := ValidateUserWithoutID(r)
 	if err != nil {
 		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
@@ -49,21 +121,294 @@ func (u *UserService) AddUser(ctx context.Context, r io.Reader) (*models.User, e
 		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
 	}
 
+	metrics.SignUps.Inc()
+
+	// user is already created, so failed email doesn't fail sign up, link can be requested again
+	err = u.sendEmailVerification(ctx, user.ID)
+	if err != nil {
+		u.logger.Errorln(err)
+	}
+
 	return user, nil
 }
 
-func (u *UserService) GetUser(ctx context.Context, email string, password string) (*models.UserWithoutPassword, error) {
-	userWithoutID, err := ValidateUserCredentials(email, password)
+// signIn checks credentials unless account or ip is locked. Wrong email or password counts as failed attempt.
+func (u *UserService) signIn(ctx context.Context, credentials *models.UserWithoutID, ip string,
+) (*models.UserWithoutPassword, error) {
+	err := u.loginLimiter.Check(ctx, credentials.Email, ip)
+	if errors.Is(err, ErrTooManySignInAttempts) {
+		metrics.SignInFailures.WithLabelValues(metrics.SignInFailureLocked).Inc()
+	}
+
 	if err != nil {
 		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
 	}
 
-	user, err := u.storage.GetUser(ctx, userWithoutID.Email, userWithoutID.Password)
+	user, err := u.storage.GetUser(ctx, credentials.Email, credentials.Password)
+	if errors.Is(err, userrepo.ErrInvalidCredentials) {
+		metrics.SignInFailures.WithLabelValues(metrics.SignInFailureWrongCredentials).Inc()
+
+		errRegister := u.loginLimiter.RegisterFailure(ctx, credentials.Email, ip)
+		if errRegister != nil {
+			u.logger.Errorln(errRegister)
+		}
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
 	if err != nil {
 		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
 	}
 
+	err = u.loginLimiter.RegisterSuccess(ctx, credentials.Email)
+	if err != nil {
+		u.logger.Errorln(err)
+	}
+
 	user.Sanitize()
 
 	return user, nil
 }
+
+func (u *UserService) SignIn(ctx context.Context, r io.Reader, ip string) (*models.UserWithoutPassword, error) {
+	ctx, span := tracing.Start(ctx, "UserService.SignIn")
+	defer span.End()
+
+	credentials, err := ValidateSignInCredentials(r)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return u.signIn(ctx, credentials, ip)
+}
+
+func (u *UserService) GetUser(ctx context.Context, email string, password string, ip string,
+) (*models.UserWithoutPassword, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUser")
+	defer span.End()
+
+	userWithoutID, err := ValidateUserCredentials(email, password)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return u.signIn(ctx, userWithoutID, ip)
+}
+
+func (u *UserService) GrantRole(ctx context.Context, r io.Reader, userID uint64) error {
+	ctx, span := tracing.Start(ctx, "UserService.GrantRole")
+	defer span.End()
+
+	err := u.policy.Check(ctx, userID, models.PermissionRoleManage)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	userRole, err := ValidateUserRole(r)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	err = u.storage.GrantRole(ctx, userRole.UserID, models.Role(userRole.Role))
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+func (u *UserService) RevokeRole(ctx context.Context, targetUserID uint64, role string, userID uint64) error {
+	ctx, span := tracing.Start(ctx, "UserService.RevokeRole")
+	defer span.End()
+
+	err := u.policy.Check(ctx, userID, models.PermissionRoleManage)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	userRole, err := ValidateUserRoleParams(targetUserID, role)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	// admin can't take away own admin role, otherwise the last admin could lock everyone out
+	if userRole.UserID == userID && models.Role(userRole.Role) == models.RoleAdmin {
+		return fmt.Errorf(myerrors.ErrTemplate, ErrRevokeOwnAdminRole)
+	}
+
+	err = u.storage.RevokeRole(ctx, userRole.UserID, models.Role(userRole.Role))
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+func (u *UserService) GetUserRoles(ctx context.Context, targetUserID uint64, userID uint64) ([]models.Role, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUserRoles")
+	defer span.End()
+
+	if targetUserID != userID {
+		err := u.policy.Check(ctx, userID, models.PermissionRoleManage)
+		if err != nil {
+			return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+	}
+
+	roles, err := u.storage.GetUserRoles(ctx, targetUserID)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return roles, nil
+}
+
+func (u *UserService) GetRolesList(ctx context.Context) ([]*models.RoleWithPermissions, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetRolesList")
+	defer span.End()
+
+	roles, err := u.storage.GetRolesList(ctx)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return roles, nil
+}
+
+func (u *UserService) GetUsersList(ctx context.Context, limit uint64, offset uint64, userID uint64,
+) ([]*models.UserWithRoles, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUsersList")
+	defer span.End()
+
+	err := u.policy.Check(ctx, userID, models.PermissionUserManage)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	users, err := u.storage.GetUsersList(ctx, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	for _, user := range users {
+		user.Sanitize()
+	}
+
+	return users, nil
+}
+
+func (u *UserService) SearchUsersByEmail(ctx context.Context, searchedEmail string, limit uint64, offset uint64,
+	userID uint64,
+) ([]*models.UserWithRoles, error) {
+	ctx, span := tracing.Start(ctx, "UserService.SearchUsersByEmail")
+	defer span.End()
+
+	err := u.policy.Check(ctx, userID, models.PermissionUserManage)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	users, err := u.storage.SearchUsersByEmail(ctx, strings.TrimSpace(searchedEmail), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	for _, user := range users {
+		user.Sanitize()
+	}
+
+	return users, nil
+}
+
+func (u *UserService) SuspendUser(ctx context.Context, targetUserID uint64, userID uint64) error {
+	ctx, span := tracing.Start(ctx, "UserService.SuspendUser")
+	defer span.End()
+
+	err := u.policy.Check(ctx, userID, models.PermissionUserManage)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	if targetUserID == userID {
+		return fmt.Errorf(myerrors.ErrTemplate, ErrSuspendYourself)
+	}
+
+	err = u.storage.SuspendUser(ctx, targetUserID)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+func (u *UserService) UnsuspendUser(ctx context.Context, targetUserID uint64, userID uint64) error {
+	ctx, span := tracing.Start(ctx, "UserService.UnsuspendUser")
+	defer span.End()
+
+	err := u.policy.Check(ctx, userID, models.PermissionUserManage)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	err = u.storage.UnsuspendUser(ctx, targetUserID)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+// DeleteUser removes user. Films and actors of deleted user are handed over to reassignToID,
+// if it is zero they are handed over to user who deletes.
+func (u *UserService) DeleteUser(ctx context.Context, targetUserID uint64, reassignToID uint64, userID uint64,
+) error {
+	ctx, span := tracing.Start(ctx, "UserService.DeleteUser")
+	defer span.End()
+
+	err := u.policy.Check(ctx, userID, models.PermissionUserManage)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	if targetUserID == userID {
+		return fmt.Errorf(myerrors.ErrTemplate, ErrDeleteYourself)
+	}
+
+	if reassignToID == 0 {
+		reassignToID = userID
+	}
+
+	if reassignToID == targetUserID {
+		return fmt.Errorf(myerrors.ErrTemplate, ErrReassignToDeleted)
+	}
+
+	err = u.storage.DeleteUser(ctx, targetUserID, reassignToID)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+// UnlockUser removes sign in lock of account before it expires. Locks of ip are not affected.
+func (u *UserService) UnlockUser(ctx context.Context, targetUserID uint64, userID uint64) error {
+	ctx, span := tracing.Start(ctx, "UserService.UnlockUser")
+	defer span.End()
+
+	err := u.policy.Check(ctx, userID, models.PermissionUserManage)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	email, err := u.storage.GetUserEmail(ctx, targetUserID)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	err = u.loginLimiter.UnlockAccount(ctx, email, userID)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
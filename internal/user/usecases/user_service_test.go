@@ -0,0 +1,114 @@
+package usecases
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SanExpett/film-library-backend/pkg/mailer"
+	"github.com/SanExpett/film-library-backend/pkg/models"
+)
+
+const (
+	testAppURL = "https://films.example.com"
+	testUserID = 1
+)
+
+// storageCall is call of UserService to storage with arguments that tests check.
+type storageCall struct {
+	method       string
+	email        string
+	userID       uint64
+	tokenHash    string
+	passwordHash string
+	expiresAt    time.Time
+	identity     *models.ExternalIdentity
+}
+
+// fakeUserStorage records calls of UserService and returns err set by test. It has no logic of its own:
+// expiration and single use of tokens are enforced by sql of UserStorage, not by service.
+// Methods which are not faked panic on nil embedded IUserStorage.
+type fakeUserStorage struct {
+	IUserStorage
+
+	mu    sync.Mutex
+	err   error
+	calls []storageCall
+}
+
+func (f *fakeUserStorage) record(call storageCall) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, call)
+
+	return f.err
+}
+
+func (f *fakeUserStorage) recorded() []storageCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]storageCall(nil), f.calls...)
+}
+
+func (f *fakeUserStorage) CreatePasswordResetToken(_ context.Context, email string, tokenHash string,
+	expiresAt time.Time,
+) error {
+	return f.record(storageCall{method: "CreatePasswordResetToken", email: email, tokenHash: tokenHash, //nolint:exhaustruct
+		expiresAt: expiresAt})
+}
+
+func (f *fakeUserStorage) ResetPassword(_ context.Context, tokenHash string, passwordHash string) error {
+	return f.record(storageCall{method: "ResetPassword", tokenHash: tokenHash, passwordHash: passwordHash}) //nolint:exhaustruct
+}
+
+// CreateEmailVerificationToken returns email of user as storage does, it is always testEmail.
+func (f *fakeUserStorage) CreateEmailVerificationToken(_ context.Context, userID uint64, tokenHash string,
+	expiresAt time.Time,
+) (string, error) {
+	err := f.record(storageCall{method: "CreateEmailVerificationToken", userID: userID, tokenHash: tokenHash, //nolint:exhaustruct
+		expiresAt: expiresAt})
+	if err != nil {
+		return "", err
+	}
+
+	return testEmail, nil
+}
+
+func (f *fakeUserStorage) VerifyEmail(_ context.Context, tokenHash string) error {
+	return f.record(storageCall{method: "VerifyEmail", tokenHash: tokenHash}) //nolint:exhaustruct
+}
+
+// newTestUserService returns service with fake storage and mailer which saves mails to returned directory.
+func newTestUserService(t *testing.T, options *UserServiceOptions) (*UserService, *fakeUserStorage, string) {
+	t.Helper()
+
+	mailDir := t.TempDir()
+
+	logMailer, err := mailer.NewLogMailer(mailDir, "noreply@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	storage := new(fakeUserStorage)
+
+	service, err := NewUserService(storage, nil, nil, logMailer, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return service, storage, mailDir
+}
+
+// SignInByIdentity returns user with email of identity as storage does for new account.
+func (f *fakeUserStorage) SignInByIdentity(_ context.Context, identity *models.ExternalIdentity, _ string,
+) (*models.UserWithoutPassword, error) {
+	err := f.record(storageCall{method: "SignInByIdentity", identity: identity}) //nolint:exhaustruct
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.UserWithoutPassword{ID: testUserID, Email: identity.Email}, nil //nolint:exhaustruct
+}
@@ -0,0 +1,159 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	userrepo "github.com/SanExpett/film-library-backend/internal/user/repository"
+	"github.com/SanExpett/film-library-backend/pkg/mailer"
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+	"github.com/SanExpett/film-library-backend/pkg/tracing"
+	"github.com/SanExpett/film-library-backend/pkg/utils"
+	"io"
+	"net/url"
+	"time"
+)
+
+const (
+	pathResetPassword = "/reset_password"
+	pathVerifyEmail   = "/verify_email"
+	pathChangeEmail   = "/confirm_email_change"
+
+	subjectPasswordReset     = "Восстановление пароля"
+	subjectEmailVerification = "Подтверждение email"
+	bodyPasswordReset        = "Чтобы задать новый пароль, перейдите по ссылке: %s\n" +
+		"Ссылка действует до %s. Если вы не запрашивали восстановление пароля, просто проигнорируйте это письмо.\n"
+	bodyEmailVerification = "Чтобы подтвердить email, перейдите по ссылке: %s\nСсылка действует до %s.\n"
+	subjectEmailChange    = "Смена email"
+	bodyEmailChange       = "Чтобы сменить email аккаунта на этот, перейдите по ссылке: %s\n" +
+		"Ссылка действует до %s. Если вы не меняли email, просто проигнорируйте это письмо.\n"
+)
+
+func (u *UserService) newLink(path string, token string) string {
+	return u.options.AppURL + path + "?" + url.Values{"token": {token}}.Encode()
+}
+
+// RequestPasswordReset sends link for password reset. Unknown and suspended emails are not reported,
+// otherwise this endpoint would tell who is registered.
+func (u *UserService) RequestPasswordReset(ctx context.Context, r io.Reader) error {
+	ctx, span := tracing.Start(ctx, "UserService.RequestPasswordReset")
+	defer span.End()
+
+	resetRequest, err := ValidatePasswordResetRequest(r)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	token, tokenHash, err := newSecretToken()
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	expiresAt := time.Now().Add(u.options.PasswordResetTokenLife)
+
+	err = u.storage.CreatePasswordResetToken(ctx, resetRequest.Email, tokenHash, expiresAt)
+	if errors.Is(err, userrepo.ErrEmailNotExist) || errors.Is(err, userrepo.ErrUserSuspended) {
+		u.logger.Infof("in RequestPasswordReset: no reset for %s: %s", resetRequest.Email, err.Error())
+
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	err = u.mailer.Send(ctx, &mailer.Message{
+		To:      resetRequest.Email,
+		Subject: subjectPasswordReset,
+		Body: fmt.Sprintf(bodyPasswordReset, u.newLink(pathResetPassword, token),
+			expiresAt.UTC().Format(time.RFC1123)),
+	})
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+func (u *UserService) ConfirmPasswordReset(ctx context.Context, r io.Reader) error {
+	ctx, span := tracing.Start(ctx, "UserService.ConfirmPasswordReset")
+	defer span.End()
+
+	resetConfirm, err := ValidatePasswordResetConfirm(r)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	tokenHash, err := utils.Hash256([]byte(resetConfirm.Token))
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	passwordHash, err := utils.HashPass(resetConfirm.Password)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	err = u.storage.ResetPassword(ctx, tokenHash, passwordHash)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+func (u *UserService) sendEmailVerification(ctx context.Context, userID uint64) error {
+	token, tokenHash, err := newSecretToken()
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	expiresAt := time.Now().Add(u.options.EmailVerificationTokenLife)
+
+	email, err := u.storage.CreateEmailVerificationToken(ctx, userID, tokenHash, expiresAt)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	err = u.mailer.Send(ctx, &mailer.Message{
+		To:      email,
+		Subject: subjectEmailVerification,
+		Body: fmt.Sprintf(bodyEmailVerification, u.newLink(pathVerifyEmail, token),
+			expiresAt.UTC().Format(time.RFC1123)),
+	})
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+// RequestEmailVerification sends new verification link, links sent earlier stop working.
+func (u *UserService) RequestEmailVerification(ctx context.Context, userID uint64) error {
+	ctx, span := tracing.Start(ctx, "UserService.RequestEmailVerification")
+	defer span.End()
+
+	return u.sendEmailVerification(ctx, userID)
+}
+
+func (u *UserService) ConfirmEmailVerification(ctx context.Context, r io.Reader) error {
+	ctx, span := tracing.Start(ctx, "UserService.ConfirmEmailVerification")
+	defer span.End()
+
+	verificationConfirm, err := ValidateUserTokenConfirm(r)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	tokenHash, err := utils.Hash256([]byte(verificationConfirm.Token))
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	err = u.storage.VerifyEmail(ctx, tokenHash)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
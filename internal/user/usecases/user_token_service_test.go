@@ -0,0 +1,273 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	userrepo "github.com/SanExpett/film-library-backend/internal/user/repository"
+	"github.com/SanExpett/film-library-backend/pkg/utils"
+)
+
+const (
+	testTokenLife   = time.Hour
+	testNewPassword = "new-password"
+)
+
+//nolint:gochecknoglobals
+var linkRegexp = regexp.MustCompile(`https?://\S+`)
+
+func newTestTokenService(t *testing.T) (*UserService, *fakeUserStorage, string) {
+	t.Helper()
+
+	return newTestUserService(t, &UserServiceOptions{ //nolint:exhaustruct
+		PasswordResetTokenLife:     testTokenLife,
+		EmailVerificationTokenLife: testTokenLife,
+		AppURL:                     testAppURL,
+	})
+}
+
+// readMails returns messages saved by LogMailer in order they were sent.
+func readMails(t *testing.T, mailDir string) []string {
+	t.Helper()
+
+	paths, err := filepath.Glob(filepath.Join(mailDir, "*.eml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mails := make([]string, 0, len(paths))
+
+	for _, path := range paths {
+		rawMail, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		mails = append(mails, string(rawMail))
+	}
+
+	return mails
+}
+
+// tokenFromMail checks that the only link of mail leads to path of frontend and returns token from it.
+func tokenFromMail(t *testing.T, mail string, wantTo string, wantPath string) string {
+	t.Helper()
+
+	if !strings.Contains(mail, "To: "+wantTo+"\r\n") {
+		t.Fatalf("mail is not sent to %s:\n%s", wantTo, mail)
+	}
+
+	links := linkRegexp.FindAllString(mail, -1)
+	if len(links) != 1 {
+		t.Fatalf("mail has %d links, want 1:\n%s", len(links), mail)
+	}
+
+	link, err := url.Parse(links[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if gotPrefix := link.Scheme + "://" + link.Host + link.Path; gotPrefix != testAppURL+wantPath {
+		t.Fatalf("link leads to %s, want %s", gotPrefix, testAppURL+wantPath)
+	}
+
+	token := link.Query().Get("token")
+	if token == "" {
+		t.Fatalf("link %s has no token", link)
+	}
+
+	return token
+}
+
+// checkCreatedToken checks that storage got hash of token from mail and expiration after token life.
+func checkCreatedToken(t *testing.T, call storageCall, token string, requestedAt time.Time) {
+	t.Helper()
+
+	wantHash, err := utils.Hash256([]byte(token))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if call.tokenHash != wantHash {
+		t.Errorf("%s got hash %s, want hash of token from mail %s", call.method, call.tokenHash, wantHash)
+	}
+
+	if call.expiresAt.Before(requestedAt.Add(testTokenLife)) || call.expiresAt.After(time.Now().Add(testTokenLife)) {
+		t.Errorf("%s got expiration %s, want %s after request", call.method, call.expiresAt, testTokenLife)
+	}
+}
+
+func TestRequestPasswordReset(t *testing.T) {
+	t.Parallel()
+
+	service, storage, mailDir := newTestTokenService(t)
+	requestedAt := time.Now()
+
+	err := service.RequestPasswordReset(context.Background(), strings.NewReader(`{"email": "`+testEmail+`"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mails := readMails(t, mailDir)
+	if len(mails) != 1 {
+		t.Fatalf("sent %d mails, want 1", len(mails))
+	}
+
+	calls := storage.recorded()
+	if len(calls) != 1 || calls[0].method != "CreatePasswordResetToken" || calls[0].email != testEmail {
+		t.Fatalf("storage calls = %+v, want CreatePasswordResetToken for %s", calls, testEmail)
+	}
+
+	checkCreatedToken(t, calls[0], tokenFromMail(t, mails[0], testEmail, pathResetPassword), requestedAt)
+}
+
+func TestRequestPasswordResetNotSent(t *testing.T) {
+	t.Parallel()
+
+	errStorage := errors.New("storage is down") //nolint:goerr113
+
+	tests := []struct {
+		name       string
+		storageErr error
+		wantErr    error
+	}{
+		{name: "unknown email is not reported", storageErr: userrepo.ErrEmailNotExist, wantErr: nil},
+		{name: "suspended user is not reported", storageErr: userrepo.ErrUserSuspended, wantErr: nil},
+		{name: "error of storage", storageErr: errStorage, wantErr: errStorage},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			service, storage, mailDir := newTestTokenService(t)
+			storage.err = test.storageErr
+
+			err := service.RequestPasswordReset(context.Background(), strings.NewReader(`{"email": "`+testEmail+`"}`))
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("err = %v, want %v", err, test.wantErr)
+			}
+
+			if mails := readMails(t, mailDir); len(mails) != 0 {
+				t.Errorf("sent %d mails, want 0", len(mails))
+			}
+		})
+	}
+}
+
+func TestConfirmPasswordReset(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		storageErr error
+	}{
+		{name: "password is reset", storageErr: nil},
+		{name: "token rejected by storage", storageErr: userrepo.ErrUserTokenInvalid},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			service, storage, _ := newTestTokenService(t)
+			storage.err = test.storageErr
+
+			err := service.ConfirmPasswordReset(context.Background(),
+				strings.NewReader(`{"token": "token", "password": "`+testNewPassword+`"}`))
+			if !errors.Is(err, test.storageErr) {
+				t.Fatalf("err = %v, want %v", err, test.storageErr)
+			}
+
+			wantHash, err := utils.Hash256([]byte("token"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			calls := storage.recorded()
+			if len(calls) != 1 || calls[0].method != "ResetPassword" || calls[0].tokenHash != wantHash {
+				t.Fatalf("storage calls = %+v, want ResetPassword with hash %s", calls, wantHash)
+			}
+
+			if isMatch, _ := utils.ComparePassAndHash(calls[0].passwordHash, testNewPassword); !isMatch {
+				t.Error("storage got hash of other password")
+			}
+		})
+	}
+}
+
+func TestConfirmPasswordResetInvalidBody(t *testing.T) {
+	t.Parallel()
+
+	for _, body := range []string{
+		`{"password": "` + testNewPassword + `"}`,
+		`{"token": "token", "password": "short"}`,
+		`{"token": `,
+	} {
+		service, storage, _ := newTestTokenService(t)
+
+		err := service.ConfirmPasswordReset(context.Background(), strings.NewReader(body))
+		if err == nil {
+			t.Errorf("body %s is accepted", body)
+		}
+
+		if calls := storage.recorded(); len(calls) != 0 {
+			t.Errorf("body %s: storage calls = %+v, want none", body, calls)
+		}
+	}
+}
+
+func TestEmailVerification(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	service, storage, mailDir := newTestTokenService(t)
+	requestedAt := time.Now()
+
+	err := service.RequestEmailVerification(ctx, testUserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mails := readMails(t, mailDir)
+	if len(mails) != 1 {
+		t.Fatalf("sent %d mails, want 1", len(mails))
+	}
+
+	token := tokenFromMail(t, mails[0], testEmail, pathVerifyEmail)
+
+	calls := storage.recorded()
+	if len(calls) != 1 || calls[0].method != "CreateEmailVerificationToken" || calls[0].userID != testUserID {
+		t.Fatalf("storage calls = %+v, want CreateEmailVerificationToken for user %d", calls, testUserID)
+	}
+
+	checkCreatedToken(t, calls[0], token, requestedAt)
+
+	err = service.ConfirmEmailVerification(ctx, strings.NewReader(`{"token": "`+token+`"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls = storage.recorded()
+	if len(calls) != 2 || calls[1].method != "VerifyEmail" || calls[1].tokenHash != calls[0].tokenHash {
+		t.Fatalf("storage calls = %+v, want VerifyEmail with hash %s", calls, calls[0].tokenHash)
+	}
+
+	storage.err = userrepo.ErrUserTokenInvalid
+
+	err = service.ConfirmEmailVerification(ctx, strings.NewReader(`{"token": "`+token+`"}`))
+	if !errors.Is(err, userrepo.ErrUserTokenInvalid) {
+		t.Errorf("err of token rejected by storage = %v, want %v", err, userrepo.ErrUserTokenInvalid)
+	}
+}
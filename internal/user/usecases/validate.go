@@ -11,8 +11,21 @@ import (
 )
 
 var (
-	ErrWrongCredentials = myerrors.NewError("Некорректный логин или пароль")
-	ErrDecodeUser       = myerrors.NewError("Некорректный json пользователя")
+	ErrWrongCredentials = myerrors.New(myerrors.KindUnauthorized, "wrong_credentials",
+		"Некорректный логин или пароль")
+	ErrDecodeUser     = myerrors.New(myerrors.KindBadRequest, "user_json_invalid", "Некорректный json пользователя")
+	ErrDecodeUserRole = myerrors.New(myerrors.KindBadRequest, "user_role_json_invalid",
+		"Некорректный json роли пользователя")
+	ErrRevokeOwnAdminRole = myerrors.New(myerrors.KindForbidden, "revoke_own_admin_role",
+		"Нельзя снять роль администратора с самого себя")
+	ErrSuspendYourself   = myerrors.New(myerrors.KindForbidden, "suspend_yourself", "Нельзя заблокировать самого себя")
+	ErrDeleteYourself    = myerrors.New(myerrors.KindForbidden, "delete_yourself", "Нельзя удалить самого себя")
+	ErrReassignToDeleted = myerrors.New(myerrors.KindValidation, "reassign_to_deleted",
+		"Нельзя передать фильмы и актеров удаляемому пользователю")
+	ErrDecodeUserToken = myerrors.New(myerrors.KindBadRequest, "user_token_json_invalid",
+		"Некорректный json запроса")
+	ErrDecodeProfile = myerrors.New(myerrors.KindBadRequest, "profile_json_invalid", "Некорректный json профиля")
+	ErrDecodeAPIKey  = myerrors.New(myerrors.KindBadRequest, "api_key_json_invalid", "Некорректный json api ключа")
 )
 
 func validateUserWithoutID(r io.Reader) (*models.UserWithoutID, error) {
@@ -44,10 +57,14 @@ func ValidateUserWithoutID(r io.Reader) (*models.UserWithoutID, error) {
 	}
 
 	userWithoutID, err := validateUserWithoutID(r)
+	if userWithoutID == nil {
+		return nil, err
+	}
+
 	if err != nil {
 		logger.Errorln(err)
 
-		return nil, myerrors.NewError(err.Error())
+		return nil, myerrors.NewValidationErrorFrom(err)
 	}
 
 	return userWithoutID, nil
@@ -64,7 +81,6 @@ func ValidateUserCredentials(email string, password string) (*models.UserWithout
 	userWithoutID.Email = email
 	userWithoutID.Password = password
 	userWithoutID.Trim()
-	logger.Infoln(userWithoutID)
 
 	_, err = govalidator.ValidateStruct(userWithoutID)
 	if err != nil && (govalidator.ErrorByField(err, "email") != "" ||
@@ -76,3 +92,213 @@ func ValidateUserCredentials(email string, password string) (*models.UserWithout
 
 	return userWithoutID, nil
 }
+
+func ValidateUserRole(r io.Reader) (*models.UserRole, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	decoder := json.NewDecoder(r)
+
+	userRole := new(models.UserRole)
+	if err := decoder.Decode(userRole); err != nil {
+		logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrDecodeUserRole)
+	}
+
+	userRole.Trim()
+
+	_, err = govalidator.ValidateStruct(userRole)
+	if err != nil {
+		logger.Errorln(err)
+
+		return nil, myerrors.NewValidationErrorFrom(err)
+	}
+
+	return userRole, nil
+}
+
+func ValidateUserRoleParams(userID uint64, role string) (*models.UserRole, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	userRole := &models.UserRole{UserID: userID, Role: role}
+	userRole.Trim()
+
+	_, err = govalidator.ValidateStruct(userRole)
+	if err != nil {
+		logger.Errorln(err)
+
+		return nil, myerrors.NewValidationErrorFrom(err)
+	}
+
+	return userRole, nil
+}
+
+// ValidateSignInCredentials decodes credentials from json body. Any problem with email or password
+// is reported as wrong credentials, so it doesn't tell whether such email exists.
+func ValidateSignInCredentials(r io.Reader) (*models.UserWithoutID, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	decoder := json.NewDecoder(r)
+
+	credentials := new(models.UserWithoutID)
+	if err := decoder.Decode(credentials); err != nil {
+		logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrDecodeUser)
+	}
+
+	return ValidateUserCredentials(credentials.Email, credentials.Password)
+}
+
+func decodeAndValidate(r io.Reader, dst interface{ Trim() }, errDecode error) error {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	decoder := json.NewDecoder(r)
+	if err := decoder.Decode(dst); err != nil {
+		logger.Errorln(err)
+
+		return fmt.Errorf(myerrors.ErrTemplate, errDecode)
+	}
+
+	dst.Trim()
+
+	_, err = govalidator.ValidateStruct(dst)
+	if err != nil {
+		logger.Errorln(err)
+
+		return myerrors.NewValidationErrorFrom(err)
+	}
+
+	return nil
+}
+
+func ValidatePasswordResetRequest(r io.Reader) (*models.PasswordResetRequest, error) {
+	resetRequest := new(models.PasswordResetRequest)
+
+	err := decodeAndValidate(r, resetRequest, ErrDecodeUserToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return resetRequest, nil
+}
+
+func ValidatePasswordResetConfirm(r io.Reader) (*models.PasswordResetConfirm, error) {
+	resetConfirm := new(models.PasswordResetConfirm)
+
+	err := decodeAndValidate(r, resetConfirm, ErrDecodeUserToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return resetConfirm, nil
+}
+
+func ValidateUserTokenConfirm(r io.Reader) (*models.UserTokenConfirm, error) {
+	tokenConfirm := new(models.UserTokenConfirm)
+
+	err := decodeAndValidate(r, tokenConfirm, ErrDecodeUserToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return tokenConfirm, nil
+}
+
+func ValidateProfile(r io.Reader) (*models.ProfileWithoutID, error) {
+	preProfile := new(models.ProfileWithoutID)
+
+	err := decodeAndValidate(r, preProfile, ErrDecodeProfile)
+	if err != nil {
+		return nil, err
+	}
+
+	return preProfile, nil
+}
+
+// ValidatePartOfProfile allows missing fields, only present ones are validated.
+func ValidatePartOfProfile(r io.Reader) (*models.ProfileWithoutID, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	decoder := json.NewDecoder(r)
+
+	preProfile := new(models.ProfileWithoutID)
+	if err := decoder.Decode(preProfile); err != nil {
+		logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrDecodeProfile)
+	}
+
+	preProfile.Trim()
+
+	_, err = govalidator.ValidateStruct(preProfile)
+	if err != nil {
+		fields := myerrors.ValidationFields(err, myerrors.ValidatorRequired)
+		if len(fields) != 0 {
+			logger.Errorln(err)
+
+			return nil, myerrors.NewValidationError(fields...)
+		}
+	}
+
+	return preProfile, nil
+}
+
+func ValidatePasswordChange(r io.Reader) (*models.PasswordChange, error) {
+	passwordChange := new(models.PasswordChange)
+
+	err := decodeAndValidate(r, passwordChange, ErrDecodeProfile)
+	if err != nil {
+		return nil, err
+	}
+
+	return passwordChange, nil
+}
+
+func ValidateEmailChange(r io.Reader) (*models.EmailChange, error) {
+	emailChange := new(models.EmailChange)
+
+	err := decodeAndValidate(r, emailChange, ErrDecodeProfile)
+	if err != nil {
+		return nil, err
+	}
+
+	return emailChange, nil
+}
+
+func ValidateAccountDeletion(r io.Reader) (*models.AccountDeletion, error) {
+	accountDeletion := new(models.AccountDeletion)
+
+	err := decodeAndValidate(r, accountDeletion, ErrDecodeProfile)
+	if err != nil {
+		return nil, err
+	}
+
+	return accountDeletion, nil
+}
+
+func ValidateAPIKeyWithoutID(r io.Reader) (*models.APIKeyWithoutID, error) {
+	preAPIKey := new(models.APIKeyWithoutID)
+
+	err := decodeAndValidate(r, preAPIKey, ErrDecodeAPIKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return preAPIKey, nil
+}
@@ -0,0 +1,51 @@
+// Package buildinfo keeps version of running binary. Commit and BuildTime are set at build:
+//
+//	go build -ldflags "-X github.com/SanExpett/film-library-backend/pkg/buildinfo.Commit=$(git rev-parse HEAD)
+//	-X github.com/SanExpett/film-library-backend/pkg/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Without ldflags Commit is taken from vcs info embedded by go build, if it is there.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+const unknown = "unknown"
+
+var (
+	Commit    = "" //nolint:gochecknoglobals
+	BuildTime = "" //nolint:gochecknoglobals
+)
+
+type Info struct {
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+func Get() *Info {
+	info := &Info{
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+
+	if buildInfo, ok := debug.ReadBuildInfo(); ok && info.Commit == "" {
+		for _, setting := range buildInfo.Settings {
+			if setting.Key == "vcs.revision" {
+				info.Commit = setting.Value
+			}
+		}
+	}
+
+	if info.Commit == "" {
+		info.Commit = unknown
+	}
+
+	if info.BuildTime == "" {
+		info.BuildTime = unknown
+	}
+
+	return info
+}
@@ -1,6 +1,17 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	bitsUint8  = 8
+	bitsUint32 = 32
+	bitsUint64 = 64
+)
 
 const (
 	standardAllowOrigin        = "localhost:3000"
@@ -10,6 +21,40 @@ const (
 	standardPathToRoot         = "."
 	standardOutputLogPath      = "stdout /var/log/backend/logs.json"
 	standardErrorOutputLogPath = "stderr /var/log/backend/err_logs.json"
+	standardJwtIssuer          = "film-library-backend"
+	standardJwtAudience        = "film-library"
+	standardJwtLeeway          = 30 * time.Second
+	standardJwtTokenLife       = 15 * time.Minute
+	standardRefreshTokenLife   = 30 * 24 * time.Hour
+	standardAllowLegacySignIn  = false
+	standardCookieDomain       = ""
+	standardCookieSecure       = false
+	standardCookieSameSite     = "lax"
+	standardLoginAttemptStore  = LoginAttemptStorePostgres
+	standardLoginMaxAttempts   = 5
+	standardLoginMaxPerIP      = 50
+	standardLoginLockBase      = time.Minute
+	standardLoginLockMax       = time.Hour
+	standardLoginWindow        = 24 * time.Hour
+	standardAppURL             = "http://localhost:3000"
+	standardResetTokenLife     = time.Hour
+	standardVerifyTokenLife    = 24 * time.Hour
+	standardMailer             = "log"
+	standardMailFrom           = "no-reply@localhost"
+	standardSMTPHost           = "localhost"
+	standardSMTPPort           = "25"
+	standardMailDir            = ""
+	standardPassHashTime       = 1
+	standardPassHashMemory     = 64 * 1024
+	standardPassHashThreads    = 4
+	standardRequestTimeout     = 5 * time.Second
+	standardShutdownDelay      = 5 * time.Second
+	standardShutdownGrace      = 20 * time.Second
+	standardTracingExporter    = "none"
+	standardTracingEndpoint    = "localhost:4318"
+	standardTracingInsecure    = true
+	standardTracingService     = "film-library-backend"
+	standardTracingSampleRatio = 1.0
 
 	envAllowOrigin        = "ALLOW_ORIGIN"
 	envSchema             = "SCHEMA"
@@ -18,8 +63,71 @@ const (
 	envPathToRoot         = "PATH_TO_ROOT"
 	envOutputLogPath      = "OUTPUT_LOG_PATH"
 	envErrorOutputLogPath = "ERROR_OUTPUT_LOG_PATH"
+	envJwtKeys            = "JWT_KEYS"
+	envJwtIssuer          = "JWT_ISSUER"
+	envJwtAudience        = "JWT_AUDIENCE"
+	envJwtLeeway          = "JWT_LEEWAY"
+	envJwtTokenLife       = "JWT_TOKEN_LIFE"
+	envRefreshTokenLife   = "REFRESH_TOKEN_LIFE"
+	envAllowLegacySignIn  = "ALLOW_LEGACY_SIGNIN"
+	envCookieDomain       = "COOKIE_DOMAIN"
+	envCookieSecure       = "COOKIE_SECURE"
+	envCookieSameSite     = "COOKIE_SAMESITE"
+	envLoginAttemptStore  = "LOGIN_ATTEMPT_STORE"
+	envLoginMaxAttempts   = "LOGIN_MAX_ATTEMPTS"
+	envLoginMaxPerIP      = "LOGIN_MAX_ATTEMPTS_PER_IP"
+	envLoginLockBase      = "LOGIN_LOCK_BASE"
+	envLoginLockMax       = "LOGIN_LOCK_MAX"
+	envLoginWindow        = "LOGIN_ATTEMPTS_WINDOW"
+	envAppURL             = "APP_URL"
+	envResetTokenLife     = "PASSWORD_RESET_TOKEN_LIFE"
+	envVerifyTokenLife    = "EMAIL_VERIFICATION_TOKEN_LIFE"
+	envMailer             = "MAILER"
+	envMailFrom           = "MAIL_FROM"
+	envSMTPHost           = "SMTP_HOST"
+	envSMTPPort           = "SMTP_PORT"
+	envSMTPUser           = "SMTP_USER"
+	envSMTPPassword       = "SMTP_PASSWORD"
+	envMailDir            = "MAIL_DIR"
+	envPassHashTime       = "PASSWORD_HASH_TIME"
+	envPassHashMemory     = "PASSWORD_HASH_MEMORY"
+	envPassHashThreads    = "PASSWORD_HASH_THREADS"
+	envOIDCProviders      = "OIDC_PROVIDERS"
+	envRequestTimeout     = "REQUEST_TIMEOUT"
+	envRouteTimeouts      = "REQUEST_ROUTE_TIMEOUTS"
+	envShutdownDelay      = "SHUTDOWN_DELAY"
+	envShutdownGrace      = "SHUTDOWN_GRACE_PERIOD"
+	envTracingExporter    = "TRACING_EXPORTER"
+	envTracingEndpoint    = "TRACING_OTLP_ENDPOINT"
+	envTracingInsecure    = "TRACING_OTLP_INSECURE"
+	envTracingService     = "TRACING_SERVICE_NAME"
+	envTracingSampleRatio = "TRACING_SAMPLE_RATIO"
+
+	// settings of every oidc provider are read from OIDC_<NAME>_<SUFFIX>
+	envOIDCPrefix             = "OIDC_"
+	envOIDCSuffixIssuer       = "_ISSUER"
+	envOIDCSuffixClientID     = "_CLIENT_ID"
+	envOIDCSuffixClientSecret = "_CLIENT_SECRET"
+	envOIDCSuffixRedirectURL  = "_REDIRECT_URL"
+	envOIDCSuffixScopes       = "_SCOPES"
 )
 
+const (
+	LoginAttemptStorePostgres = "postgres"
+	LoginAttemptStoreMemory   = "memory"
+)
+
+// OIDCProvider is identity provider for sign in, RedirectURL is /api/v1/oidc/callback of this server
+// registered at provider. Empty Scopes mean openid, email and profile.
+type OIDCProvider struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
 type Config struct {
 	AllowOrigin        string
 	Schema             string
@@ -28,17 +136,125 @@ type Config struct {
 	PathToRoot         string
 	OutputLogPath      string
 	ErrorOutputLogPath string
+	// JwtKeys is space separated list of kid=alg:source, the first key signs new tokens.
+	// alg is HS256, RS256 or EdDSA, source is secret for HS256 and path to PEM private key otherwise.
+	// It is required, server doesn't start without it
+	JwtKeys     string
+	JwtIssuer   string
+	JwtAudience string
+	// JwtLeeway is allowed clock skew when exp, nbf and iat of token are checked
+	JwtLeeway time.Duration
+	// JwtTokenLife is lifetime of access token, RefreshTokenLife is lifetime of session
+	JwtTokenLife     time.Duration
+	RefreshTokenLife time.Duration
+	// AllowLegacySignIn keeps deprecated GET /signin with credentials in query string
+	AllowLegacySignIn bool
+	// CookieDomain, CookieSecure and CookieSameSite (lax, strict or none) are set for every cookie,
+	// CookieSecure has to be enabled when server is behind https
+	CookieDomain   string
+	CookieSecure   bool
+	CookieSameSite string
+	// LoginAttemptStore is postgres or memory. After LoginMaxAttempts failed sign in attempts in a row
+	// (LoginMaxAttemptsPerIP for ip) sign in is locked for LoginLockBase, every next failure doubles lock
+	// up to LoginLockMax. Failures older than LoginAttemptsWindow are forgotten
+	LoginAttemptStore     string
+	LoginMaxAttempts      uint64
+	LoginMaxAttemptsPerIP uint64
+	LoginLockBase         time.Duration
+	LoginLockMax          time.Duration
+	LoginAttemptsWindow   time.Duration
+	// AppURL is address of frontend, links from emails lead there
+	AppURL                     string
+	PasswordResetTokenLife     time.Duration
+	EmailVerificationTokenLife time.Duration
+	// Mailer is smtp or log. log mailer only writes emails to log and to MailDir if it is set
+	Mailer       string
+	MailFrom     string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUser     string
+	SMTPPassword string
+	MailDir      string
+	// PassHashTime, PassHashMemory (KiB) and PassHashThreads are argon2id parameters of new password hashes,
+	// hashes with other parameters are replaced on next sign in. Values out of range of argon2id
+	// parameters are replaced by default ones, zero values are rejected by utils.SetPassHashParams
+	PassHashTime    uint32
+	PassHashMemory  uint32
+	PassHashThreads uint8
+	// OIDCProviders are listed by names in OIDC_PROVIDERS, settings of provider are taken
+	// from OIDC_<NAME>_ISSUER, OIDC_<NAME>_CLIENT_ID, OIDC_<NAME>_CLIENT_SECRET, OIDC_<NAME>_REDIRECT_URL
+	// and OIDC_<NAME>_SCOPES
+	OIDCProviders []OIDCProvider
+	// RequestTimeout is deadline of every request, RouteTimeouts override it for routes.
+	// They are read from REQUEST_ROUTE_TIMEOUTS as space separated list of route=duration
+	RequestTimeout time.Duration
+	RouteTimeouts  map[string]time.Duration
+	// SkippedRouteTimeouts are malformed pairs of REQUEST_ROUTE_TIMEOUTS, server logs them on start
+	SkippedRouteTimeouts []string
+	// On SIGTERM server reports not ready and keeps serving for ShutdownDelay, so orchestrator notices it,
+	// then stops accepting connections and waits ShutdownGracePeriod for in-flight requests
+	ShutdownDelay       time.Duration
+	ShutdownGracePeriod time.Duration
+	// TracingExporter is none, stdout or otlp. otlp exporter sends spans over http to TracingOTLPEndpoint
+	// (host:port), TracingSampleRatio of new traces is recorded, traces started by caller follow its decision
+	TracingExporter     string
+	TracingOTLPEndpoint string
+	TracingOTLPInsecure bool
+	TracingServiceName  string
+	TracingSampleRatio  float64
 }
 
 func New() *Config {
+	routeTimeouts, skippedRouteTimeouts := getRouteTimeouts()
+
 	return &Config{
-		AllowOrigin:        getEnvStr(envAllowOrigin, standardAllowOrigin),
-		Schema:             getEnvStr(envSchema, standardSchema),
-		PortServer:         getEnvStr(envPortBackend, standardPort),
-		URLDataBase:        getEnvStr(envURLDataBase, standardURLDataBase),
-		PathToRoot:         getEnvStr(envPathToRoot, standardPathToRoot),
-		OutputLogPath:      getEnvStr(envOutputLogPath, standardOutputLogPath),
-		ErrorOutputLogPath: getEnvStr(envErrorOutputLogPath, standardErrorOutputLogPath),
+		AllowOrigin:                getEnvStr(envAllowOrigin, standardAllowOrigin),
+		Schema:                     getEnvStr(envSchema, standardSchema),
+		PortServer:                 getEnvStr(envPortBackend, standardPort),
+		URLDataBase:                getEnvStr(envURLDataBase, standardURLDataBase),
+		PathToRoot:                 getEnvStr(envPathToRoot, standardPathToRoot),
+		OutputLogPath:              getEnvStr(envOutputLogPath, standardOutputLogPath),
+		ErrorOutputLogPath:         getEnvStr(envErrorOutputLogPath, standardErrorOutputLogPath),
+		JwtKeys:                    getEnvStr(envJwtKeys, ""),
+		JwtIssuer:                  getEnvStr(envJwtIssuer, standardJwtIssuer),
+		JwtAudience:                getEnvStr(envJwtAudience, standardJwtAudience),
+		JwtLeeway:                  getEnvDuration(envJwtLeeway, standardJwtLeeway),
+		JwtTokenLife:               getEnvDuration(envJwtTokenLife, standardJwtTokenLife),
+		RefreshTokenLife:           getEnvDuration(envRefreshTokenLife, standardRefreshTokenLife),
+		AllowLegacySignIn:          getEnvBool(envAllowLegacySignIn, standardAllowLegacySignIn),
+		CookieDomain:               getEnvStr(envCookieDomain, standardCookieDomain),
+		CookieSecure:               getEnvBool(envCookieSecure, standardCookieSecure),
+		CookieSameSite:             getEnvStr(envCookieSameSite, standardCookieSameSite),
+		LoginAttemptStore:          getEnvStr(envLoginAttemptStore, standardLoginAttemptStore),
+		LoginMaxAttempts:           getEnvUint(envLoginMaxAttempts, standardLoginMaxAttempts),
+		LoginMaxAttemptsPerIP:      getEnvUint(envLoginMaxPerIP, standardLoginMaxPerIP),
+		LoginLockBase:              getEnvDuration(envLoginLockBase, standardLoginLockBase),
+		LoginLockMax:               getEnvDuration(envLoginLockMax, standardLoginLockMax),
+		LoginAttemptsWindow:        getEnvDuration(envLoginWindow, standardLoginWindow),
+		AppURL:                     getEnvStr(envAppURL, standardAppURL),
+		PasswordResetTokenLife:     getEnvDuration(envResetTokenLife, standardResetTokenLife),
+		EmailVerificationTokenLife: getEnvDuration(envVerifyTokenLife, standardVerifyTokenLife),
+		Mailer:                     getEnvStr(envMailer, standardMailer),
+		MailFrom:                   getEnvStr(envMailFrom, standardMailFrom),
+		SMTPHost:                   getEnvStr(envSMTPHost, standardSMTPHost),
+		SMTPPort:                   getEnvStr(envSMTPPort, standardSMTPPort),
+		SMTPUser:                   getEnvStr(envSMTPUser, ""),
+		SMTPPassword:               getEnvStr(envSMTPPassword, ""),
+		MailDir:                    getEnvStr(envMailDir, standardMailDir),
+		PassHashTime:               uint32(getEnvUintSized(envPassHashTime, standardPassHashTime, bitsUint32)),
+		PassHashMemory:             uint32(getEnvUintSized(envPassHashMemory, standardPassHashMemory, bitsUint32)),
+		PassHashThreads:            uint8(getEnvUintSized(envPassHashThreads, standardPassHashThreads, bitsUint8)),
+		OIDCProviders:              getOIDCProviders(),
+		RequestTimeout:             getEnvDuration(envRequestTimeout, standardRequestTimeout),
+		RouteTimeouts:              routeTimeouts,
+		SkippedRouteTimeouts:       skippedRouteTimeouts,
+		ShutdownDelay:              getEnvDuration(envShutdownDelay, standardShutdownDelay),
+		ShutdownGracePeriod:        getEnvDuration(envShutdownGrace, standardShutdownGrace),
+		TracingExporter:            getEnvStr(envTracingExporter, standardTracingExporter),
+		TracingOTLPEndpoint:        getEnvStr(envTracingEndpoint, standardTracingEndpoint),
+		TracingOTLPInsecure:        getEnvBool(envTracingInsecure, standardTracingInsecure),
+		TracingServiceName:         getEnvStr(envTracingService, standardTracingService),
+		TracingSampleRatio:         getEnvFloat(envTracingSampleRatio, standardTracingSampleRatio),
 	}
 }
 
@@ -50,3 +266,113 @@ func getEnvStr(name string, defaultValue string) string {
 
 	return result
 }
+
+func getEnvDuration(name string, defaultValue time.Duration) time.Duration {
+	rawResult, ok := os.LookupEnv(name)
+	if !ok {
+		return defaultValue
+	}
+
+	result, err := time.ParseDuration(rawResult)
+	if err != nil {
+		return defaultValue
+	}
+
+	return result
+}
+
+func getEnvBool(name string, defaultValue bool) bool {
+	rawResult, ok := os.LookupEnv(name)
+	if !ok {
+		return defaultValue
+	}
+
+	result, err := strconv.ParseBool(rawResult)
+	if err != nil {
+		return defaultValue
+	}
+
+	return result
+}
+
+func getEnvUint(name string, defaultValue uint64) uint64 {
+	return getEnvUintSized(name, defaultValue, bitsUint64)
+}
+
+// getEnvUintSized returns value which fits into bitSize bits, so it can be converted to smaller uint type.
+func getEnvUintSized(name string, defaultValue uint64, bitSize int) uint64 {
+	rawResult, ok := os.LookupEnv(name)
+	if !ok {
+		return defaultValue
+	}
+
+	result, err := strconv.ParseUint(rawResult, 10, bitSize)
+	if err != nil {
+		return defaultValue
+	}
+
+	return result
+}
+
+func getEnvFloat(name string, defaultValue float64) float64 {
+	rawResult, ok := os.LookupEnv(name)
+	if !ok {
+		return defaultValue
+	}
+
+	result, err := strconv.ParseFloat(rawResult, 64)
+	if err != nil {
+		return defaultValue
+	}
+
+	return result
+}
+
+func getOIDCProviders() []OIDCProvider {
+	names := strings.Fields(getEnvStr(envOIDCProviders, ""))
+	providers := make([]OIDCProvider, 0, len(names))
+
+	for _, name := range names {
+		prefix := envOIDCPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+
+		providers = append(providers, OIDCProvider{
+			Name:         name,
+			Issuer:       getEnvStr(prefix+envOIDCSuffixIssuer, ""),
+			ClientID:     getEnvStr(prefix+envOIDCSuffixClientID, ""),
+			ClientSecret: getEnvStr(prefix+envOIDCSuffixClientSecret, ""),
+			RedirectURL:  getEnvStr(prefix+envOIDCSuffixRedirectURL, ""),
+			Scopes:       strings.Fields(getEnvStr(prefix+envOIDCSuffixScopes, "")),
+		})
+	}
+
+	return providers
+}
+
+// getRouteTimeouts parses route=duration pairs, pairs with invalid duration are skipped and returned
+// separately, so they can be logged.
+func getRouteTimeouts() (map[string]time.Duration, []string) {
+	pairs := strings.Fields(getEnvStr(envRouteTimeouts, ""))
+	timeouts := make(map[string]time.Duration, len(pairs))
+
+	var skipped []string
+
+	for _, pair := range pairs {
+		route, rawTimeout, found := strings.Cut(pair, "=")
+		if !found {
+			skipped = append(skipped, pair)
+
+			continue
+		}
+
+		timeout, err := time.ParseDuration(rawTimeout)
+		if err != nil || timeout <= 0 {
+			skipped = append(skipped, pair)
+
+			continue
+		}
+
+		timeouts[route] = timeout
+	}
+
+	return timeouts, skipped
+}
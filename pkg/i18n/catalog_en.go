@@ -0,0 +1,113 @@
+package i18n
+
+var catalogEn = map[string]string{ //nolint:gochecknoglobals
+	"internal_error":     "Internal server error",
+	"request_timeout":    "Request took too long, try again later",
+	"validation_failed":  "Invalid fields",
+	"wrong_number_param": "Got invalid numeric parameter, it must be an integer %s=%s",
+
+	"unauthorized":          "You are not signed in",
+	"forbidden":             "Not enough permissions for this action",
+	"admin_only":            "Action is available only to administrator",
+	"session_only":          "Action is not available by api key, sign in to account",
+	"principal_not_found":   "User is not found or suspended",
+	"cookie_not_presented":  "Cookie must be set, but it is missing",
+	"csrf_token_invalid":    "Invalid csrf token",
+	"session_revoked":       "Session is finished, sign in again",
+	"session_not_found":     "Session is not found or already finished",
+	"refresh_token_empty":   "Refresh token is missing",
+	"refresh_token_reused":  "Refresh token was already used, session is finished",
+	"token_nil":             "Got token = nil",
+	"token_signing_method":  "Unexpected signing method",
+	"token_invalid":         "Invalid token",
+	"token_expired":         "Token is expired",
+	"token_not_valid_yet":   "Token is not valid yet",
+	"api_key_malformed":     "Authorization header must look like: ApiKey <key>",
+	"api_key_invalid":       "Api key is invalid",
+	"api_key_scope_denied":  "Api key doesn't allow this action",
+	"api_key_scope_unknown": "Unknown permission in scopes of api key",
+
+	"api_key_expires_at_invalid": "Expiration of api key must be in the future",
+	"api_key_not_found":          "Api key is not found or already revoked",
+	"api_key_json_invalid":       "Invalid json of api key",
+
+	"wrong_credentials":         "Wrong login or password",
+	"wrong_password":            "Wrong password",
+	"invalid_credentials":       "Invalid email or password",
+	"too_many_sign_in_attempts": "Too many failed sign in attempts, sign in is temporarily locked",
+	"user_suspended":            "User account is suspended",
+	"user_json_invalid":         "Invalid json of user",
+	"user_role_json_invalid":    "Invalid json of user role",
+	"user_token_json_invalid":   "Invalid json of request",
+	"profile_json_invalid":      "Invalid json of profile",
+	"user_not_exist":            "User doesn't exist",
+	"user_no_update_fields":     "There are no fields to update",
+	"user_not_updated":          "Failed to update user data",
+	"user_token_invalid":        "Link is invalid or expired",
+	"email_busy":                "Email is already taken",
+	"email_not_exist":           "Email doesn't exist",
+	"email_not_changed":         "New email is the same as current",
+	"email_already_verified":    "Email is already verified",
+	"phone_busy":                "Phone is already taken",
+	"role_not_exist":            "Role doesn't exist",
+	"user_already_has_role":     "User already has this role",
+	"user_has_no_role":          "User doesn't have this role",
+	"revoke_own_admin_role":     "You can't revoke administrator role from yourself",
+	"suspend_yourself":          "You can't suspend yourself",
+	"delete_yourself":           "You can't delete yourself",
+	"user_already_suspended":    "User is already suspended",
+	"user_not_suspended":        "User is not suspended",
+	"delete_last_admin":         "You can't delete the only administrator",
+	"no_admin_to_reassign":      "There is nobody to take over your films and actors, contact administrator",
+	"reassign_to_deleted":       "Films and actors can't be passed to the deleted user",
+	"reassign_to_not_exist":     "User to take over films and actors doesn't exist",
+
+	"oidc_provider_unknown":     "Unknown oidc provider",
+	"oidc_state_invalid":        "Invalid state of oidc sign in, start sign in again",
+	"oidc_no_code":              "Oidc provider didn't pass authorization code",
+	"oidc_denied":               "Sign in by oidc provider is canceled",
+	"oidc_no_email":             "Oidc provider didn't pass email of user",
+	"oidc_email_not_verified":   "Email is not verified by oidc provider, verify it there or sign in by password",
+	"oidc_id_token_invalid":     "Invalid id token of oidc provider",
+	"oidc_id_token_unknown_key": "Id token is signed by unknown key",
+	"oidc_exchange_code":        "Oidc provider didn't accept authorization code",
+	"oidc_discovery":            "Failed to get configuration of oidc provider",
+
+	"film_json_invalid":       "Invalid json of film",
+	"film_actor_json_invalid": "Invalid json of actor in cast of film",
+	"new_actor_json_invalid":  "Invalid json of new actor",
+	"duplicate_actor_in_film": "Actor is set in cast of film several times",
+	"film_not_found":          "Film is not found",
+	"film_not_exist":          "Film doesn't exist",
+	"film_no_update_fields":   "There are no fields of film to update",
+	"film_not_updated":        "Failed to update film data",
+	"actor_json_invalid":      "Invalid json of actor",
+	"actor_film_json_invalid": "Invalid json of film in filmography of actor",
+	"duplicate_film_in_actor": "Film is set in filmography of actor several times",
+	"actor_not_found":         "Actor is not found",
+	"actor_not_exist":         "Actor doesn't exist",
+	"actor_no_update_fields":  "There are no fields of actor to update",
+	"actor_not_updated":       "Failed to update actor data",
+	"actor_already_in_film":   "Actor is already in cast of film",
+	"actor_not_in_film":       "Actor is not in cast of film",
+
+	"field.required": "Field is required",
+	"field.length":   "Invalid length",
+	"field.range":    "Value is out of range",
+	"field.in":       "Value is not allowed",
+	"field.email":    "Not valid email",
+	"field.httpurl":  "Not valid url",
+	"field.password": "Password must be at least 6 symbols",
+
+	"field.title.length":          "Title length must be from 1 to 150",
+	"field.description.length":    "Description length must be from 1 to 1000",
+	"field.rating.range":          "Rating must be from 0 to 10",
+	"field.character_name.length": "Character name length must be from 1 to 256",
+	"field.gender.in":             "Gender must be male, female or other",
+	"field.display_name.length":   "Display name must be from 1 to 64 symbols",
+	"field.avatar_url.length":     "Avatar url must be at most 512 symbols",
+	"field.avatar_url.httpurl":    "Avatar url must be http or https url",
+	"field.name.length":           "Name must be from 1 to 64 symbols",
+	"field.locale.in":             "Locale must be ru or en",
+	"field.role.in":               "Unknown role",
+}
@@ -0,0 +1,113 @@
+package i18n
+
+var catalogRu = map[string]string{ //nolint:gochecknoglobals
+	"internal_error":     "Ошибка на сервере",
+	"request_timeout":    "Запрос выполнялся слишком долго, попробуйте позже",
+	"validation_failed":  "Некорректные поля",
+	"wrong_number_param": "Получили некорректный числовой параметр. Он должен быть целым %s=%s",
+
+	"unauthorized":          "Вы не авторизованы",
+	"forbidden":             "Недостаточно прав для выполнения этого действия",
+	"admin_only":            "Действие доступно только администратору",
+	"session_only":          "Действие недоступно по api ключу, войдите в аккаунт",
+	"principal_not_found":   "Пользователь не найден или заблокирован",
+	"cookie_not_presented":  "Должна быть выставлена cookie, а её нет",
+	"csrf_token_invalid":    "Некорректный csrf токен",
+	"session_revoked":       "Сессия завершена, войдите заново",
+	"session_not_found":     "Сессия не найдена или уже завершена",
+	"refresh_token_empty":   "Отсутствует refresh токен",
+	"refresh_token_reused":  "Refresh токен уже был использован, сессия завершена",
+	"token_nil":             "Получили токен = nil",
+	"token_signing_method":  "Неожиданный signing метод",
+	"token_invalid":         "Некорректный токен",
+	"token_expired":         "Срок действия токена истек",
+	"token_not_valid_yet":   "Токен еще не действителен",
+	"api_key_malformed":     "Заголовок Authorization должен иметь вид: ApiKey <ключ>",
+	"api_key_invalid":       "Api ключ недействителен",
+	"api_key_scope_denied":  "Api ключ не позволяет выполнить это действие",
+	"api_key_scope_unknown": "Неизвестное право в scopes api ключа",
+
+	"api_key_expires_at_invalid": "Срок действия api ключа должен быть в будущем",
+	"api_key_not_found":          "Api ключ не найден или уже отозван",
+	"api_key_json_invalid":       "Некорректный json api ключа",
+
+	"wrong_credentials":         "Некорректный логин или пароль",
+	"wrong_password":            "Некорректный пароль",
+	"invalid_credentials":       "Неверный email или пароль",
+	"too_many_sign_in_attempts": "Слишком много неудачных попыток входа, вход временно заблокирован",
+	"user_suspended":            "Аккаунт пользователя заблокирован",
+	"user_json_invalid":         "Некорректный json пользователя",
+	"user_role_json_invalid":    "Некорректный json роли пользователя",
+	"user_token_json_invalid":   "Некорректный json запроса",
+	"profile_json_invalid":      "Некорректный json профиля",
+	"user_not_exist":            "Такого пользователя не существует",
+	"user_no_update_fields":     "Вы пытаетесь обновить пустое количество полей",
+	"user_not_updated":          "Не получилось обновить данные пользователя",
+	"user_token_invalid":        "Ссылка недействительна или устарела",
+	"email_busy":                "Такой email уже занят",
+	"email_not_exist":           "Такой email не существует",
+	"email_not_changed":         "Новый email совпадает с текущим",
+	"email_already_verified":    "Email уже подтвержден",
+	"phone_busy":                "Такой телефон уже занят",
+	"role_not_exist":            "Такой роли не существует",
+	"user_already_has_role":     "У пользователя уже есть эта роль",
+	"user_has_no_role":          "У пользователя нет этой роли",
+	"revoke_own_admin_role":     "Нельзя снять роль администратора с самого себя",
+	"suspend_yourself":          "Нельзя заблокировать самого себя",
+	"delete_yourself":           "Нельзя удалить самого себя",
+	"user_already_suspended":    "Пользователь уже заблокирован",
+	"user_not_suspended":        "Пользователь не заблокирован",
+	"delete_last_admin":         "Нельзя удалить единственного администратора",
+	"no_admin_to_reassign":      "Некому передать ваши фильмы и актеров, обратитесь к администратору",
+	"reassign_to_deleted":       "Нельзя передать фильмы и актеров удаляемому пользователю",
+	"reassign_to_not_exist":     "Пользователя, которому передаются фильмы и актеры, не существует",
+
+	"oidc_provider_unknown":     "Неизвестный oidc провайдер",
+	"oidc_state_invalid":        "Некорректное состояние входа через oidc, начните вход заново",
+	"oidc_no_code":              "Oidc провайдер не передал код авторизации",
+	"oidc_denied":               "Вход через oidc провайдера отменен",
+	"oidc_no_email":             "Oidc провайдер не передал email пользователя",
+	"oidc_email_not_verified":   "Email не подтвержден у oidc провайдера, подтвердите его у провайдера или войдите паролем",
+	"oidc_id_token_invalid":     "Некорректный id токен oidc провайдера",
+	"oidc_id_token_unknown_key": "Id токен подписан неизвестным ключом",
+	"oidc_exchange_code":        "Oidc провайдер не принял код авторизации",
+	"oidc_discovery":            "Не удалось получить конфигурацию oidc провайдера",
+
+	"film_json_invalid":       "Некорректный json фильма",
+	"film_actor_json_invalid": "Некорректный json актера в составе фильма",
+	"new_actor_json_invalid":  "Некорректный json нового актера",
+	"duplicate_actor_in_film": "Актер указан в составе фильма несколько раз",
+	"film_not_found":          "Этот фильм не найден",
+	"film_not_exist":          "Такого фильма не существует",
+	"film_no_update_fields":   "Вы пытаетесь обновить пустое количество полей фильма",
+	"film_not_updated":        "Не получилось обновить данные фильма",
+	"actor_json_invalid":      "Некорректный json актера",
+	"actor_film_json_invalid": "Некорректный json фильма в фильмографии актера",
+	"duplicate_film_in_actor": "Фильм указан в фильмографии актера несколько раз",
+	"actor_not_found":         "Этот актер не найден",
+	"actor_not_exist":         "Такого актера не существует",
+	"actor_no_update_fields":  "Вы пытаетесь обновить пустое количество полей актера",
+	"actor_not_updated":       "Не получилось обновить данные актера",
+	"actor_already_in_film":   "Этот актер уже добавлен в состав фильма",
+	"actor_not_in_film":       "Этого актера нет в составе фильма",
+
+	"field.required": "Обязательное поле",
+	"field.length":   "Некорректная длина",
+	"field.range":    "Значение вне допустимого диапазона",
+	"field.in":       "Недопустимое значение",
+	"field.email":    "Некорректный email",
+	"field.httpurl":  "Некорректный url",
+	"field.password": "Пароль должен быть не короче 6 символов",
+
+	"field.title.length":          "Длина названия должна быть от 1 до 150 символов",
+	"field.description.length":    "Длина описания должна быть от 1 до 1000 символов",
+	"field.rating.range":          "Рейтинг должен быть от 0 до 10",
+	"field.character_name.length": "Длина имени персонажа должна быть от 1 до 256 символов",
+	"field.gender.in":             "Пол должен быть male, female или other",
+	"field.display_name.length":   "Отображаемое имя должно быть от 1 до 64 символов",
+	"field.avatar_url.length":     "Url аватара должен быть не длиннее 512 символов",
+	"field.avatar_url.httpurl":    "Url аватара должен быть http или https ссылкой",
+	"field.name.length":           "Название должно быть от 1 до 64 символов",
+	"field.locale.in":             "Язык должен быть ru или en",
+	"field.role.in":               "Неизвестная роль",
+}
@@ -0,0 +1,103 @@
+// Package i18n translates messages for client by stable keys. Key of error message is code of my_errors.Error,
+// key of message about field of request body is "field.<field>.<validator>" or "field.<validator>" for any field.
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type Lang string
+
+const (
+	LangRu Lang = "ru"
+	LangEn Lang = "en"
+
+	DefaultLang = LangRu
+
+	HeaderAcceptLanguage = "Accept-Language"
+)
+
+var catalogs = map[Lang]map[string]string{ //nolint:gochecknoglobals
+	LangRu: catalogRu,
+	LangEn: catalogEn,
+}
+
+// Parse returns supported language of tag, region is ignored: en-US is en.
+func Parse(tag string) (Lang, bool) {
+	primary, _, _ := strings.Cut(strings.TrimSpace(tag), "-")
+	lang := Lang(strings.ToLower(primary))
+
+	_, ok := catalogs[lang]
+
+	return lang, ok
+}
+
+// FromAcceptLanguage picks supported language with the highest weight from Accept-Language header,
+// DefaultLang is returned if there is no supported language.
+func FromAcceptLanguage(header string) Lang {
+	best, bestWeight := DefaultLang, 0.0
+
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+
+		weight := 1.0
+
+		if rawWeight, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsedWeight, err := strconv.ParseFloat(rawWeight, 64)
+			if err != nil {
+				continue
+			}
+
+			weight = parsedWeight
+		}
+
+		lang, ok := Parse(tag)
+		if ok && weight > bestWeight {
+			best, bestWeight = lang, weight
+		}
+	}
+
+	return best
+}
+
+type keyCtx string
+
+const langKey keyCtx = "lang"
+
+func WithLang(ctx context.Context, lang Lang) context.Context {
+	return context.WithValue(ctx, langKey, lang)
+}
+
+// FromContext returns language chosen for request, for example locale from profile of signed in user.
+func FromContext(ctx context.Context) (Lang, bool) {
+	lang, ok := ctx.Value(langKey).(Lang)
+
+	return lang, ok
+}
+
+// Message returns message of key in lang, args are put into message as into format of fmt.Sprintf.
+func Message(lang Lang, key string, args ...any) (string, bool) {
+	message, ok := catalogs[lang][key]
+	if !ok {
+		return "", false
+	}
+
+	if len(args) != 0 {
+		message = fmt.Sprintf(message, args...)
+	}
+
+	return message, true
+}
+
+// FieldMessage returns message about field which failed validator, message for field is preferred
+// over common message of validator.
+func FieldMessage(lang Lang, field string, validator string) (string, bool) {
+	if message, ok := Message(lang, "field."+field+"."+validator); ok {
+		return message, true
+	}
+
+	return Message(lang, "field."+validator)
+}
@@ -0,0 +1,61 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+)
+
+const (
+	keyTypeRSA   = "RSA"
+	keyTypeOKP   = "OKP"
+	curveEd25519 = "Ed25519"
+	keyUseSig    = "sig"
+)
+
+// JSONWebKey is public key in format of RFC 7517.
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JWKS returns public keys of set. HMAC secrets are never published.
+func (k *KeySet) JWKS() *JSONWebKeySet {
+	jwks := &JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(k.keys))}
+
+	for _, key := range k.keys {
+		switch publicKey := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwks.Keys = append(jwks.Keys, JSONWebKey{ //nolint:exhaustruct
+				KeyType:   keyTypeRSA,
+				KeyID:     key.ID,
+				Use:       keyUseSig,
+				Algorithm: key.Method.Alg(),
+				N:         base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+				E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			jwks.Keys = append(jwks.Keys, JSONWebKey{ //nolint:exhaustruct
+				KeyType:   keyTypeOKP,
+				KeyID:     key.ID,
+				Use:       keyUseSig,
+				Algorithm: key.Method.Alg(),
+				Curve:     curveEd25519,
+				X:         base64.RawURLEncoding.EncodeToString(publicKey),
+			})
+		}
+	}
+
+	return jwks
+}
@@ -1,95 +1,159 @@
 package jwt
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"strconv"
+	"time"
+
 	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
 	"github.com/SanExpett/film-library-backend/pkg/my_logger"
 	"github.com/golang-jwt/jwt/v5"
 	"go.uber.org/zap"
 )
 
-var Secret = []byte("super-secret")
+const lenTokenID = 16
 
 var (
-	ErrNilToken           = myerrors.NewError("Получили токен = nil")
-	ErrWrongSigningMethod = myerrors.NewError("Неожиданный signing метод ")
-	ErrInvalidToken       = myerrors.NewError("Некорректный токен")
+	ErrNilToken           = myerrors.New(myerrors.KindUnauthorized, "token_nil", "Получили токен = nil")
+	ErrWrongSigningMethod = myerrors.New(myerrors.KindUnauthorized, "token_signing_method",
+		"Неожиданный signing метод ")
+	ErrInvalidToken     = myerrors.New(myerrors.KindUnauthorized, "token_invalid", "Некорректный токен")
+	ErrTokenExpired     = myerrors.New(myerrors.KindUnauthorized, "token_expired", "Срок действия токена истек")
+	ErrTokenNotValidYet = myerrors.New(myerrors.KindUnauthorized, "token_not_valid_yet", "Токен еще не действителен")
 )
 
+// Options are checked for every parsed token, Leeway is allowed clock skew for exp, nbf and iat.
+// TokenLife is lifetime of access token.
+type Options struct {
+	Issuer    string
+	Audience  string
+	Leeway    time.Duration
+	TokenLife time.Duration
+}
+
 type UserJwtPayload struct {
-	UserID uint64
-	Expire int64
-	Email  string
+	UserID   uint64
+	Expire   int64
+	IssuedAt int64
+	// ID is jti claim, unique for every token
+	ID        string
+	SessionID uint64
+	Email     string
+}
+
+type userClaims struct {
+	Email     string `json:"email"`
+	SessionID uint64 `json:"sid"`
+	jwt.RegisteredClaims
 }
 
-func NewUserJwtPayload(rawJwt string, secret []byte) (*UserJwtPayload, error) {
+func NewUserJwtPayload(rawJwt string, keys *KeySet) (*UserJwtPayload, error) {
 	logger, err := my_logger.Get()
 	if err != nil {
 		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
 	}
 
-	tokenDuplicity, err := jwt.Parse(rawJwt, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			logger.Errorf("method == %+v %w", token.Header["alg"], ErrWrongSigningMethod)
-
-			return nil, fmt.Errorf(myerrors.ErrTemplate, ErrInvalidToken)
-		}
+	claims := new(userClaims)
 
-		return secret, nil
-	})
+	_, err = jwt.ParseWithClaims(rawJwt, claims, keys.keyFunc,
+		jwt.WithValidMethods([]string{AlgHS256, AlgRS256, AlgEdDSA}),
+		jwt.WithIssuer(keys.options.Issuer),
+		jwt.WithAudience(keys.options.Audience),
+		jwt.WithLeeway(keys.options.Leeway),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
 	if err != nil {
 		logger.Errorf("%s", err.Error())
 
-		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrInvalidToken)
-	}
-
-	if claims, ok := tokenDuplicity.Claims.(jwt.MapClaims); ok && tokenDuplicity.Valid {
-		interfaceUserID, ok1 := claims["userID"]
-		interfaceExpire, ok2 := claims["expire"]
-		interfaceEmail, ok3 := claims["email"]
-
-		if !(ok1 && ok2 && ok3) {
-			logger.Errorf("error with claims: %+v", claims)
-
+		switch {
+		case errors.Is(err, jwt.ErrTokenExpired):
+			return nil, fmt.Errorf(myerrors.ErrTemplate, ErrTokenExpired)
+		case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+			return nil, fmt.Errorf(myerrors.ErrTemplate, ErrTokenNotValidYet)
+		default:
 			return nil, fmt.Errorf(myerrors.ErrTemplate, ErrInvalidToken)
 		}
+	}
 
-		userID, ok1 := interfaceUserID.(float64)
-		expire, ok2 := interfaceExpire.(float64)
-		email, ok3 := interfaceEmail.(string)
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil || claims.IssuedAt == nil || claims.ID == "" {
+		logger.Errorf("error with claims: %+v", claims)
 
-		if !(ok1 && ok2 && ok3) {
-			logger.Errorf("error with casting claims: %+v", claims)
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrInvalidToken)
+	}
 
-			return nil, fmt.Errorf(myerrors.ErrTemplate, ErrInvalidToken)
-		}
+	return &UserJwtPayload{
+		UserID:    userID,
+		Expire:    claims.ExpiresAt.Unix(),
+		IssuedAt:  claims.IssuedAt.Unix(),
+		ID:        claims.ID,
+		SessionID: claims.SessionID,
+		Email:     claims.Email,
+	}, nil
+}
 
-		return &UserJwtPayload{UserID: uint64(userID), Expire: int64(expire), Email: email}, nil
+func (u *UserJwtPayload) getClaims(options *Options) *userClaims {
+	issuedAt := jwt.NewNumericDate(time.Unix(u.IssuedAt, 0))
+
+	return &userClaims{
+		Email:     u.Email,
+		SessionID: u.SessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    options.Issuer,
+			Subject:   strconv.FormatUint(u.UserID, 10),
+			Audience:  jwt.ClaimStrings{options.Audience},
+			ExpiresAt: jwt.NewNumericDate(time.Unix(u.Expire, 0)),
+			NotBefore: issuedAt,
+			IssuedAt:  issuedAt,
+			ID:        u.ID,
+		},
 	}
-
-	return nil, fmt.Errorf(myerrors.ErrTemplate, ErrInvalidToken)
 }
 
-func (u *UserJwtPayload) getMapClaims() jwt.MapClaims {
-	result := make(jwt.MapClaims)
+func newTokenID() (string, error) {
+	rawID := make([]byte, lenTokenID)
 
-	result["userID"] = u.UserID
-	result["expire"] = u.Expire
-	result["email"] = u.Email
+	_, err := rand.Read(rawID)
+	if err != nil {
+		return "", fmt.Errorf(myerrors.ErrTemplate, err)
+	}
 
-	return result
+	return hex.EncodeToString(rawID), nil
 }
 
-func GenerateJwtToken(userToken *UserJwtPayload, secret []byte, logger *zap.SugaredLogger) (string, error) {
+// GenerateJwtToken signs token by the first key of set. If IssuedAt or ID are not set, they are filled.
+func GenerateJwtToken(userToken *UserJwtPayload, keys *KeySet, logger *zap.SugaredLogger) (string, error) {
 	if userToken == nil {
 		logger.Errorln(ErrNilToken)
 
 		return "", fmt.Errorf(myerrors.ErrTemplate, ErrInvalidToken)
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, userToken.getMapClaims())
+	if userToken.IssuedAt == 0 {
+		userToken.IssuedAt = time.Now().Unix()
+	}
+
+	if userToken.ID == "" {
+		tokenID, err := newTokenID()
+		if err != nil {
+			logger.Errorln(err)
+
+			return "", fmt.Errorf(myerrors.ErrTemplate, ErrInvalidToken)
+		}
+
+		userToken.ID = tokenID
+	}
+
+	signingKey := keys.signingKey()
+
+	token := jwt.NewWithClaims(signingKey.Method, userToken.getClaims(keys.options))
+	token.Header["kid"] = signingKey.ID
 
-	tokenString, err := token.SignedString(secret)
+	tokenString, err := token.SignedString(signingKey.signKey)
 	if err != nil {
 		logger.Errorln(err)
 
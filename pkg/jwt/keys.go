@@ -0,0 +1,192 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+
+	// LegacyKeyID is used for tokens issued before kid appeared in header.
+	LegacyKeyID = "default"
+)
+
+var (
+	keySet    *KeySet   //nolint:gochecknoglobals
+	keySetErr error     //nolint:gochecknoglobals
+	once      sync.Once //nolint:gochecknoglobals
+
+	ErrNoKeySet       = myerrors.NewError("jwt.Get для отсутствующего набора ключей")
+	ErrNoKeys         = myerrors.NewError("Не задано ни одного ключа для подписи jwt, задайте их в JWT_KEYS")
+	ErrWrongKeyFormat = myerrors.NewError("Некорректный формат ключа jwt, ожидается kid=alg:source")
+	ErrDuplicateKeyID = myerrors.NewError("Повторяющийся kid ключа jwt")
+	ErrUnknownAlg     = myerrors.NewError("Неподдерживаемый алгоритм подписи jwt")
+	ErrWrongKeyType   = myerrors.NewError("Тип ключа не соответствует алгоритму подписи jwt")
+)
+
+// Key is one key of KeySet. For HS256 signKey and verifyKey are the same secret,
+// for RS256 and EdDSA signKey is private key and verifyKey is public one.
+type Key struct {
+	ID        string
+	Method    jwt.SigningMethod
+	signKey   any
+	verifyKey any
+}
+
+// KeySet holds all active keys. The first key signs new tokens, the rest are only used to
+// verify tokens issued before rotation, so rotation doesn't log everyone out.
+type KeySet struct {
+	keys    []*Key
+	byKeyID map[string]*Key
+	options *Options
+}
+
+// New parses keys once and keeps them for Get. rawKeys is space separated list of kid=alg:source,
+// where source is secret for HS256 and path to PEM (PKCS8) private key for RS256 and EdDSA.
+// Error of parsing is kept too, so every call after failed one fails the same way.
+func New(rawKeys string, options *Options) (*KeySet, error) {
+	once.Do(func() {
+		keySet, keySetErr = NewKeySet(rawKeys, options)
+	})
+
+	if keySetErr != nil {
+		return nil, keySetErr
+	}
+
+	return keySet, nil
+}
+
+func Get() (*KeySet, error) {
+	if keySet == nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrNoKeySet)
+	}
+
+	return keySet, nil
+}
+
+func NewKeySet(rawKeys string, options *Options) (*KeySet, error) {
+	set := &KeySet{byKeyID: make(map[string]*Key), options: options} //nolint:exhaustruct
+
+	for _, rawKey := range strings.Fields(rawKeys) {
+		key, err := parseKey(rawKey)
+		if err != nil {
+			return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		if _, ok := set.byKeyID[key.ID]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateKeyID, key.ID)
+		}
+
+		set.keys = append(set.keys, key)
+		set.byKeyID[key.ID] = key
+	}
+
+	if len(set.keys) == 0 {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrNoKeys)
+	}
+
+	return set, nil
+}
+
+func parseKey(rawKey string) (*Key, error) {
+	keyID, algAndSource, ok := strings.Cut(rawKey, "=")
+	if !ok || keyID == "" {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrWrongKeyFormat)
+	}
+
+	alg, source, ok := strings.Cut(algAndSource, ":")
+	if !ok || source == "" {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrWrongKeyFormat)
+	}
+
+	switch alg {
+	case AlgHS256:
+		return &Key{ID: keyID, Method: jwt.SigningMethodHS256, signKey: []byte(source), verifyKey: []byte(source)}, nil
+	case AlgRS256, AlgEdDSA:
+		privateKey, err := readPrivateKey(source)
+		if err != nil {
+			return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		return newAsymmetricKey(keyID, alg, privateKey)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAlg, alg)
+	}
+}
+
+func readPrivateKey(path string) (crypto.PrivateKey, error) {
+	rawPEM, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	block, _ := pem.Decode(rawPEM)
+	if block == nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrWrongKeyType)
+	}
+
+	privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return privateKey, nil
+}
+
+func newAsymmetricKey(keyID string, alg string, privateKey crypto.PrivateKey) (*Key, error) {
+	switch typedKey := privateKey.(type) {
+	case *rsa.PrivateKey:
+		if alg != AlgRS256 {
+			return nil, fmt.Errorf(myerrors.ErrTemplate, ErrWrongKeyType)
+		}
+
+		return &Key{ID: keyID, Method: jwt.SigningMethodRS256, signKey: typedKey, verifyKey: &typedKey.PublicKey}, nil
+	case ed25519.PrivateKey:
+		if alg != AlgEdDSA {
+			return nil, fmt.Errorf(myerrors.ErrTemplate, ErrWrongKeyType)
+		}
+
+		return &Key{
+			ID: keyID, Method: jwt.SigningMethodEdDSA, signKey: typedKey, verifyKey: typedKey.Public(),
+		}, nil
+	default:
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrWrongKeyType)
+	}
+}
+
+func (k *KeySet) TokenLife() time.Duration {
+	return k.options.TokenLife
+}
+
+func (k *KeySet) signingKey() *Key {
+	return k.keys[0]
+}
+
+// keyFunc chooses verification key by kid from token header.
+func (k *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	keyID, ok := token.Header["kid"].(string)
+	if !ok {
+		keyID = LegacyKeyID
+	}
+
+	key, ok := k.byKeyID[keyID]
+	if !ok || key.Method.Alg() != token.Method.Alg() {
+		return nil, fmt.Errorf("%w: kid=%s alg=%s", ErrWrongSigningMethod, keyID, token.Method.Alg())
+	}
+
+	return key.verifyKey, nil
+}
@@ -0,0 +1,67 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"go.uber.org/zap"
+)
+
+const permMessageFile = 0o600
+
+// LogMailer doesn't send anything: messages are written to log and, if dir is set, saved to files
+// there. It is meant for local development and tests, where links from emails are taken from files.
+type LogMailer struct {
+	dir     string
+	from    string
+	counter atomic.Uint64
+	logger  *zap.SugaredLogger
+}
+
+func NewLogMailer(dir string, from string) (*LogMailer, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	if dir != "" {
+		err = os.MkdirAll(dir, 0o700) //nolint:gomnd
+		if err != nil {
+			return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+	}
+
+	return &LogMailer{dir: dir, from: from, logger: logger}, nil //nolint:exhaustruct
+}
+
+func (l *LogMailer) Send(_ context.Context, message *Message) error {
+	l.logger.Infof("in LogMailer: to=%s subject=%s", message.To, message.Subject)
+
+	if l.dir == "" {
+		l.logger.Infof("in LogMailer: body=%s", message.Body)
+
+		return nil
+	}
+
+	rawMessage, err := buildMessage(l.from, message)
+	if err != nil {
+		return err
+	}
+
+	fileName := strconv.FormatInt(time.Now().UnixNano(), 10) + "_" +
+		strconv.FormatUint(l.counter.Add(1), 10) + ".eml"
+
+	err = os.WriteFile(filepath.Join(l.dir, fileName), rawMessage, permMessageFile)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
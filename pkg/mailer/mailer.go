@@ -0,0 +1,49 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+)
+
+const (
+	KindSMTP = "smtp"
+	KindLog  = "log"
+)
+
+var ErrUnknownMailer = myerrors.NewError("Неизвестный тип mailer, ожидается smtp или log")
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails to users. Implementations must be safe for concurrent use.
+type Mailer interface {
+	Send(ctx context.Context, message *Message) error
+}
+
+type Config struct {
+	Kind         string
+	From         string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUser     string
+	SMTPPassword string
+	// Dir is directory where LogMailer saves messages, if it is empty messages are only logged
+	Dir string
+}
+
+func New(config *Config) (Mailer, error) {
+	switch strings.ToLower(config.Kind) {
+	case KindSMTP:
+		return NewSMTPMailer(config.SMTPHost, config.SMTPPort, config.SMTPUser, config.SMTPPassword, config.From), nil
+	case KindLog:
+		return NewLogMailer(config.Dir, config.From)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownMailer, config.Kind)
+	}
+}
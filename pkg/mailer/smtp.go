@@ -0,0 +1,64 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+)
+
+var ErrHeaderInjection = myerrors.NewError("Недопустимый перевод строки в заголовке письма")
+
+type SMTPMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPMailer makes mailer which sends messages through smtp server. If user is empty, no auth is used.
+func NewSMTPMailer(host string, port string, user string, password string, from string) *SMTPMailer {
+	var auth smtp.Auth
+	if user != "" {
+		auth = smtp.PlainAuth("", user, password, host)
+	}
+
+	return &SMTPMailer{addr: net.JoinHostPort(host, port), auth: auth, from: from}
+}
+
+func buildMessage(from string, message *Message) ([]byte, error) {
+	for _, header := range []string{from, message.To, message.Subject} {
+		if strings.ContainsAny(header, "\r\n") {
+			return nil, fmt.Errorf(myerrors.ErrTemplate, ErrHeaderInjection)
+		}
+	}
+
+	var builder strings.Builder
+
+	builder.WriteString("From: " + from + "\r\n")
+	builder.WriteString("To: " + message.To + "\r\n")
+	builder.WriteString("Subject: " + message.Subject + "\r\n")
+	builder.WriteString("MIME-Version: 1.0\r\n")
+	builder.WriteString("Content-Type: text/plain; charset=\"UTF-8\"\r\n")
+	builder.WriteString("\r\n")
+	builder.WriteString(message.Body)
+
+	return []byte(builder.String()), nil
+}
+
+// Send doesn't support context cancellation, because net/smtp doesn't. ctx is kept for Mailer interface.
+func (s *SMTPMailer) Send(_ context.Context, message *Message) error {
+	rawMessage, err := buildMessage(s.from, message)
+	if err != nil {
+		return err
+	}
+
+	err = smtp.SendMail(s.addr, s.auth, s.from, []string{message.To}, rawMessage)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
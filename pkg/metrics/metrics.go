@@ -0,0 +1,91 @@
+// Package metrics keeps prometheus metrics of server, they are served on /metrics.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "film_library"
+
+// storage label values of StorageQueryDuration
+const (
+	StorageFilm  = "film"
+	StorageActor = "actor"
+	StorageUser  = "user"
+)
+
+// reason label values of SignInFailures
+const (
+	SignInFailureWrongCredentials = "wrong_credentials"
+	SignInFailureLocked           = "locked"
+)
+
+var (
+	registry = prometheus.NewRegistry() //nolint:gochecknoglobals
+
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{ //nolint:gochecknoglobals,exhaustruct
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of http requests by route, method and status.",
+	}, []string{"route", "method", "status"})
+
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{ //nolint:gochecknoglobals,exhaustruct
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duration of http requests by route and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	StorageQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{ //nolint:gochecknoglobals,exhaustruct
+		Namespace: namespace,
+		Name:      "storage_query_duration_seconds",
+		Help:      "Duration of storage methods by storage and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"storage", "method"})
+
+	SignUps = prometheus.NewCounter(prometheus.CounterOpts{ //nolint:gochecknoglobals,exhaustruct
+		Namespace: namespace,
+		Name:      "sign_ups_total",
+		Help:      "Number of users signed up with email and password.",
+	})
+
+	SignInFailures = prometheus.NewCounterVec(prometheus.CounterOpts{ //nolint:gochecknoglobals,exhaustruct
+		Namespace: namespace,
+		Name:      "sign_in_failures_total",
+		Help:      "Number of failed sign in attempts by reason.",
+	}, []string{"reason"})
+
+	FilmsCreated = prometheus.NewCounter(prometheus.CounterOpts{ //nolint:gochecknoglobals,exhaustruct
+		Namespace: namespace,
+		Name:      "films_created_total",
+		Help:      "Number of created films.",
+	})
+)
+
+func init() { //nolint:gochecknoinits
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}), //nolint:exhaustruct
+		HTTPRequests, HTTPRequestDuration, StorageQueryDuration, SignUps, SignInFailures, FilmsCreated,
+	)
+}
+
+// Register adds collectors to registry of server, e.g. statistics of pgx pool.
+func Register(collector prometheus.Collector) error {
+	return registry.Register(collector) //nolint:wrapcheck
+}
+
+// ObserveQuery records duration of storage method started at start. It is deferred
+// at the beginning of method: defer metrics.ObserveQuery(metrics.StorageFilm, "GetFilm", time.Now()).
+func ObserveQuery(storage string, method string, start time.Time) {
+	StorageQueryDuration.WithLabelValues(storage, method).Observe(time.Since(start).Seconds())
+}
+
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{}) //nolint:exhaustruct
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PoolCollector exports statistics of pgx pool, they are read on every scrape.
+type PoolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns        *prometheus.Desc
+	idleConns            *prometheus.Desc
+	totalConns           *prometheus.Desc
+	maxConns             *prometheus.Desc
+	acquireCount         *prometheus.Desc
+	acquireDuration      *prometheus.Desc
+	emptyAcquireCount    *prometheus.Desc
+	canceledAcquireCount *prometheus.Desc
+}
+
+func newPoolDesc(name string, help string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(namespace, "pgxpool", name), help, nil, nil)
+}
+
+func NewPoolCollector(pool *pgxpool.Pool) *PoolCollector {
+	return &PoolCollector{
+		pool:            pool,
+		acquiredConns:   newPoolDesc("acquired_conns", "Number of connections currently in use."),
+		idleConns:       newPoolDesc("idle_conns", "Number of idle connections."),
+		totalConns:      newPoolDesc("total_conns", "Number of open connections."),
+		maxConns:        newPoolDesc("max_conns", "Maximum size of pool."),
+		acquireCount:    newPoolDesc("acquire_total", "Number of successful acquires of connection."),
+		acquireDuration: newPoolDesc("acquire_duration_seconds_total", "Total time spent on acquires of connection."),
+		emptyAcquireCount: newPoolDesc("empty_acquire_total",
+			"Number of acquires which waited for connection because pool was empty."),
+		canceledAcquireCount: newPoolDesc("canceled_acquire_total",
+			"Number of acquires canceled by context."),
+	}
+}
+
+func (p *PoolCollector) Describe(descs chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(p, descs)
+}
+
+func (p *PoolCollector) Collect(metrics chan<- prometheus.Metric) {
+	stat := p.pool.Stat()
+
+	metrics <- prometheus.MustNewConstMetric(p.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	metrics <- prometheus.MustNewConstMetric(p.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	metrics <- prometheus.MustNewConstMetric(p.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	metrics <- prometheus.MustNewConstMetric(p.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	metrics <- prometheus.MustNewConstMetric(p.acquireCount, prometheus.CounterValue, float64(stat.AcquireCount()))
+	metrics <- prometheus.MustNewConstMetric(p.acquireDuration, prometheus.CounterValue,
+		stat.AcquireDuration().Seconds())
+	metrics <- prometheus.MustNewConstMetric(p.emptyAcquireCount, prometheus.CounterValue,
+		float64(stat.EmptyAcquireCount()))
+	metrics <- prometheus.MustNewConstMetric(p.canceledAcquireCount, prometheus.CounterValue,
+		float64(stat.CanceledAcquireCount()))
+}
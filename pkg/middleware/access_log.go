@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	HeaderRequestID = "X-Request-ID"
+
+	maxRequestIDLen = 128
+)
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	size   int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(body []byte) (int, error) {
+	size, err := s.ResponseWriter.Write(body)
+	s.size += size
+
+	return size, err //nolint:wrapcheck
+}
+
+// isValidRequestID accepts only short ids of letters, digits, '-', '_' and '.', so client can't break log lines.
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLen {
+		return false
+	}
+
+	for _, char := range requestID {
+		isLetter := (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z')
+		isDigit := char >= '0' && char <= '9'
+
+		if !isLetter && !isDigit && char != '-' && char != '_' && char != '.' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// AccessLog takes X-Request-ID of request or generates new one and echoes it in response.
+// Logger of request is put into context (see my_logger.FromCtx), after request one access log line
+// with request id, route, user id, status and latency is written.
+func AccessLog(next http.Handler, logger *zap.SugaredLogger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		requestID := r.Header.Get(HeaderRequestID)
+		if !isValidRequestID(requestID) {
+			var err error
+
+			requestID, err = my_logger.NewRequestID()
+			if err != nil {
+				logger.Errorf("in AccessLog: %+v", err)
+			}
+		}
+
+		w.Header().Set(HeaderRequestID, requestID)
+
+		info := &my_logger.RequestInfo{ //nolint:exhaustruct
+			ID:     requestID,
+			Logger: logger.With("request_id", requestID),
+		}
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK, size: 0}
+
+		next.ServeHTTP(recorder, r.WithContext(my_logger.WithRequestInfo(r.Context(), info)))
+
+		info.Logger.Infow("access",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", recorder.status,
+			"latency", time.Since(start),
+			"size", recorder.size,
+			"remote_addr", r.RemoteAddr,
+		)
+	})
+}
+
+// Route records pattern of route for access log and logger of request.
+func Route(pattern string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		my_logger.GetRequestInfoFromCtx(r.Context()).SetRoute(pattern)
+		next.ServeHTTP(w, r)
+	})
+}
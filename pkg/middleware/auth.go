@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"github.com/SanExpett/film-library-backend/internal/server/delivery"
+	"github.com/SanExpett/film-library-backend/pkg/i18n"
+	"github.com/SanExpett/film-library-backend/pkg/models"
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"github.com/SanExpett/film-library-backend/pkg/principal"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// Access is level of access to route, it is declared for every route in mux.
+type Access int
+
+const (
+	// AccessPublic routes are open for everyone, principal is not resolved for them
+	AccessPublic Access = iota
+	// AccessAuthenticated routes need signed in user or api key
+	AccessAuthenticated
+	// AccessSession routes need user signed in by cookie, api key can't be used for them
+	AccessSession
+	// AccessAdmin routes need user with admin role
+	AccessAdmin
+)
+
+var ErrAdminOnly = myerrors.New(myerrors.KindForbidden, "admin_only", "Действие доступно только администратору")
+
+// Auth resolves principal of request once and puts it into request context, handlers read it
+// by delivery.GetPrincipal. Requests without access to route are rejected before handler.
+func Auth(authenticator *delivery.Authenticator, access Access, next http.Handler, logger *zap.SugaredLogger,
+) http.Handler {
+	if access == AccessPublic {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestPrincipal, err := authenticator.ResolvePrincipal(r)
+		if err != nil {
+			my_logger.FromCtx(r.Context()).Errorf("in Auth: %s %s err=%+v", r.Method, r.URL.Path, err)
+			delivery.HandleErr(w, r, logger, err)
+
+			return
+		}
+
+		my_logger.GetRequestInfoFromCtx(r.Context()).SetUserID(requestPrincipal.UserID)
+
+		// locale from profile is preferred over Accept-Language for messages of signed in user
+		if lang, ok := i18n.Parse(requestPrincipal.Locale); ok {
+			r = r.WithContext(i18n.WithLang(r.Context(), lang))
+		}
+
+		if access == AccessSession && requestPrincipal.IsAPIKey() {
+			my_logger.FromCtx(r.Context()).Errorf("in Auth: api key id=%d is used for %s %s",
+				requestPrincipal.APIKey.ID, r.Method, r.URL.Path)
+			delivery.HandleErr(w, r, logger, delivery.ErrSessionOnly)
+
+			return
+		}
+
+		if access == AccessAdmin && !requestPrincipal.HasRole(models.RoleAdmin) {
+			my_logger.FromCtx(r.Context()).Errorf("in Auth: user id=%d is not admin for %s %s",
+				requestPrincipal.UserID, r.Method, r.URL.Path)
+			delivery.HandleErr(w, r, logger, ErrAdminOnly)
+
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(principal.With(r.Context(), requestPrincipal)))
+	})
+}
@@ -1,13 +0,0 @@
-package middleware
-
-import (
-	"context"
-	"net/http"
-)
-
-func Context(ctx context.Context, next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		r = r.WithContext(ctx)
-		next.ServeHTTP(w, r)
-	})
-}
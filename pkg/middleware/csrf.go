@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"github.com/SanExpett/film-library-backend/internal/server/delivery"
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+var ErrCSRFToken = myerrors.New(myerrors.KindForbidden, "csrf_token_invalid", "Некорректный csrf токен")
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// CSRF checks double-submit token for every state-changing request:
+// X-CSRF-Token header has to be equal to csrf_token cookie. Other site can send cookie, but can't read it.
+// Requests with Authorization header are not checked: they are authenticated only by api key from the header,
+// and other site can't add this header without cors preflight.
+func CSRF(next http.Handler, logger *zap.SugaredLogger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isSafeMethod(r.Method) || r.Header.Get(delivery.HeaderAuthorization) != "" {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		cookie, err := r.Cookie(delivery.CookieCSRFName)
+		headerToken := r.Header.Get(delivery.HeaderCSRFName)
+
+		if err != nil || cookie.Value == "" ||
+			subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(headerToken)) != 1 {
+			my_logger.FromCtx(r.Context()).Errorf("in CSRF: csrf token mismatch for %s %s", r.Method, r.URL.Path)
+			delivery.HandleErr(w, r, logger, ErrCSRFToken)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"github.com/SanExpett/film-library-backend/pkg/metrics"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// MethodOther is label of requests with non-standard method, any client can send arbitrary method,
+// so such methods are not used as label as is.
+const MethodOther = "other"
+
+var knownMethods = map[string]struct{}{ //nolint:gochecknoglobals
+	http.MethodGet:     {},
+	http.MethodHead:    {},
+	http.MethodPost:    {},
+	http.MethodPut:     {},
+	http.MethodPatch:   {},
+	http.MethodDelete:  {},
+	http.MethodConnect: {},
+	http.MethodOptions: {},
+	http.MethodTrace:   {},
+}
+
+func methodLabel(method string) string {
+	if _, ok := knownMethods[method]; !ok {
+		return MethodOther
+	}
+
+	return method
+}
+
+// Metrics records count and duration of requests to route, pattern of route is used as label,
+// so path parameters don't multiply series.
+func Metrics(pattern string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK, size: 0}
+
+		next.ServeHTTP(recorder, r)
+
+		method := methodLabel(r.Method)
+		metrics.HTTPRequests.WithLabelValues(pattern, method, strconv.Itoa(recorder.status)).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(pattern, method).Observe(time.Since(start).Seconds())
+	})
+}
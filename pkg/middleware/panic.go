@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"github.com/SanExpett/film-library-backend/internal/server/delivery"
+	"github.com/SanExpett/film-library-backend/pkg/my_logger"
 	"net/http"
 
 	"go.uber.org/zap"
@@ -11,9 +12,9 @@ func Panic(next http.Handler, logger *zap.SugaredLogger) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if err := recover(); err != nil {
-				logger.Errorf("panic recovered: %+v\n", err)
-				delivery.SendErrResponse(w, logger,
-					delivery.NewErrResponse(delivery.StatusErrInternalServer, delivery.ErrInternalServer))
+				my_logger.FromCtx(r.Context()).Errorf("panic recovered: %+v\n", err)
+				lang := delivery.RequestLang(r)
+				delivery.SendErrResponse(w, logger, lang, delivery.NewInternalServerProblem(lang))
 			}
 		}()
 		next.ServeHTTP(w, r)
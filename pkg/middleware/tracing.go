@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"github.com/SanExpett/film-library-backend/pkg/tracing"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// spanName is "METHOD route". Pattern of v2 route already starts with method, so it is used as is.
+func spanName(method string, pattern string) string {
+	if strings.Contains(pattern, " ") {
+		return pattern
+	}
+
+	return method + " " + pattern
+}
+
+// Tracing continues trace from W3C traceparent header of request or starts new one. Span of route
+// covers auth and handler, spans of services and storages are its children.
+func Tracing(pattern string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+		// method is taken as label of metrics, so arbitrary method of client doesn't get into span
+		method := methodLabel(r.Method)
+
+		ctx, span := tracing.Start(ctx, spanName(method, pattern), trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(method),
+				semconv.HTTPRoute(pattern),
+				semconv.URLPath(r.URL.Path),
+			))
+		defer span.End()
+
+		if span.SpanContext().HasTraceID() {
+			my_logger.GetRequestInfoFromCtx(ctx).SetTraceID(span.SpanContext().TraceID().String())
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK, size: 0}
+
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPResponseStatusCode(recorder.status))
+
+		if recorder.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(recorder.status))
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// APIKey is key of machine client. Key itself is shown only once on creation, only its hash is stored,
+// Prefix helps to tell keys apart in list.
+type APIKey struct {
+	ID         uint64       `json:"id"           valid:"required"`
+	UserID     uint64       `json:"user_id"      valid:"required"`
+	Name       string       `json:"name"         valid:"required"`
+	Prefix     string       `json:"prefix"       valid:"required"`
+	Scopes     []Permission `json:"scopes"       valid:"required"`
+	CreatedAt  time.Time    `json:"created_at"   valid:"required"`
+	ExpiresAt  *time.Time   `json:"expires_at"   valid:"optional"`
+	LastUsedAt *time.Time   `json:"last_used_at" valid:"optional"`
+}
+
+func (a *APIKey) Sanitize() {
+	sanitizer := bluemonday.UGCPolicy()
+
+	a.Name = sanitizer.Sanitize(a.Name)
+}
+
+// HasScope reports whether key is allowed to be used for action with permission.
+func (a *APIKey) HasScope(permission Permission) bool {
+	for _, scope := range a.Scopes {
+		if scope == permission {
+			return true
+		}
+	}
+
+	return false
+}
+
+type APIKeyWithoutID struct {
+	Name      string       `json:"name"       valid:"required,length(1|64)~Name must be from 1 to 64 symbols"`
+	Scopes    []Permission `json:"scopes"     valid:"required"`
+	ExpiresAt *time.Time   `json:"expires_at" valid:"optional"`
+}
+
+func (a *APIKeyWithoutID) Trim() {
+	a.Name = strings.TrimSpace(a.Name)
+
+	for i := range a.Scopes {
+		a.Scopes[i] = Permission(strings.TrimSpace(string(a.Scopes[i])))
+	}
+}
+
+type PreAPIKey struct {
+	UserID    uint64
+	Name      string
+	Prefix    string
+	KeyHash   string
+	Scopes    []Permission
+	ExpiresAt *time.Time
+}
+
+// CreatedAPIKey is key just created, Key is returned to user only here.
+type CreatedAPIKey struct {
+	APIKey *APIKey `json:"api_key"`
+	Key    string  `json:"key"`
+}
+
+//nolint:gochecknoglobals
+var permissions = []Permission{
+	PermissionFilmCreate, PermissionFilmUpdate, PermissionFilmDelete,
+	PermissionActorCreate, PermissionActorUpdate, PermissionActorDelete,
+	PermissionCastUpdate, PermissionUserManage, PermissionRoleManage,
+}
+
+func IsKnownPermission(permission Permission) bool {
+	for _, knownPermission := range permissions {
+		if knownPermission == permission {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,19 @@
+package models
+
+type AuditAction string
+
+const (
+	AuditActionAccountLocked   AuditAction = "account_locked"
+	AuditActionIPLocked        AuditAction = "ip_locked"
+	AuditActionAccountUnlocked AuditAction = "account_unlocked"
+)
+
+// AuditEntry is record of security-relevant event. ActorID is nil if event isn't caused by signed in user,
+// Target is email, ip or other subject of event.
+type AuditEntry struct {
+	ActorID *uint64
+	Action  AuditAction
+	Target  string
+	IP      string
+	Details string
+}
@@ -24,6 +24,20 @@ type FilmWithoutID struct {
 	CreatedAt   time.Time `json:"created_at"   valid:"required"`
 }
 
+// FilmWithCast is used for adding film with its cast at once. Actors from ActorsIDs must already exist,
+// actors from NewActors are created together with the film.
+type FilmWithCast struct {
+	FilmWithoutID
+	ActorsIDs []uint64          `json:"actors_ids"   valid:"optional"`
+	NewActors []*ActorWithoutID `json:"new_actors"   valid:"optional"`
+}
+
+type AddedFilm struct {
+	ID            uint64   `json:"id"              valid:"required"`
+	ActorsIDs     []uint64 `json:"actors_ids"      valid:"optional"`
+	FilmActorsIDs []uint64 `json:"film_actors_ids" valid:"optional"`
+}
+
 func (f *Film) Trim() {
 	f.Title = strings.TrimSpace(f.Title)
 	f.Description = strings.TrimSpace(f.Description)
@@ -34,6 +48,16 @@ func (f *FilmWithoutID) Trim() {
 	f.Description = strings.TrimSpace(f.Description)
 }
 
+func (f *FilmWithCast) Trim() {
+	f.FilmWithoutID.Trim()
+
+	for _, preActor := range f.NewActors {
+		if preActor != nil {
+			preActor.Trim()
+		}
+	}
+}
+
 func (f *Film) Sanitize() {
 	sanitizer := bluemonday.UGCPolicy()
 
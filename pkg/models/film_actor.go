@@ -0,0 +1,31 @@
+package models
+
+import (
+	"github.com/microcosm-cc/bluemonday"
+	"strings"
+)
+
+type FilmActor struct {
+	ID            uint64 `json:"id"             valid:"required"`
+	FilmID        uint64 `json:"film_id"        valid:"required"`
+	ActorID       uint64 `json:"actor_id"       valid:"required"`
+	CharacterName string `json:"character_name" valid:"optional,length(1|256)~Character name length must be from 1 to 256"` //nolint
+	BillingOrder  uint64 `json:"billing_order"  valid:"optional"`
+}
+
+type FilmActorWithoutID struct {
+	FilmID        uint64  `json:"film_id"        valid:"required"`
+	ActorID       uint64  `json:"actor_id"       valid:"required"`
+	CharacterName string  `json:"character_name" valid:"optional,length(1|256)~Character name length must be from 1 to 256"` //nolint
+	BillingOrder  *uint64 `json:"billing_order"  valid:"optional"`
+}
+
+func (f *FilmActorWithoutID) Trim() {
+	f.CharacterName = strings.TrimSpace(f.CharacterName)
+}
+
+func (f *FilmActor) Sanitize() {
+	sanitizer := bluemonday.UGCPolicy()
+
+	f.CharacterName = sanitizer.Sanitize(f.CharacterName)
+}
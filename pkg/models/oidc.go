@@ -0,0 +1,33 @@
+package models
+
+import "strings"
+
+const MaxLenDisplayName = 64
+
+// ExternalIdentity is user at oidc provider, Issuer and Subject together identify user there.
+// Name becomes display name of user created on first sign in.
+type ExternalIdentity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+func (e *ExternalIdentity) Trim() {
+	e.Email = strings.TrimSpace(e.Email)
+	e.Name = strings.TrimSpace(e.Name)
+
+	if nameRunes := []rune(e.Name); len(nameRunes) > MaxLenDisplayName {
+		e.Name = string(nameRunes[:MaxLenDisplayName])
+	}
+}
+
+// OIDCLogin is started sign in by oidc provider. State, Nonce and CodeVerifier are kept by client
+// until provider redirects back, AuthURL is page of provider where user is redirected.
+type OIDCLogin struct {
+	AuthURL      string
+	State        string
+	Nonce        string
+	CodeVerifier string
+}
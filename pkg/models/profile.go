@@ -0,0 +1,90 @@
+package models
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/microcosm-cc/bluemonday"
+)
+
+//nolint:gochecknoinits
+func init() {
+	// requrl of govalidator accepts any scheme, for example javascript:, so avatar url is checked to be http(s)
+	govalidator.CustomTypeTagMap.Set(
+		"httpurl",
+		func(i interface{}, o interface{}) bool {
+			subject, ok := i.(string)
+			if !ok {
+				return false
+			}
+
+			parsedURL, err := url.Parse(subject)
+			if err != nil {
+				return false
+			}
+
+			return (parsedURL.Scheme == "http" || parsedURL.Scheme == "https") && parsedURL.Host != ""
+		},
+	)
+}
+
+type Profile struct {
+	ID            uint64    `json:"id"             valid:"required"`
+	Email         string    `json:"email"          valid:"required,email~Not valid email"`
+	DisplayName   string    `json:"display_name"   valid:"optional"`
+	AvatarURL     string    `json:"avatar_url"     valid:"optional"`
+	Locale        string    `json:"locale"         valid:"required"`
+	EmailVerified bool      `json:"email_verified" valid:"optional"`
+	PendingEmail  *string   `json:"pending_email"  valid:"optional"`
+	CreatedAt     time.Time `json:"created_at"     valid:"required"`
+}
+
+func (p *Profile) Sanitize() {
+	sanitizer := bluemonday.UGCPolicy()
+
+	p.Email = sanitizer.Sanitize(p.Email)
+	p.DisplayName = sanitizer.Sanitize(p.DisplayName)
+	p.AvatarURL = sanitizer.Sanitize(p.AvatarURL)
+
+	if p.PendingEmail != nil {
+		pendingEmail := sanitizer.Sanitize(*p.PendingEmail)
+		p.PendingEmail = &pendingEmail
+	}
+}
+
+type ProfileWithoutID struct {
+	DisplayName string `json:"display_name" valid:"required,length(1|64)~Display name must be from 1 to 64 symbols"`
+	AvatarURL   string `json:"avatar_url"   valid:"required,httpurl~Avatar url must be http or https url,length(1|512)~Avatar url must be at most 512 symbols"` //nolint:lll
+	Locale      string `json:"locale"       valid:"required,in(ru|en)~Locale must be ru or en"`
+}
+
+func (p *ProfileWithoutID) Trim() {
+	p.DisplayName = strings.TrimSpace(p.DisplayName)
+	p.AvatarURL = strings.TrimSpace(p.AvatarURL)
+	p.Locale = strings.TrimSpace(p.Locale)
+}
+
+type PasswordChange struct {
+	CurrentPassword string `json:"current_password" valid:"required"`
+	NewPassword     string `json:"new_password"     valid:"required,password~Password must be at least 6 symbols"`
+}
+
+func (p *PasswordChange) Trim() {}
+
+// EmailChange asks for current password, so stolen session isn't enough to take over account.
+type EmailChange struct {
+	Email    string `json:"email"    valid:"required,email~Not valid email"`
+	Password string `json:"password" valid:"required"`
+}
+
+func (e *EmailChange) Trim() {
+	e.Email = strings.TrimSpace(e.Email)
+}
+
+type AccountDeletion struct {
+	Password string `json:"password" valid:"required"`
+}
+
+func (a *AccountDeletion) Trim() {}
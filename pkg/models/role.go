@@ -0,0 +1,40 @@
+package models
+
+import "strings"
+
+type Role string
+
+const (
+	RoleViewer    Role = "viewer"
+	RoleEditor    Role = "editor"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+type Permission string
+
+const (
+	PermissionFilmCreate  Permission = "film:create"
+	PermissionFilmUpdate  Permission = "film:update"
+	PermissionFilmDelete  Permission = "film:delete"
+	PermissionActorCreate Permission = "actor:create"
+	PermissionActorUpdate Permission = "actor:update"
+	PermissionActorDelete Permission = "actor:delete"
+	PermissionCastUpdate  Permission = "cast:update"
+	PermissionUserManage  Permission = "user:manage"
+	PermissionRoleManage  Permission = "role:manage"
+)
+
+type RoleWithPermissions struct {
+	Name        Role         `json:"name"        valid:"required"`
+	Permissions []Permission `json:"permissions" valid:"optional"`
+}
+
+type UserRole struct {
+	UserID uint64 `json:"user_id" valid:"required"`
+	Role   string `json:"role"    valid:"required,in(viewer|editor|moderator|admin)~Unknown role"`
+}
+
+func (u *UserRole) Trim() {
+	u.Role = strings.TrimSpace(u.Role)
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+const MaxLenUserAgent = 256
+
+type Session struct {
+	ID         uint64    `json:"id"           valid:"required"`
+	UserID     uint64    `json:"user_id"      valid:"required"`
+	UserAgent  string    `json:"user_agent"   valid:"optional"`
+	IP         string    `json:"ip"           valid:"optional"`
+	CreatedAt  time.Time `json:"created_at"   valid:"required"`
+	LastUsedAt time.Time `json:"last_used_at" valid:"required"`
+	ExpiresAt  time.Time `json:"expires_at"   valid:"required"`
+	IsCurrent  bool      `json:"is_current"   valid:"optional"`
+}
+
+func (s *Session) Sanitize() {
+	sanitizer := bluemonday.UGCPolicy()
+
+	s.UserAgent = sanitizer.Sanitize(s.UserAgent)
+	s.IP = sanitizer.Sanitize(s.IP)
+}
+
+type SessionWithoutID struct {
+	UserID           uint64
+	RefreshTokenHash string
+	UserAgent        string
+	IP               string
+	ExpiresAt        time.Time
+}
+
+func (s *SessionWithoutID) Trim() {
+	if len(s.UserAgent) > MaxLenUserAgent {
+		s.UserAgent = s.UserAgent[:MaxLenUserAgent]
+	}
+}
+
+// AuthSession is session just created or refreshed. RefreshToken is shown to user only once,
+// only its hash is stored.
+type AuthSession struct {
+	Session      *Session
+	User         *UserWithoutPassword
+	RefreshToken string
+}
@@ -59,3 +59,17 @@ func (u *UserWithoutPassword) Sanitize() {
 
 	u.Email = sanitizer.Sanitize(u.Email)
 }
+
+type UserWithRoles struct {
+	ID          uint64     `json:"id"           valid:"required"`
+	Email       string     `json:"email"        valid:"required,email~Not valid email"`
+	CreatedAt   time.Time  `json:"created_at"   valid:"required"`
+	SuspendedAt *time.Time `json:"suspended_at" valid:"optional"`
+	Roles       []Role     `json:"roles"        valid:"optional"`
+}
+
+func (u *UserWithRoles) Sanitize() {
+	sanitizer := bluemonday.UGCPolicy()
+
+	u.Email = sanitizer.Sanitize(u.Email)
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"strings"
+)
+
+type UserTokenPurpose string
+
+const (
+	UserTokenPasswordReset     UserTokenPurpose = "password_reset"
+	UserTokenEmailVerification UserTokenPurpose = "email_verification"
+	UserTokenEmailChange       UserTokenPurpose = "email_change"
+)
+
+type PasswordResetRequest struct {
+	Email string `json:"email" valid:"required,email~Not valid email"`
+}
+
+func (p *PasswordResetRequest) Trim() {
+	p.Email = strings.TrimSpace(p.Email)
+}
+
+type PasswordResetConfirm struct {
+	Token    string `json:"token"    valid:"required"`
+	Password string `json:"password" valid:"required,password~Password must be at least 6 symbols"`
+}
+
+func (p *PasswordResetConfirm) Trim() {
+	p.Token = strings.TrimSpace(p.Token)
+}
+
+// UserTokenConfirm is body of confirmation by token from email.
+type UserTokenConfirm struct {
+	Token string `json:"token" valid:"required"`
+}
+
+func (u *UserTokenConfirm) Trim() {
+	u.Token = strings.TrimSpace(u.Token)
+}
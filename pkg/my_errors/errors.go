@@ -6,14 +6,61 @@ const (
 	ErrTemplate = "%w"
 )
 
+// Kind tells what is wrong with request, delivery turns it into http status of response.
+type Kind int
+
+const (
+	// KindBadRequest is kind of errors created by NewError, request can't be processed as it is
+	KindBadRequest Kind = iota
+	// KindValidation means that fields of request body are not valid, details are in Fields
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindTooManyRequests
+	KindTimeout
+)
+
+const CodeBadRequest = "bad_request"
+
 type Error struct {
-	err string
+	err    string
+	kind   Kind
+	code   string
+	args   []any
+	fields []FieldError
 }
 
+// NewError creates error of KindBadRequest with code CodeBadRequest.
 func NewError(format string, args ...any) *Error {
-	return &Error{fmt.Sprintf(format, args...)}
+	return &Error{err: fmt.Sprintf(format, args...), kind: KindBadRequest, code: CodeBadRequest}
+}
+
+// New creates error of kind with code. Code is stable: it is sent to client,
+// so client can tell errors apart without parsing message. Code is also key of translated message,
+// args are put into it the same way as into format.
+func New(kind Kind, code string, format string, args ...any) *Error {
+	return &Error{err: fmt.Sprintf(format, args...), kind: kind, code: code, args: args}
 }
 
 func (e *Error) Error() string {
 	return e.err
 }
+
+func (e *Error) Kind() Kind {
+	return e.kind
+}
+
+func (e *Error) Code() string {
+	return e.code
+}
+
+func (e *Error) Args() []any {
+	return e.args
+}
+
+// Fields returns errors of fields for error of KindValidation.
+func (e *Error) Fields() []FieldError {
+	return e.fields
+}
@@ -0,0 +1,103 @@
+package my_errors
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/asaskevich/govalidator"
+)
+
+const (
+	CodeValidation = "validation_failed"
+
+	// ValidatorRequired is validator of govalidator for empty required field
+	ValidatorRequired = "required"
+)
+
+// FieldError is error of one field of request body. Code is name of failed validator, for example
+// required or length, Detail is message of govalidator.
+type FieldError struct {
+	Field  string `json:"field"`
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
+
+func collectFields(err error, fields []FieldError) []FieldError {
+	switch err := err.(type) { //nolint:errorlint
+	case govalidator.Errors:
+		for _, item := range err.Errors() {
+			fields = collectFields(item, fields)
+		}
+	case govalidator.Error:
+		fields = append(fields, FieldError{Field: err.Name, Code: err.Validator, Detail: err.Err.Error()})
+	}
+
+	return fields
+}
+
+// ValidationFields splits error of govalidator.ValidateStruct into errors of fields sorted by field.
+// Fields which failed ignored validators are skipped, so partial update can ignore ValidatorRequired.
+func ValidationFields(err error, ignored ...string) []FieldError {
+	var validationErrs govalidator.Errors
+
+	var validationErr govalidator.Error
+
+	var allFields []FieldError
+
+	switch {
+	case errors.As(err, &validationErrs):
+		allFields = collectFields(validationErrs, nil)
+	case errors.As(err, &validationErr):
+		allFields = collectFields(validationErr, nil)
+	}
+
+	fields := make([]FieldError, 0, len(allFields))
+
+	for _, field := range allFields {
+		isIgnored := false
+
+		for _, ignoredValidator := range ignored {
+			if field.Code == ignoredValidator {
+				isIgnored = true
+
+				break
+			}
+		}
+
+		if !isIgnored {
+			fields = append(fields, field)
+		}
+	}
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		return fields[i].Field < fields[j].Field
+	})
+
+	return fields
+}
+
+// NewValidationError creates error of KindValidation with errors of fields.
+func NewValidationError(fields ...FieldError) *Error {
+	details := make([]string, 0, len(fields))
+	for _, field := range fields {
+		details = append(details, field.Field+": "+field.Detail)
+	}
+
+	return &Error{ //nolint:exhaustruct
+		err:    "Некорректные поля: " + strings.Join(details, "; "),
+		kind:   KindValidation,
+		code:   CodeValidation,
+		fields: fields,
+	}
+}
+
+// NewValidationErrorFrom creates error of KindValidation from error of govalidator.ValidateStruct.
+func NewValidationErrorFrom(err error) *Error {
+	fields := ValidationFields(err)
+	if len(fields) == 0 {
+		return &Error{err: err.Error(), kind: KindValidation, code: CodeValidation} //nolint:exhaustruct
+	}
+
+	return NewValidationError(fields...)
+}
@@ -1,21 +1,100 @@
 package my_logger
 
-import "context"
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+
+	"go.uber.org/zap"
+)
 
 type keyCtx string
 
 const (
 	requestIDKey keyCtx = "req_id"
 
-	minRequestID = 100000
-	maxRequestID = 999999
+	requestIDLen = 16
 )
 
-func GetRequestIDFromCtx(ctx context.Context) string {
-	requestID, ok := ctx.Value(requestIDKey).(string)
+// RequestInfo is shared by middlewares of one request: route and user are known only deeper in chain,
+// access log reads them after request is served. Logger has fields of everything known about request.
+type RequestInfo struct {
+	ID     string
+	Route  string
+	UserID uint64
+	Logger *zap.SugaredLogger
+}
+
+func (i *RequestInfo) SetRoute(route string) {
+	if i == nil {
+		return
+	}
+
+	i.Route = route
+	i.Logger = i.Logger.With("route", route)
+}
+
+func (i *RequestInfo) SetUserID(userID uint64) {
+	if i == nil {
+		return
+	}
+
+	i.UserID = userID
+	i.Logger = i.Logger.With("user_id", userID)
+}
+
+func (i *RequestInfo) SetTraceID(traceID string) {
+	if i == nil {
+		return
+	}
+
+	i.Logger = i.Logger.With("trace_id", traceID)
+}
+
+func NewRequestID() (string, error) {
+	rawID := make([]byte, requestIDLen)
+
+	_, err := rand.Read(rawID)
+	if err != nil {
+		return "", fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return hex.EncodeToString(rawID), nil
+}
+
+func WithRequestInfo(ctx context.Context, info *RequestInfo) context.Context {
+	return context.WithValue(ctx, requestIDKey, info)
+}
+
+// GetRequestInfoFromCtx returns nil if request isn't passed through middleware.AccessLog.
+func GetRequestInfoFromCtx(ctx context.Context) *RequestInfo {
+	info, ok := ctx.Value(requestIDKey).(*RequestInfo)
 	if !ok {
+		return nil
+	}
+
+	return info
+}
+
+func GetRequestIDFromCtx(ctx context.Context) string {
+	info := GetRequestInfoFromCtx(ctx)
+	if info == nil {
 		return ""
 	}
 
-	return requestID
+	return info.ID
+}
+
+// FromCtx returns logger of request with request id, route and user id, global logger is returned
+// outside of request.
+func FromCtx(ctx context.Context) *zap.SugaredLogger {
+	info := GetRequestInfoFromCtx(ctx)
+	if info == nil || info.Logger == nil {
+		return logger
+	}
+
+	return info.Logger
 }
@@ -0,0 +1,219 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	leeway = time.Minute
+
+	keyTypeRSA = "RSA"
+	keyTypeEC  = "EC"
+)
+
+var (
+	ErrInvalidIDToken = myerrors.New(myerrors.KindUnauthorized, "oidc_id_token_invalid",
+		"Некорректный id токен oidc провайдера")
+	ErrUnknownKey = myerrors.New(myerrors.KindUnauthorized, "oidc_id_token_unknown_key",
+		"Id токен подписан неизвестным ключом")
+)
+
+// Claims are claims of verified id token. Issuer and Subject together identify user at provider.
+type Claims struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type idTokenClaims struct {
+	Nonce           string `json:"nonce"`
+	Email           string `json:"email"`
+	EmailVerified   bool   `json:"email_verified"`
+	Name            string `json:"name"`
+	AuthorizedParty string `json:"azp"`
+	jwt.RegisteredClaims
+}
+
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	Curve   string `json:"crv"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+}
+
+type keySet struct {
+	byKeyID map[string]any
+}
+
+func decodeBigInt(raw string) (*big.Int, error) {
+	rawBytes, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return new(big.Int).SetBytes(rawBytes), nil
+}
+
+func curveByName(name string) (elliptic.Curve, bool) {
+	switch name {
+	case "P-256":
+		return elliptic.P256(), true
+	case "P-384":
+		return elliptic.P384(), true
+	case "P-521":
+		return elliptic.P521(), true
+	default:
+		return nil, false
+	}
+}
+
+// publicKey returns key for signature check, ok is false for keys of unsupported types, they are skipped.
+func (j *jsonWebKey) publicKey() (any, bool) {
+	switch j.KeyType {
+	case keyTypeRSA:
+		n, errN := decodeBigInt(j.N)
+		e, errE := decodeBigInt(j.E)
+
+		if errN != nil || errE != nil || !e.IsInt64() {
+			return nil, false
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, true
+	case keyTypeEC:
+		curve, ok := curveByName(j.Curve)
+		if !ok {
+			return nil, false
+		}
+
+		x, errX := decodeBigInt(j.X)
+		y, errY := decodeBigInt(j.Y)
+
+		if errX != nil || errY != nil {
+			return nil, false
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, true
+	default:
+		return nil, false
+	}
+}
+
+func (p *Provider) loadKeys(ctx context.Context, jwksURI string) (*keySet, error) {
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+
+	err := p.getJSON(ctx, jwksURI, &jwks)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	keys := &keySet{byKeyID: make(map[string]any, len(jwks.Keys))}
+
+	for i := range jwks.Keys {
+		publicKey, ok := jwks.Keys[i].publicKey()
+		if ok {
+			keys.byKeyID[jwks.Keys[i].KeyID] = publicKey
+		}
+	}
+
+	return keys, nil
+}
+
+// getKey finds key by kid, keys are reloaded once if kid is unknown, so rotation at provider is picked up.
+func (p *Provider) getKey(ctx context.Context, keyID string) (any, error) {
+	discovery, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.keys != nil {
+		if key, ok := p.keys.byKeyID[keyID]; ok {
+			return key, nil
+		}
+	}
+
+	keys, err := p.loadKeys(ctx, discovery.JWKSURI)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	p.keys = keys
+
+	key, ok := keys.byKeyID[keyID]
+	if !ok {
+		p.logger.Errorf("in getKey: provider %s has no key kid=%s", p.config.Name, keyID)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrUnknownKey)
+	}
+
+	return key, nil
+}
+
+// VerifyIDToken checks signature, issuer, audience, expiration and nonce of id token.
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken string, nonce string) (*Claims, error) {
+	claims := new(idTokenClaims)
+
+	_, err := jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		keyID, _ := token.Header["kid"].(string)
+
+		return p.getKey(ctx, keyID)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(p.config.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithLeeway(leeway),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if errors.Is(err, ErrUnknownKey) {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrUnknownKey)
+	}
+
+	if err != nil {
+		p.logger.Errorf("in VerifyIDToken: provider %s: err=%+v", p.config.Name, err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrInvalidIDToken)
+	}
+
+	// token for other client of the same provider must not be accepted
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.config.ClientID {
+		p.logger.Errorf("in VerifyIDToken: provider %s: azp=%s", p.config.Name, claims.AuthorizedParty)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrInvalidIDToken)
+	}
+
+	if claims.Subject == "" || nonce == "" || subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		p.logger.Errorf("in VerifyIDToken: provider %s: empty subject or wrong nonce", p.config.Name)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrInvalidIDToken)
+	}
+
+	return &Claims{
+		Issuer:        claims.Issuer,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+	}, nil
+}
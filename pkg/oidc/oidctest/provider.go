@@ -0,0 +1,182 @@
+// Package oidctest provides stub OpenID Connect provider for tests of sign in by oidc.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SanExpett/film-library-backend/pkg/oidc"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	Name         = "stub"
+	ClientID     = "film-library"
+	ClientSecret = "secret"
+	KeyID        = "stub-key"
+	RedirectURL  = "https://films.example.com/api/v1/oidc/callback"
+
+	// rsaKeyBits is enough for tests and keeps key generation fast
+	rsaKeyBits = 2048
+)
+
+// Provider is identity provider served by httptest.Server: it answers discovery, JWKS and token endpoint.
+// Token endpoint returns id token set by SetCode for authorization code.
+type Provider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu       sync.Mutex
+	idTokens map[string]string
+}
+
+// NewProvider starts stub provider, it is closed when test finishes.
+func NewProvider(t testing.TB) *Provider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider := &Provider{key: key, idTokens: make(map[string]string)} //nolint:exhaustruct
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", provider.discoveryHandler)
+	mux.HandleFunc("GET /jwks", provider.jwksHandler)
+	mux.HandleFunc("POST /token", provider.tokenHandler)
+
+	provider.server = httptest.NewServer(mux)
+	t.Cleanup(provider.server.Close)
+
+	return provider
+}
+
+// Issuer is address of provider, it is issuer of its id tokens.
+func (p *Provider) Issuer() string {
+	return p.server.URL
+}
+
+// Config is configuration of client of stub provider.
+func (p *Provider) Config() *oidc.Config {
+	return &oidc.Config{
+		Name:         Name,
+		Issuer:       p.Issuer(),
+		ClientID:     ClientID,
+		ClientSecret: ClientSecret,
+		RedirectURL:  RedirectURL,
+		Scopes:       nil,
+	}
+}
+
+// Claims returns claims of valid id token for subject and nonce, tests change them to make token invalid.
+func (p *Provider) Claims(subject string, nonce string) jwt.MapClaims {
+	now := time.Now()
+
+	return jwt.MapClaims{
+		"iss":            p.Issuer(),
+		"aud":            ClientID,
+		"sub":            subject,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(), //nolint:gomnd
+		"nonce":          nonce,
+		"email":          subject + "@example.com",
+		"email_verified": true,
+		"name":           subject,
+	}
+}
+
+// SignIDToken signs claims by key of provider, kid of header is keyID.
+func (p *Provider) SignIDToken(t testing.TB, claims jwt.MapClaims, keyID string) string {
+	t.Helper()
+
+	return SignIDToken(t, p.key, claims, keyID)
+}
+
+// SignIDToken signs claims by key, it is used to make token signed by key unknown to provider.
+func SignIDToken(t testing.TB, key *rsa.PrivateKey, claims jwt.MapClaims, keyID string) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = keyID
+
+	rawToken, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return rawToken
+}
+
+// SetCode makes token endpoint return idToken for code, unknown codes are rejected.
+func (p *Provider) SetCode(code string, idToken string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.idTokens[code] = idToken
+}
+
+func sendJSON(w http.ResponseWriter, status int, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func (p *Provider) discoveryHandler(w http.ResponseWriter, _ *http.Request) {
+	sendJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 p.Issuer(),
+		"authorization_endpoint": p.Issuer() + "/authorize",
+		"token_endpoint":         p.Issuer() + "/token",
+		"jwks_uri":               p.Issuer() + "/jwks",
+	})
+}
+
+func (p *Provider) jwksHandler(w http.ResponseWriter, _ *http.Request) {
+	publicKey := p.key.PublicKey
+
+	sendJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": KeyID,
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+		}},
+	})
+}
+
+func (p *Provider) tokenHandler(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok || clientID != ClientID || clientSecret != ClientSecret {
+		sendJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+
+		return
+	}
+
+	if r.PostFormValue("grant_type") != "authorization_code" || r.PostFormValue("code_verifier") == "" {
+		sendJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+
+		return
+	}
+
+	p.mu.Lock()
+	idToken, ok := p.idTokens[r.PostFormValue("code")]
+	p.mu.Unlock()
+
+	if !ok {
+		sendJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+
+		return
+	}
+
+	sendJSON(w, http.StatusOK, map[string]string{"id_token": idToken, "token_type": "Bearer"})
+}
@@ -0,0 +1,234 @@
+// Package oidc implements authorization code flow of OpenID Connect with PKCE for sign in by
+// external identity provider.
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"go.uber.org/zap"
+)
+
+const (
+	discoveryPath = "/.well-known/openid-configuration"
+
+	httpTimeout = 10 * time.Second
+	// maxResponseSize limits answers of provider, they are small json documents
+	maxResponseSize = 1 << 20
+)
+
+//nolint:gochecknoglobals
+var providerNameRegexp = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+var (
+	ErrWrongProviderConfig = myerrors.NewError("Некорректная конфигурация oidc провайдера")
+	ErrDiscovery           = myerrors.New(myerrors.KindBadRequest, "oidc_discovery",
+		"Не удалось получить конфигурацию oidc провайдера")
+	ErrExchangeCode = myerrors.New(myerrors.KindUnauthorized, "oidc_exchange_code",
+		"Oidc провайдер не принял код авторизации")
+)
+
+// Config of one provider, RedirectURL is address of callback of this server registered at provider.
+type Config struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Provider is identity provider found by discovery. Discovery document and keys are loaded on first use
+// and cached, keys are reloaded when token is signed by unknown key.
+type Provider struct {
+	config     *Config
+	httpClient *http.Client
+	logger     *zap.SugaredLogger
+
+	mu        sync.Mutex
+	discovery *discoveryDocument
+	keys      *keySet
+}
+
+func NewProvider(config *Config) (*Provider, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	if !providerNameRegexp.MatchString(config.Name) || config.Issuer == "" || config.ClientID == "" || config.RedirectURL == "" {
+		return nil, fmt.Errorf("%w: %s", ErrWrongProviderConfig, config.Name)
+	}
+
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "email", "profile"}
+	}
+
+	return &Provider{ //nolint:exhaustruct
+		config:     config,
+		httpClient: &http.Client{Timeout: httpTimeout}, //nolint:exhaustruct
+		logger:     logger,
+	}, nil
+}
+
+func (p *Provider) Name() string {
+	return p.config.Name
+}
+
+func (p *Provider) getJSON(ctx context.Context, rawURL string, dst any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		p.logger.Errorf("in getJSON: url=%s err=%+v", rawURL, err)
+
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s responded %d", ErrDiscovery, rawURL, resp.StatusCode)
+	}
+
+	err = json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(dst)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+func (p *Provider) getDiscovery(ctx context.Context) (*discoveryDocument, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	discovery := new(discoveryDocument)
+
+	err := p.getJSON(ctx, strings.TrimSuffix(p.config.Issuer, "/")+discoveryPath, discovery)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	// issuer from document has to be the configured one, otherwise tokens of other issuer could be accepted
+	if discovery.Issuer != p.config.Issuer || discovery.AuthorizationEndpoint == "" ||
+		discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		p.logger.Errorf("in getDiscovery: provider %s: wrong discovery document %+v", p.config.Name, discovery)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrDiscovery)
+	}
+
+	p.discovery = discovery
+
+	return discovery, nil
+}
+
+// CodeChallenge returns S256 PKCE challenge of verifier.
+func CodeChallenge(codeVerifier string) string {
+	hash := sha256.Sum256([]byte(codeVerifier))
+
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+// AuthCodeURL returns address of provider login page, provider redirects back to RedirectURL with code and state.
+func (p *Provider) AuthCodeURL(ctx context.Context, state string, nonce string, codeVerifier string,
+) (string, error) {
+	discovery, err := p.getDiscovery(ctx)
+	if err != nil {
+		return "", fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	authURL, err := url.Parse(discovery.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientID)
+	query.Set("redirect_uri", p.config.RedirectURL)
+	query.Set("scope", strings.Join(p.config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", CodeChallenge(codeVerifier))
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+
+	return authURL.String(), nil
+}
+
+// Exchange exchanges authorization code for tokens and returns verified claims of id token.
+func (p *Provider) Exchange(ctx context.Context, code string, codeVerifier string, nonce string,
+) (*Claims, error) {
+	discovery, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint,
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		p.logger.Errorf("in Exchange: provider %s: err=%+v", p.config.Name, err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+	defer resp.Body.Close()
+
+	token := new(tokenResponse)
+
+	err = json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(token)
+	if err != nil || resp.StatusCode != http.StatusOK || token.IDToken == "" {
+		p.logger.Errorf("in Exchange: provider %s: status=%d error=%s %s err=%+v",
+			p.config.Name, resp.StatusCode, token.Error, token.ErrorDescription, err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrExchangeCode)
+	}
+
+	return p.VerifyIDToken(ctx, token.IDToken, nonce)
+}
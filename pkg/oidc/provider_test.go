@@ -0,0 +1,203 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"github.com/SanExpett/film-library-backend/pkg/oidc"
+	"github.com/SanExpett/film-library-backend/pkg/oidc/oidctest"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testSubject = "alice"
+	testNonce   = "nonce"
+)
+
+func TestMain(m *testing.M) {
+	_, err := my_logger.New([]string{os.DevNull}, []string{os.DevNull})
+	if err != nil {
+		panic(err)
+	}
+
+	os.Exit(m.Run())
+}
+
+func newTestProvider(t *testing.T, stub *oidctest.Provider) *oidc.Provider {
+	t.Helper()
+
+	provider, err := oidc.NewProvider(stub.Config())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return provider
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	t.Parallel()
+
+	stub := oidctest.NewProvider(t)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048) //nolint:gomnd
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		// change makes claims of valid token invalid
+		change  func(claims jwt.MapClaims)
+		keyID   string
+		key     *rsa.PrivateKey
+		wantErr error
+	}{
+		{
+			name:    "valid token",
+			change:  func(jwt.MapClaims) {},
+			wantErr: nil,
+		},
+		{
+			name:    "wrong nonce",
+			change:  func(claims jwt.MapClaims) { claims["nonce"] = "other nonce" },
+			wantErr: oidc.ErrInvalidIDToken,
+		},
+		{
+			name:    "no nonce",
+			change:  func(claims jwt.MapClaims) { delete(claims, "nonce") },
+			wantErr: oidc.ErrInvalidIDToken,
+		},
+		{
+			name:    "wrong audience",
+			change:  func(claims jwt.MapClaims) { claims["aud"] = "other-client" },
+			wantErr: oidc.ErrInvalidIDToken,
+		},
+		{
+			name: "several audiences without azp",
+			change: func(claims jwt.MapClaims) {
+				claims["aud"] = []string{oidctest.ClientID, "other-client"}
+			},
+			wantErr: oidc.ErrInvalidIDToken,
+		},
+		{
+			name: "several audiences with wrong azp",
+			change: func(claims jwt.MapClaims) {
+				claims["aud"] = []string{oidctest.ClientID, "other-client"}
+				claims["azp"] = "other-client"
+			},
+			wantErr: oidc.ErrInvalidIDToken,
+		},
+		{
+			name: "several audiences with our azp",
+			change: func(claims jwt.MapClaims) {
+				claims["aud"] = []string{oidctest.ClientID, "other-client"}
+				claims["azp"] = oidctest.ClientID
+			},
+			wantErr: nil,
+		},
+		{
+			name:    "wrong issuer",
+			change:  func(claims jwt.MapClaims) { claims["iss"] = "https://evil.example.com" },
+			wantErr: oidc.ErrInvalidIDToken,
+		},
+		{
+			name: "expired token",
+			change: func(claims jwt.MapClaims) {
+				claims["iat"] = time.Now().Add(-time.Hour).Unix()
+				claims["exp"] = time.Now().Add(-10 * time.Minute).Unix()
+			},
+			wantErr: oidc.ErrInvalidIDToken,
+		},
+		{
+			name:    "no expiration",
+			change:  func(claims jwt.MapClaims) { delete(claims, "exp") },
+			wantErr: oidc.ErrInvalidIDToken,
+		},
+		{
+			name:    "no subject",
+			change:  func(claims jwt.MapClaims) { delete(claims, "sub") },
+			wantErr: oidc.ErrInvalidIDToken,
+		},
+		{
+			name:    "unknown kid",
+			change:  func(jwt.MapClaims) {},
+			keyID:   "unknown-key",
+			wantErr: oidc.ErrUnknownKey,
+		},
+		{
+			name:    "known kid signed by other key",
+			change:  func(jwt.MapClaims) {},
+			key:     otherKey,
+			wantErr: oidc.ErrInvalidIDToken,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			provider := newTestProvider(t, stub)
+
+			claims := stub.Claims(testSubject, testNonce)
+			test.change(claims)
+
+			keyID := test.keyID
+			if keyID == "" {
+				keyID = oidctest.KeyID
+			}
+
+			var rawIDToken string
+			if test.key != nil {
+				rawIDToken = oidctest.SignIDToken(t, test.key, claims, keyID)
+			} else {
+				rawIDToken = stub.SignIDToken(t, claims, keyID)
+			}
+
+			got, err := provider.VerifyIDToken(context.Background(), rawIDToken, testNonce)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("VerifyIDToken() err = %v, want %v", err, test.wantErr)
+			}
+
+			if test.wantErr != nil {
+				return
+			}
+
+			if got.Issuer != stub.Issuer() || got.Subject != testSubject || !got.EmailVerified ||
+				got.Email != testSubject+"@example.com" {
+				t.Errorf("VerifyIDToken() = %+v", got)
+			}
+		})
+	}
+}
+
+func TestExchange(t *testing.T) {
+	t.Parallel()
+
+	stub := oidctest.NewProvider(t)
+	provider := newTestProvider(t, stub)
+	ctx := context.Background()
+
+	stub.SetCode("code", stub.SignIDToken(t, stub.Claims(testSubject, testNonce), oidctest.KeyID))
+
+	claims, err := provider.Exchange(ctx, "code", "verifier", testNonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if claims.Subject != testSubject {
+		t.Errorf("Exchange() subject = %s, want %s", claims.Subject, testSubject)
+	}
+
+	_, err = provider.Exchange(ctx, "unknown code", "verifier", testNonce)
+	if !errors.Is(err, oidc.ErrExchangeCode) {
+		t.Errorf("Exchange() of unknown code err = %v, want %v", err, oidc.ErrExchangeCode)
+	}
+}
@@ -0,0 +1,50 @@
+// Package principal keeps in request context who makes request. Principal is put there
+// by middleware.Auth once per request, handlers and services only read it.
+package principal
+
+import (
+	"context"
+
+	"github.com/SanExpett/film-library-backend/pkg/models"
+)
+
+type keyCtx string
+
+const principalKey keyCtx = "principal"
+
+type Principal struct {
+	UserID uint64
+	Email  string
+	Roles  []models.Role
+	// Locale is language from profile of user, messages for client are translated to it
+	Locale string
+	// SessionID is session of access token, it is 0 for request authenticated by api key
+	SessionID uint64
+	// APIKey is set for request authenticated by api key, its scopes limit permissions of user
+	APIKey *models.APIKey
+}
+
+func (p *Principal) HasRole(role models.Role) bool {
+	for _, principalRole := range p.Roles {
+		if principalRole == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *Principal) IsAPIKey() bool {
+	return p.APIKey != nil
+}
+
+func With(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+// FromContext returns principal of request, ok is false if request is not authenticated.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey).(*Principal)
+
+	return principal, ok && principal != nil
+}
@@ -0,0 +1,87 @@
+// Package tracing sets up OpenTelemetry tracing: exporter of spans, W3C trace context propagation
+// and helpers to start spans in handler, service and storage layers.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+
+	tracerName = "github.com/SanExpett/film-library-backend"
+)
+
+var ErrUnknownExporter = myerrors.NewError("Неизвестный экспортер трейсов, ожидается none, stdout или otlp")
+
+// Config of tracing. Exporter none keeps propagation of trace context, but spans are not recorded.
+// OTLPEndpoint is host:port of collector accepting otlp over http.
+type Config struct {
+	Exporter     string
+	OTLPEndpoint string
+	OTLPInsecure bool
+	ServiceName  string
+	SampleRatio  float64
+}
+
+func newExporter(ctx context.Context, config *Config) (sdktrace.SpanExporter, error) {
+	switch config.Exporter {
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout)) //nolint:wrapcheck
+	case ExporterOTLP:
+		options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(config.OTLPEndpoint)}
+		if config.OTLPInsecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+
+		return otlptracehttp.New(ctx, options...) //nolint:wrapcheck
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownExporter, config.Exporter)
+	}
+}
+
+// Init sets global tracer provider and W3C propagator. Returned shutdown flushes spans left in batch,
+// it has to be called before exit.
+func Init(ctx context.Context, config *Config) (func(ctx context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{}))
+
+	if config.Exporter == ExporterNone || config.Exporter == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := newExporter(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(config.ServiceName))),
+	)
+	otel.SetTracerProvider(tracerProvider)
+
+	return tracerProvider.Shutdown, nil
+}
+
+// Start starts span of layer method, e.g. tracing.Start(ctx, "FilmService.SearchFilmByTitle").
+// Span has to be ended by caller.
+func Start(ctx context.Context, name string, options ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, options...) //nolint:spancheck
+}
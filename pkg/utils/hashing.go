@@ -1,11 +1,14 @@
 package utils
 
 import (
-	"bytes"
 	"crypto/rand"
 	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"strings"
+
 	myerrors "github.com/SanExpett/film-library-backend/pkg/my_errors"
 	"github.com/SanExpett/film-library-backend/pkg/my_logger"
 
@@ -13,19 +16,58 @@ import (
 )
 
 const (
-	saltLen = 8
-
-	time    = 1
-	memory  = 64 * 1024
-	threads = 4
+	saltLen = 16
 	keyLen  = 32
+
+	prefixArgon2id = "$argon2id$"
+	formatArgon2id = "$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s"
+
+	// legacy hashes are hex of salt||argon2id key made with fixed parameters
+	legacySaltLen = 8
+	legacyTime    = 1
+	legacyMemory  = 64 * 1024
+	legacyThreads = 4
+	legacyKeyLen  = 32
 )
 
+var (
+	ErrWrongHashParams = myerrors.NewError("Некорректные параметры хэширования паролей")
+
+	//nolint:gochecknoglobals
+	passHashParams = PassHashParams{Time: 1, Memory: 64 * 1024, Threads: 4}
+)
+
+// PassHashParams are argon2id parameters for new password hashes, Memory is in KiB.
+// Hashes made with other parameters are still verified, they are only reported as outdated.
+type PassHashParams struct {
+	Time    uint32
+	Memory  uint32
+	Threads uint8
+}
+
+// isValid reports whether params can be passed to argon2.IDKey, it panics on zero time or threads.
+func (p PassHashParams) isValid() bool {
+	return p.Time != 0 && p.Threads != 0 && p.Memory >= 8*uint32(p.Threads)
+}
+
+// SetPassHashParams changes parameters of new hashes, it has to be called once on start.
+func SetPassHashParams(params PassHashParams) error {
+	if !params.isValid() {
+		return fmt.Errorf("%w: %+v", ErrWrongHashParams, params)
+	}
+
+	passHashParams = params
+
+	return nil
+}
+
+// HashPass makes argon2id hash in PHC string format, so parameters are stored together with hash.
 func HashPass(plainPassword string) (string, error) {
 	logger, err := my_logger.Get()
 	if err != nil {
 		return "", fmt.Errorf(myerrors.ErrTemplate, err)
 	}
+
 	salt := make([]byte, saltLen)
 
 	_, err = rand.Read(salt)
@@ -35,24 +77,96 @@ func HashPass(plainPassword string) (string, error) {
 		return "", fmt.Errorf(myerrors.ErrTemplate, err)
 	}
 
-	return hex.EncodeToString(hashPassWithSalt(salt, plainPassword)), nil
+	params := passHashParams
+	key := argon2.IDKey([]byte(plainPassword), salt, params.Time, params.Memory, params.Threads, keyLen)
+
+	return fmt.Sprintf(formatArgon2id, argon2.Version, params.Memory, params.Time, params.Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+type decodedPassHash struct {
+	params PassHashParams
+	salt   []byte
+	key    []byte
+}
+
+func decodePHCHash(passHash string) (*decodedPassHash, bool) {
+	parts := strings.Split(passHash, "$")
+	if len(parts) != 6 { //nolint:gomnd
+		return nil, false
+	}
+
+	var version int
+
+	_, err := fmt.Sscanf(parts[2], "v=%d", &version)
+	if err != nil || version != argon2.Version {
+		return nil, false
+	}
+
+	decoded := new(decodedPassHash)
+
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d",
+		&decoded.params.Memory, &decoded.params.Time, &decoded.params.Threads)
+	if err != nil || !decoded.params.isValid() {
+		return nil, false
+	}
+
+	decoded.salt, err = base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, false
+	}
+
+	decoded.key, err = base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(decoded.key) == 0 {
+		return nil, false
+	}
+
+	return decoded, true
 }
 
-func hashPassWithSalt(salt []byte, plainPassword string) []byte {
-	hashedPass := argon2.IDKey([]byte(plainPassword), salt, time, memory, threads, keyLen)
+func decodeLegacyHash(passHash string) (*decodedPassHash, bool) {
+	rawHash, err := hex.DecodeString(passHash)
+	if err != nil || len(rawHash) != legacySaltLen+legacyKeyLen {
+		return nil, false
+	}
 
-	return append(salt, hashedPass...)
+	return &decodedPassHash{
+		params: PassHashParams{Time: legacyTime, Memory: legacyMemory, Threads: legacyThreads},
+		salt:   rawHash[:legacySaltLen],
+		key:    rawHash[legacySaltLen:],
+	}, true
 }
 
-func ComparePassAndHash(passHash []byte, plainPassword string) bool {
-	passHashCopy := make([]byte, len(passHash))
+// ComparePassAndHash checks password against PHC or legacy hex hash in constant time.
+// needsRehash is true if password is right, but hash is legacy or made with other parameters than current.
+func ComparePassAndHash(passHash string, plainPassword string) (isMatch bool, needsRehash bool) {
+	isPHC := strings.HasPrefix(passHash, prefixArgon2id)
 
-	copy(passHashCopy, passHash)
+	var decoded *decodedPassHash
+
+	var ok bool
+
+	if isPHC {
+		decoded, ok = decodePHCHash(passHash)
+	} else {
+		decoded, ok = decodeLegacyHash(passHash)
+	}
+
+	if !ok {
+		return false, false
+	}
+
+	key := argon2.IDKey([]byte(plainPassword), decoded.salt, decoded.params.Time, decoded.params.Memory,
+		decoded.params.Threads, uint32(len(decoded.key)))
+
+	if subtle.ConstantTimeCompare(key, decoded.key) != 1 {
+		return false, false
+	}
 
-	salt := passHashCopy[0:saltLen]
-	userPassHash := hashPassWithSalt(salt[:saltLen], plainPassword)
+	needsRehash = !isPHC || decoded.params != passHashParams || len(decoded.salt) != saltLen ||
+		len(decoded.key) != keyLen
 
-	return bytes.Equal(userPassHash, passHash)
+	return true, needsRehash
 }
 
 func Hash256(content []byte) (string, error) {
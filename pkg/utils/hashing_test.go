@@ -0,0 +1,183 @@
+package utils_test
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/SanExpett/film-library-backend/pkg/my_logger"
+	"github.com/SanExpett/film-library-backend/pkg/utils"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	testPassword      = "password"
+	testWrongPassword = "wrong password"
+
+	testSaltLen       = 16
+	testKeyLen        = 32
+	testLegacySaltLen = 8
+	testLegacyMemory  = 64 * 1024
+	testLegacyThreads = 4
+)
+
+// testParams are cheap parameters of new hashes, so tests don't spend 64 MiB on every hash
+//
+//nolint:gochecknoglobals
+var testParams = utils.PassHashParams{Time: 1, Memory: 64, Threads: 1}
+
+func TestMain(m *testing.M) {
+	_, err := my_logger.New([]string{os.DevNull}, []string{os.DevNull})
+	if err != nil {
+		panic(err)
+	}
+
+	err = utils.SetPassHashParams(testParams)
+	if err != nil {
+		panic(err)
+	}
+
+	os.Exit(m.Run())
+}
+
+func randomBytes(t *testing.T, length int) []byte {
+	t.Helper()
+
+	result := make([]byte, length)
+
+	_, err := rand.Read(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return result
+}
+
+// phcHash makes PHC hash of password with raw params part, so tests can build hashes with broken params.
+func phcHash(t *testing.T, rawParams string, params utils.PassHashParams) string {
+	t.Helper()
+
+	salt := randomBytes(t, testSaltLen)
+	key := argon2.IDKey([]byte(testPassword), salt, params.Time, params.Memory, params.Threads, testKeyLen)
+
+	return fmt.Sprintf("$argon2id$v=%d$%s$%s$%s", argon2.Version, rawParams,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+}
+
+// legacyHash makes hex hash of salt||key with fixed parameters, the way passwords were hashed before PHC.
+func legacyHash(t *testing.T) string {
+	t.Helper()
+
+	salt := randomBytes(t, testLegacySaltLen)
+	key := argon2.IDKey([]byte(testPassword), salt, 1, testLegacyMemory, testLegacyThreads, testKeyLen)
+
+	return hex.EncodeToString(append(salt, key...))
+}
+
+func TestComparePassAndHash(t *testing.T) {
+	t.Parallel()
+
+	currentHash, err := utils.HashPass(testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherParams := utils.PassHashParams{Time: 2, Memory: 64, Threads: 1}
+	otherParamsHash := phcHash(t, "m=64,t=2,p=1", otherParams)
+	legacy := legacyHash(t)
+
+	tests := []struct {
+		name            string
+		passHash        string
+		password        string
+		wantMatch       bool
+		wantNeedsRehash bool
+	}{
+		{name: "current params", passHash: currentHash, password: testPassword, wantMatch: true},
+		{name: "current params wrong password", passHash: currentHash, password: testWrongPassword},
+		{
+			name: "other params", passHash: otherParamsHash, password: testPassword,
+			wantMatch: true, wantNeedsRehash: true,
+		},
+		{name: "other params wrong password", passHash: otherParamsHash, password: testWrongPassword},
+		{name: "legacy", passHash: legacy, password: testPassword, wantMatch: true, wantNeedsRehash: true},
+		{name: "legacy wrong password", passHash: legacy, password: testWrongPassword},
+		{name: "zero time", passHash: phcHash(t, "m=64,t=0,p=1", testParams), password: testPassword},
+		{name: "zero threads", passHash: phcHash(t, "m=64,t=1,p=0", testParams), password: testPassword},
+		{name: "memory below 8 per thread", passHash: phcHash(t, "m=7,t=1,p=1", testParams), password: testPassword},
+		{name: "threads overflowing uint8", passHash: phcHash(t, "m=64,t=1,p=257", testParams), password: testPassword},
+		{name: "malformed params", passHash: phcHash(t, "m=64;t=1;p=1", testParams), password: testPassword},
+		{
+			name:     "unknown version",
+			passHash: "$argon2id$v=16$m=64,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2U",
+			password: testPassword,
+		},
+		{name: "no key", passHash: "$argon2id$v=19$m=64,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$", password: testPassword},
+		{name: "missing part", passHash: "$argon2id$v=19$m=64,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA", password: testPassword},
+		{name: "legacy of wrong length", passHash: legacy[:len(legacy)-2], password: testPassword},
+		{name: "empty hash", passHash: "", password: testPassword},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			isMatch, needsRehash := utils.ComparePassAndHash(test.passHash, test.password)
+			if isMatch != test.wantMatch || needsRehash != test.wantNeedsRehash {
+				t.Errorf("ComparePassAndHash(%q) = %t, %t, want %t, %t", test.passHash, isMatch, needsRehash,
+					test.wantMatch, test.wantNeedsRehash)
+			}
+		})
+	}
+}
+
+func TestHashPassIsSalted(t *testing.T) {
+	t.Parallel()
+
+	firstHash, err := utils.HashPass(testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secondHash, err := utils.HashPass(testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if firstHash == secondHash {
+		t.Errorf("hashes of the same password are equal: %s", firstHash)
+	}
+}
+
+func TestSetPassHashParamsRejectsInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		params utils.PassHashParams
+	}{
+		{name: "zero time", params: utils.PassHashParams{Time: 0, Memory: 64, Threads: 1}},
+		{name: "zero threads", params: utils.PassHashParams{Time: 1, Memory: 64, Threads: 0}},
+		{name: "memory below 8 per thread", params: utils.PassHashParams{Time: 1, Memory: 31, Threads: 4}},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := utils.SetPassHashParams(test.params)
+			if !errors.Is(err, utils.ErrWrongHashParams) {
+				t.Errorf("SetPassHashParams(%+v) err = %v, want %v", test.params, err, utils.ErrWrongHashParams)
+			}
+		})
+	}
+}
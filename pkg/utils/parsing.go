@@ -11,17 +11,25 @@ import (
 var MessageErrWrongNumberParam = "Получили некорректный числовой параметр. " + //nolint:gochecknoglobals
 	"Он должен быть целым"
 
+const CodeWrongNumberParam = "wrong_number_param"
+
+// ParseUint64FromRequest parses parameter from wildcard of route pattern, for example {id} of /films/{id},
+// or from query if route has no such wildcard.
 func ParseUint64FromRequest(r *http.Request, paramName string) (uint64, error) {
 	logger, err := mylogger.Get()
 	if err != nil {
 		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
 	}
 
-	numberStr := r.URL.Query().Get(paramName)
+	numberStr := r.PathValue(paramName)
+	if numberStr == "" {
+		numberStr = r.URL.Query().Get(paramName)
+	}
 
 	number, err := strconv.ParseUint(numberStr, 10, 64)
 	if err != nil {
-		err := fmt.Errorf("%s %s=%s", MessageErrWrongNumberParam, paramName, numberStr)
+		err := myerrors.New(myerrors.KindBadRequest, CodeWrongNumberParam,
+			MessageErrWrongNumberParam+" %s=%s", paramName, numberStr)
 
 		logger.Errorln(err)
 
@@ -31,6 +39,16 @@ func ParseUint64FromRequest(r *http.Request, paramName string) (uint64, error) {
 	return number, nil
 }
 
+// ParseIDFromRequest parses id of resource from wildcard {id} of route pattern, route without this wildcard
+// passes id in parameter queryName of query.
+func ParseIDFromRequest(r *http.Request, queryName string) (uint64, error) {
+	if r.PathValue("id") != "" {
+		return ParseUint64FromRequest(r, "id")
+	}
+
+	return ParseUint64FromRequest(r, queryName)
+}
+
 func ParseStringFromRequest(r *http.Request, paramName string) string {
 	return r.URL.Query().Get(paramName)
 }